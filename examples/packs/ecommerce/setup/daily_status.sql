@@ -0,0 +1,11 @@
+-- Summary table a nightly job would maintain; rebuilt on every run so it matches orders.
+CREATE TABLE IF NOT EXISTS ecommerce_daily_status (
+  d DATE NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  orders BIGINT NOT NULL,
+  amount DECIMAL(16, 2) NOT NULL,
+  PRIMARY KEY (d, status)
+);
+
+REPLACE INTO ecommerce_daily_status (d, status, orders, amount)
+SELECT DATE(created_at), status, COUNT(*), SUM(total_amount) FROM orders GROUP BY DATE(created_at), status;
@@ -0,0 +1,136 @@
+// Package advisor explains query plans in plain language and proposes candidate indexes.
+//
+// The knowledge base maps EXPLAIN access types, Extra flags and EXPLAIN ANALYZE iterator
+// names to short teaching notes, using the same vocabulary as the built-in scenarios.
+package advisor
+
+import (
+	"fmt"
+	"strings"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// Annotation is a teaching note attached to one element of a plan row.
+type Annotation struct {
+	Table   string
+	Element string
+	Note    string
+}
+
+// accessTypes explains the EXPLAIN "type" column, from worst to best.
+var accessTypes = map[string]string{
+	"ALL":             "全表扫描：逐行读取整张表。大表上通常意味着缺少可用索引，或条件对列做了函数/类型转换导致索引失效（参见“函数索引”“类型匹配”场景）。",
+	"index":           "全索引扫描：按索引顺序读完整棵索引树。比 ALL 少读数据页，但仍是 O(n)；常见于 COUNT(*) 或只为排序而走索引。",
+	"range":           "索引范围扫描：只读取满足区间条件的索引片段（BETWEEN、<、>、IN）。区间越窄越好，注意范围列之后的联合索引列无法再用于定位。",
+	"index_merge":     "索引合并：分别扫描多个单列索引再求交/并集。往往说明缺少一个合适的联合索引。",
+	"ref_or_null":     "与 ref 相同，但额外再查一次 NULL 值。",
+	"fulltext":        "使用 FULLTEXT 索引检索。",
+	"ref":             "非唯一索引等值查找：可能返回多行，若还需读取非索引列会产生回表（参见“覆盖索引”场景）。",
+	"eq_ref":          "唯一索引等值连接：对前表的每一行最多匹配一行，通常是主键/唯一键上的 JOIN，效率很高。",
+	"const":           "常量查找：通过主键或唯一索引最多命中一行，优化阶段即可读出。",
+	"system":          "表只有一行（系统表），是 const 的特例。",
+	"unique_subquery": "IN 子查询被改写为唯一索引查找。",
+	"index_subquery":  "IN 子查询被改写为非唯一索引查找。",
+}
+
+// extraFlags explains the "; "-separated items of the EXPLAIN "Extra" column. Each item takes the
+// first entry it contains, so longer flags must precede their prefixes ("Using index condition" before "Using index").
+var extraFlags = []struct {
+	flag string
+	note string
+}{
+	{"Using filesort", "需要额外排序：ORDER BY 无法直接利用索引顺序，结果先收集再排序，数据量大时会落盘。考虑让索引列顺序覆盖 WHERE + ORDER BY。"},
+	{"Using temporary", "创建了内部临时表，常见于 GROUP BY / DISTINCT / UNION 无法利用索引（参见“UNION vs UNION ALL”场景）。"},
+	{"Using index condition", "索引条件下推（ICP）：在存储引擎层用索引列先过滤，再回表，减少回表次数（参见 ICP 场景）。"},
+	{"Using index for skip scan", "跳跃扫描：联合索引前导列未出现在条件中，优化器按前导列的每个不同值分段扫描；前导列基数越低越有效。"},
+	{"Using index for group-by", "松散索引扫描：GROUP BY/MIN/MAX 只读取每组的首尾索引项。"},
+	{"Using index", "覆盖索引：所需列全部在索引中，无需回表读取聚簇索引。"},
+	{"Using where", "存储引擎返回的行还需在 Server 层按 WHERE 再过滤；配合较低的 filtered 说明很多行被读出后又被丢弃。"},
+	{"Using join buffer (hash join)", "哈希连接：被驱动表没有可用索引，先把一侧读入内存建哈希表。通常说明连接列缺少索引。"},
+	{"Using join buffer (Block Nested Loop)", "块嵌套循环：被驱动表没有可用索引，按批缓存驱动表的行再扫描被驱动表。"},
+	{"Batched Key Access", "BKA：批量收集连接键，再用 MRR 顺序访问被驱动表的索引（参见 MRR/BKA 场景）。"},
+	{"Using MRR", "多范围读（MRR）：先收集主键再排序回表，把随机 IO 变为顺序 IO。"},
+	{"Backward index scan", "反向索引扫描：按索引逆序读取以满足 ORDER BY ... DESC。InnoDB 反向遍历页内记录比正向略慢，可考虑 DESC 索引。"},
+	{"Using union", "index_merge 的并集算法：多个索引结果按主键合并。"},
+	{"Using intersect", "index_merge 的交集算法：多个索引结果求交，通常可由联合索引替代。"},
+	{"Select tables optimized away", "结果在优化阶段直接从索引得出（如 MIN/MAX 或 InnoDB 外的 COUNT(*)），无需访问表。"},
+	{"Impossible WHERE", "WHERE 条件恒为假，查询不会读取任何行。"},
+	{"no matching row in const table", "const 表未命中任何行，查询直接返回空结果。"},
+	{"Range checked for each record", "没有确定的好索引，优化器为前表的每一行重新评估使用哪个索引，代价很高。"},
+	{"FirstMatch", "半连接 FirstMatch：IN/EXISTS 子查询找到第一条匹配即停止。"},
+	{"LooseScan", "半连接 LooseScan：利用索引对子查询结果去重。"},
+	{"Start temporary", "半连接 DuplicateWeedout：借助临时表去重。"},
+}
+
+// treeNodes explains EXPLAIN ANALYZE iterator names, matched by substring on each tree line.
+var treeNodes = []struct {
+	node string
+	note string
+}{
+	{"Table scan on", "全表扫描迭代器（对应 type=ALL）。"},
+	{"Covering index", "覆盖索引迭代器：只读索引，不回表。"},
+	{"Index scan on", "全索引扫描迭代器（对应 type=index）。"},
+	{"Index range scan on", "索引范围扫描迭代器（对应 type=range）。"},
+	{"Index lookup on", "索引等值查找迭代器（对应 type=ref）；非覆盖时每行都要回表。"},
+	{"Single-row index lookup", "唯一索引查找迭代器（对应 eq_ref/const）。"},
+	{"Index skip scan", "跳跃扫描迭代器：按联合索引前导列分段扫描。"},
+	{"Multi-range index scan", "MRR 迭代器：排序主键后批量回表。"},
+	{"Filter:", "Server 层过滤：对比上一层的 rows 与本层 rows 可看出多少行被读出后丢弃。"},
+	{"Sort:", "显式排序（对应 Using filesort）。"},
+	{"Sort row IDs", "先排序行 ID 再回表读取，常见于大行的 filesort。"},
+	{"Inner hash join", "哈希连接：连接列缺少索引时 MySQL 8.0.18+ 的默认选择。"},
+	{"-> Hash", "哈希连接的构建端：此输入会整体读入内存。"},
+	{"Nested loop", "嵌套循环连接：外层每一行都会驱动一次内层访问，内层 loops 值即执行次数。"},
+	{"Aggregate using temporary table", "借助临时表聚合（对应 Using temporary）。"},
+	{"Materialize", "物化：子查询或派生表结果先写入临时表。"},
+	{"Limit:", "LIMIT 提前终止；若下层是排序，仍需先读完所有行。"},
+	{"Stream results", "流式返回：不需要先物化结果。"},
+}
+
+// Annotate returns the teaching notes for every row of a traditional EXPLAIN.
+func Annotate(rows []data.PlanRow) []Annotation {
+	var annotations []Annotation
+	for _, row := range rows {
+		table := row.Table
+		if table == "" {
+			table = "-"
+		}
+		if note, ok := accessTypes[row.Type]; ok {
+			annotations = append(annotations, Annotation{Table: table, Element: "type=" + row.Type, Note: note})
+		}
+		if row.Key == "" && row.PossibleKeys != "" {
+			annotations = append(annotations, Annotation{
+				Table:   table,
+				Element: "key=NULL",
+				Note:    fmt.Sprintf("存在候选索引 %s 但未被选用：优化器估算全表扫描更便宜，通常因为条件选择性差或统计信息过期。", row.PossibleKeys),
+			})
+		}
+		if row.Filtered > 0 && row.Filtered < 10 && row.Rows > 1000 {
+			annotations = append(annotations, Annotation{
+				Table:   table,
+				Element: fmt.Sprintf("filtered=%.2f", row.Filtered),
+				Note:    fmt.Sprintf("预计读取 %d 行中只有 %.2f%% 满足条件，大部分读取被浪费；考虑把过滤列加入索引。", row.Rows, row.Filtered),
+			})
+		}
+		for _, item := range strings.Split(row.Extra, "; ") {
+			for _, flag := range extraFlags {
+				if strings.Contains(item, flag.flag) {
+					annotations = append(annotations, Annotation{Table: table, Element: item, Note: flag.note})
+					break
+				}
+			}
+		}
+	}
+	return annotations
+}
+
+// AnnotateTree returns the note for an EXPLAIN ANALYZE tree line, or "" when the knowledge base has none.
+func AnnotateTree(line string) string {
+	for _, node := range treeNodes {
+		if strings.Contains(line, node.node) {
+			return node.note
+		}
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+package advisor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// InnoDB key length limits with the default 16KB page: 3072 bytes per key part and per index
+// for DYNAMIC/COMPRESSED tables, 767 bytes per key part for REDUNDANT/COMPACT tables — which
+// is where the familiar VARCHAR(191) comes from (191 × 4 bytes of utf8mb4 ≤ 767).
+const (
+	largeKeyPartLimit = 3072
+	smallKeyPartLimit = 767
+	indexKeyLimit     = 3072
+)
+
+// Key length verdicts.
+const (
+	KeyOK        = "ok"
+	KeyTruncated = "truncated"
+	KeyRejected  = "rejected"
+)
+
+// IndexColumn is one key part of a proposed index; Prefix is the prefix length in characters, 0 for the whole column.
+type IndexColumn struct {
+	Column string
+	Prefix int
+}
+
+// ProposedIndex is an index that has not been created yet.
+type ProposedIndex struct {
+	Table   string
+	Columns []IndexColumn
+	Unique  bool
+}
+
+func (p ProposedIndex) String() string {
+	parts := make([]string, 0, len(p.Columns))
+	for _, c := range p.Columns {
+		if c.Prefix > 0 {
+			parts = append(parts, fmt.Sprintf("%s(%d)", c.Column, c.Prefix))
+		} else {
+			parts = append(parts, c.Column)
+		}
+	}
+	s := fmt.Sprintf("%s(%s)", p.Table, strings.Join(parts, ", "))
+	if p.Unique {
+		s = "UNIQUE " + s
+	}
+	return s
+}
+
+var (
+	indexSpecRe  = regexp.MustCompile(`^(?i:(unique)\s+)?(\w+)\s*\((.+)\)$`)
+	keyPartRe    = regexp.MustCompile(`^(\w+)(?:\s*\(\s*(\d+)\s*\))?$`)
+	blobTypeName = map[string]bool{"tinytext": true, "text": true, "mediumtext": true, "longtext": true,
+		"tinyblob": true, "blob": true, "mediumblob": true, "longblob": true}
+)
+
+// ParseIndex parses "table(col1, col2(20))", optionally prefixed with "UNIQUE ".
+func ParseIndex(spec string) (ProposedIndex, error) {
+	m := indexSpecRe.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return ProposedIndex{}, fmt.Errorf("index %q: want table(col1, col2(prefix), ...)", spec)
+	}
+	idx := ProposedIndex{Table: m[2], Unique: m[1] != ""}
+	for _, part := range strings.Split(m[3], ",") {
+		pm := keyPartRe.FindStringSubmatch(strings.TrimSpace(part))
+		if pm == nil {
+			return ProposedIndex{}, fmt.Errorf("index %q: bad key part %q", spec, part)
+		}
+		col := IndexColumn{Column: pm[1]}
+		if pm[2] != "" {
+			col.Prefix, _ = strconv.Atoi(pm[2])
+		}
+		idx.Columns = append(idx.Columns, col)
+	}
+	return idx, nil
+}
+
+// KeyPart is the byte budget of one key part: characters indexed times the charset's maximum bytes per character.
+type KeyPart struct {
+	Column  string
+	Type    string
+	Charset string
+	Chars   int
+	Bytes   int
+}
+
+// KeyLengthCheck is the outcome of checking a proposed index against InnoDB key length limits.
+type KeyLengthCheck struct {
+	Index     ProposedIndex
+	RowFormat string
+	Parts     []KeyPart
+	// PartLimit is the per-key-part byte limit implied by RowFormat.
+	PartLimit  int
+	TotalBytes int
+	Verdict    string
+	Notes      []string
+}
+
+// CheckKeyLength works out how many bytes each key part of idx takes in the table's current
+// charsets and row format, and whether MySQL would reject the index or — for a non-unique index
+// without strict sql_mode — silently shorten an over-long key part to a prefix with only a warning.
+func CheckKeyLength(ctx context.Context, db *gorm.DB, idx ProposedIndex) (KeyLengthCheck, error) {
+	check := KeyLengthCheck{Index: idx, Verdict: KeyOK}
+	if err := db.WithContext(ctx).Raw(`SELECT ROW_FORMAT FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`, idx.Table).Row().Scan(&check.RowFormat); err != nil {
+		return check, fmt.Errorf("table %s: %w", idx.Table, err)
+	}
+	check.PartLimit = largeKeyPartLimit
+	if format := strings.ToUpper(check.RowFormat); format == "REDUNDANT" || format == "COMPACT" {
+		check.PartLimit = smallKeyPartLimit
+	}
+	var sqlMode string
+	if err := db.WithContext(ctx).Raw("SELECT @@SESSION.sql_mode").Row().Scan(&sqlMode); err != nil {
+		return check, err
+	}
+	strict := strings.Contains(sqlMode, "STRICT_TRANS_TABLES") || strings.Contains(sqlMode, "STRICT_ALL_TABLES")
+
+	for _, col := range idx.Columns {
+		var info struct {
+			DataType string
+			MaxChars *int64
+			MaxBytes *int64
+			Charset  *string
+			MaxLen   *int
+		}
+		err := db.WithContext(ctx).Raw(`SELECT c.DATA_TYPE AS data_type, c.CHARACTER_MAXIMUM_LENGTH AS max_chars,
+				c.CHARACTER_OCTET_LENGTH AS max_bytes, c.CHARACTER_SET_NAME AS charset, cs.MAXLEN AS max_len
+			FROM information_schema.COLUMNS c
+			LEFT JOIN information_schema.CHARACTER_SETS cs ON cs.CHARACTER_SET_NAME = c.CHARACTER_SET_NAME
+			WHERE c.TABLE_SCHEMA = DATABASE() AND c.TABLE_NAME = ? AND c.COLUMN_NAME = ?`, idx.Table, col.Column).
+			Scan(&info).Error
+		if err != nil {
+			return check, err
+		}
+		if info.DataType == "" {
+			return check, fmt.Errorf("column %s.%s not found", idx.Table, col.Column)
+		}
+		part := KeyPart{Column: col.Column, Type: info.DataType}
+		perChar := 1
+		switch {
+		case info.Charset != nil && info.MaxChars != nil && info.MaxLen != nil:
+			part.Charset = *info.Charset
+			part.Chars = int(*info.MaxChars)
+			perChar = *info.MaxLen
+		case info.MaxBytes != nil:
+			// Binary strings: one byte per character.
+			part.Chars = int(*info.MaxBytes)
+		default:
+			// Numeric and temporal columns are small fixed-size key parts; they never hit the limits.
+			part.Bytes = 8
+		}
+		if part.Chars > 0 {
+			if col.Prefix > 0 {
+				part.Chars = min(col.Prefix, part.Chars)
+			}
+			part.Bytes = part.Chars * perChar
+		}
+
+		if blobTypeName[info.DataType] && col.Prefix == 0 {
+			check.reject(fmt.Sprintf("%s 是 %s，必须指定前缀长度（ERROR 1170）。", col.Column, info.DataType))
+			check.Parts = append(check.Parts, part)
+			continue
+		}
+		if part.Bytes > check.PartLimit {
+			fits := check.PartLimit / perChar
+			msg := fmt.Sprintf("%s 需要 %d 字节（%d 字符 × %d 字节/字符），超过 %s 行格式单列 %d 字节上限，最多 %d 字符",
+				col.Column, part.Bytes, part.Chars, perChar, check.RowFormat, check.PartLimit, fits)
+			if idx.Unique || strict {
+				check.reject(msg + "：建索引会报错 ERROR 1071。")
+			} else {
+				check.truncate(msg + fmt.Sprintf("：非严格 sql_mode 下普通索引会被静默截成 %s(%d)，只有一条 Warning 1071。", col.Column, fits))
+				part.Chars, part.Bytes = fits, fits*perChar
+			}
+		}
+		check.Parts = append(check.Parts, part)
+		check.TotalBytes += part.Bytes
+	}
+	if check.TotalBytes > indexKeyLimit {
+		check.reject(fmt.Sprintf("索引总长度 %d 字节，超过 InnoDB 单个索引 %d 字节上限（ERROR 1071）；考虑对长列使用前缀。", check.TotalBytes, indexKeyLimit))
+	}
+	return check, nil
+}
+
+func (c *KeyLengthCheck) reject(note string) {
+	c.Verdict = KeyRejected
+	c.Notes = append(c.Notes, note)
+}
+
+func (c *KeyLengthCheck) truncate(note string) {
+	if c.Verdict == KeyOK {
+		c.Verdict = KeyTruncated
+	}
+	c.Notes = append(c.Notes, note)
+}
@@ -0,0 +1,43 @@
+package advisor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIndex(t *testing.T) {
+	tests := []struct {
+		spec string
+		want ProposedIndex
+	}{
+		{"orders(customer_id)", ProposedIndex{Table: "orders", Columns: []IndexColumn{{Column: "customer_id"}}}},
+		{"  customers ( email(191), name ) ", ProposedIndex{Table: "customers", Columns: []IndexColumn{{"email", 191}, {"name", 0}}}},
+		{"UNIQUE customers(email)", ProposedIndex{Table: "customers", Columns: []IndexColumn{{Column: "email"}}, Unique: true}},
+		{"unique t(a ( 10 ),b)", ProposedIndex{Table: "t", Columns: []IndexColumn{{"a", 10}, {"b", 0}}, Unique: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseIndex(tt.spec)
+		if err != nil {
+			t.Errorf("ParseIndex(%q): %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseIndex(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseIndexErrors(t *testing.T) {
+	for _, spec := range []string{"", "orders", "orders()", "orders(a,)", "orders(a(x))", "orders(a b)", "db.orders(a)", "PRIMARY orders(a)"} {
+		if idx, err := ParseIndex(spec); err == nil {
+			t.Errorf("ParseIndex(%q) = %+v, want an error", spec, idx)
+		}
+	}
+}
+
+func TestProposedIndexString(t *testing.T) {
+	idx := ProposedIndex{Table: "customers", Columns: []IndexColumn{{"email", 191}, {"name", 0}}, Unique: true}
+	if got, want := idx.String(), "UNIQUE customers(email(191), name)"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,509 @@
+package advisor
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// Suggestion is a candidate index for one table of a plan that scans it in full or sorts its rows.
+type Suggestion struct {
+	Table string
+	// Columns are in key order; a sort column may carry a " DESC" suffix.
+	Columns []string
+	Reason  string
+}
+
+// DDL returns the statement that creates the suggested index.
+func (s Suggestion) DDL() string {
+	name := "idx_" + s.Table
+	for _, c := range s.Columns {
+		name += "_" + strings.TrimSuffix(c, " DESC")
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD INDEX %s (%s)", s.Table, name, strings.Join(s.Columns, ", "))
+}
+
+func (s Suggestion) String() string {
+	return s.DDL() + " -- " + s.Reason
+}
+
+// SuggestIndexes proposes an index for every plan row with type=ALL or Using filesort, built
+// from the query's predicates on that table the usual way: equality columns first, then the
+// ORDER BY columns when they all belong to the table, otherwise the first range column. Only
+// bare column predicates joined by AND at the top level are understood; a column wrapped in a
+// function cannot use an index anyway, and OR or subqueries leave nothing to suggest. The
+// result is a starting point to verify with EXPLAIN, not a guarantee the optimizer will use it.
+func SuggestIndexes(query string, plan []data.PlanRow) []Suggestion {
+	q := parseQuery(query)
+	var suggestions []Suggestion
+	for i, row := range plan {
+		fullScan := row.Type == "ALL"
+		filesort := strings.Contains(row.Extra, "Using filesort")
+		if !fullScan && !filesort {
+			continue
+		}
+		table, ok := q.tables[strings.ToLower(row.Table)]
+		if !ok {
+			continue
+		}
+		p := q.preds[strings.ToLower(row.Table)]
+		if p == nil {
+			p = &tablePreds{}
+		}
+		cols := append([]string(nil), p.eq...)
+		var why []string
+		if len(p.eq) > 0 {
+			why = append(why, "equality on "+strings.Join(p.eq, ", "))
+		}
+		// A join column only helps when the other table is read first, i.e. earlier in the plan.
+		for _, j := range p.joins {
+			if !contains(cols, j.column) && readBefore(plan[:i], j.other) {
+				cols = append(cols, j.column)
+				why = append(why, "join on "+j.column)
+			}
+		}
+		eqCols := len(cols)
+		sortCols, sortable := q.sortColumns(row.Table)
+		switch {
+		case filesort && sortable:
+			var added []string
+			for _, c := range sortCols {
+				if !contains(cols, strings.TrimSuffix(c, " DESC")) {
+					cols = append(cols, c)
+					added = append(added, c)
+				}
+			}
+			if len(added) > 0 {
+				why = append(why, "ORDER BY "+strings.Join(added, ", ")+" read in index order")
+			}
+		case len(p.rng) > 0:
+			if !contains(cols, p.rng[0]) {
+				cols = append(cols, p.rng[0])
+				why = append(why, "range on "+p.rng[0])
+			}
+		}
+		if len(cols) == 0 || (len(cols) == eqCols && !fullScan) {
+			continue
+		}
+		reason := "type=ALL"
+		if filesort {
+			reason = "Using filesort"
+			if fullScan {
+				reason = "type=ALL, Using filesort"
+			}
+		}
+		suggestions = append(suggestions, Suggestion{
+			Table:   table,
+			Columns: cols,
+			Reason:  reason + ": " + strings.Join(why, "; "),
+		})
+	}
+	return suggestions
+}
+
+// tablePreds are the indexable predicates on one table, columns in order of appearance.
+type tablePreds struct {
+	eq    []string
+	rng   []string
+	joins []joinColumn
+}
+
+// joinColumn is a column compared with a column of another table reference.
+type joinColumn struct {
+	column string
+	other  string
+}
+
+func readBefore(plan []data.PlanRow, ref string) bool {
+	for _, row := range plan {
+		if strings.EqualFold(row.Table, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsedQuery is what SuggestIndexes understands of a SELECT: the tables of the outer FROM
+// clause by alias, the predicates of its WHERE clause and its ORDER BY columns.
+type parsedQuery struct {
+	// tables maps each table reference, lower-cased as EXPLAIN's table column names it (the
+	// alias when there is one), to the table name.
+	tables  map[string]string
+	preds   map[string]*tablePreds
+	orderBy []qualifiedColumn
+	// orderByOK is false when ORDER BY contains anything but plain columns.
+	orderByOK bool
+}
+
+type qualifiedColumn struct {
+	qualifier string
+	column    string
+	desc      bool
+}
+
+// sortColumns returns the ORDER BY columns when all of them belong to the plan table alias.
+func (q parsedQuery) sortColumns(alias string) ([]string, bool) {
+	if !q.orderByOK || len(q.orderBy) == 0 {
+		return nil, false
+	}
+	var cols []string
+	for _, c := range q.orderBy {
+		if q.owner(c) != strings.ToLower(alias) {
+			return nil, false
+		}
+		col := c.column
+		if c.desc {
+			col += " DESC"
+		}
+		cols = append(cols, col)
+	}
+	return cols, true
+}
+
+// owner resolves the table reference a column belongs to: its qualifier, or the only table
+// of the query when it is unqualified.
+func (q parsedQuery) owner(c qualifiedColumn) string {
+	if c.qualifier != "" {
+		return strings.ToLower(c.qualifier)
+	}
+	if len(q.tables) != 1 {
+		return ""
+	}
+	for ref := range q.tables {
+		return ref
+	}
+	return ""
+}
+
+func (q parsedQuery) add(c qualifiedColumn, eq bool) {
+	p := q.predsOf(c)
+	switch {
+	case p == nil:
+	case eq && !contains(p.eq, c.column):
+		p.eq = append(p.eq, c.column)
+	case !eq && !contains(p.rng, c.column):
+		p.rng = append(p.rng, c.column)
+	}
+}
+
+func (q parsedQuery) addJoin(c, other qualifiedColumn) {
+	if p := q.predsOf(c); p != nil && q.owner(other) != "" {
+		p.joins = append(p.joins, joinColumn{column: c.column, other: q.owner(other)})
+	}
+}
+
+// predsOf returns the predicate set of the table a column belongs to, nil when unknown.
+func (q parsedQuery) predsOf(c qualifiedColumn) *tablePreds {
+	ref := q.owner(c)
+	if _, ok := q.tables[ref]; !ok {
+		return nil
+	}
+	p := q.preds[ref]
+	if p == nil {
+		p = &tablePreds{}
+		q.preds[ref] = p
+	}
+	return p
+}
+
+func parseQuery(query string) parsedQuery {
+	q := parsedQuery{tables: map[string]string{}, preds: map[string]*tablePreds{}}
+	toks := tokenize(query)
+	clauses := splitClauses(toks)
+	parseFrom(q.tables, clauses["FROM"])
+	for _, conj := range splitAnd(clauses["WHERE"]) {
+		parsePredicate(q, conj)
+	}
+	for _, conj := range splitAnd(clauses["ON"]) {
+		parsePredicate(q, conj)
+	}
+	q.orderBy, q.orderByOK = parseOrderBy(clauses["ORDER BY"])
+	return q
+}
+
+// token is one lexical item; keywords and identifiers are kept as written in text, while
+// upper holds the upper-cased form used for keyword matching.
+type token struct {
+	text  string
+	upper string
+	ident bool
+}
+
+func tokenize(query string) []token {
+	var toks []token
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			j := i + 1
+			for j < len(rs) && rs[j] != r {
+				if rs[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			toks = append(toks, token{text: string(rs[i:min(j+1, len(rs))])})
+			i = j + 1
+		case r == '`':
+			j := i + 1
+			for j < len(rs) && rs[j] != '`' {
+				j++
+			}
+			name := string(rs[i+1 : min(j, len(rs))])
+			toks = append(toks, token{text: name, upper: "`", ident: true})
+			i = j + 1
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && (rs[j] == '_' || rs[j] == '$' || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			word := string(rs[i:j])
+			toks = append(toks, token{text: word, upper: strings.ToUpper(word), ident: !unicode.IsDigit(r)})
+			i = j
+		case strings.ContainsRune("<>!=", r):
+			j := i + 1
+			for j < len(rs) && strings.ContainsRune("<>=", rs[j]) {
+				j++
+			}
+			op := string(rs[i:j])
+			toks = append(toks, token{text: op, upper: op})
+			i = j
+		default:
+			toks = append(toks, token{text: string(r), upper: string(r)})
+			i++
+		}
+	}
+	return toks
+}
+
+// clauseKeywords start a clause of the outer query; ON is collected across all joins.
+var clauseKeywords = []string{"SELECT", "FROM", "WHERE", "GROUP BY", "HAVING", "ORDER BY", "LIMIT", "ON", "UNION", "FOR", "WINDOW", "USING"}
+
+var joinKeywords = map[string]bool{"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "CROSS": true,
+	"OUTER": true, "STRAIGHT_JOIN": true, "NATURAL": true}
+
+// splitClauses groups the top-level tokens by the clause they belong to. Tokens nested in
+// parentheses stay with their clause, so subqueries never leak into the outer one.
+func splitClauses(toks []token) map[string][]token {
+	clauses := map[string][]token{}
+	current := ""
+	depth := 0
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch t.upper {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth == 0 && t.ident && t.upper != "`" {
+			kw := t.upper
+			if (kw == "GROUP" || kw == "ORDER") && i+1 < len(toks) && toks[i+1].upper == "BY" {
+				kw += " BY"
+				i++
+			}
+			if contains(clauseKeywords, kw) {
+				if kw == "UNION" {
+					// Only the first SELECT of a UNION is analysed.
+					break
+				}
+				current = kw
+				if kw == "ON" {
+					// Separate the ON conditions of consecutive joins.
+					clauses[kw] = append(clauses[kw], token{text: "AND", upper: "AND", ident: true})
+				}
+				continue
+			}
+			if joinKeywords[kw] && (current == "ON" || current == "USING") {
+				// The next join continues the FROM clause.
+				current = "FROM"
+			}
+		}
+		if current != "" {
+			clauses[current] = append(clauses[current], t)
+		}
+	}
+	return clauses
+}
+
+// parseFrom records "table [AS] alias" references of the FROM clause and its joins; derived
+// tables in parentheses are skipped.
+func parseFrom(tables map[string]string, toks []token) {
+	joinWords := map[string]bool{"FORCE": true, "USE": true, "IGNORE": true, "INDEX": true, "KEY": true}
+	for kw := range joinKeywords {
+		joinWords[kw] = true
+	}
+	expectTable := true
+	depth := 0
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch {
+		case t.upper == "(":
+			depth++
+			continue
+		case t.upper == ")":
+			depth--
+			continue
+		case depth > 0:
+			continue
+		case t.upper == ",":
+			expectTable = true
+			continue
+		case joinWords[t.upper]:
+			expectTable = t.upper == "JOIN" || t.upper == "STRAIGHT_JOIN" || expectTable
+			continue
+		}
+		if !expectTable || !t.ident {
+			continue
+		}
+		name := t.text
+		if i+2 < len(toks) && toks[i+1].upper == "." && toks[i+2].ident {
+			// schema.table
+			name = toks[i+2].text
+			i += 2
+		}
+		ref := name
+		if i+1 < len(toks) && toks[i+1].upper == "AS" {
+			i++
+		}
+		if i+1 < len(toks) && toks[i+1].ident && !joinWords[toks[i+1].upper] && toks[i+1].upper != "PARTITION" {
+			ref = toks[i+1].text
+			i++
+		}
+		tables[strings.ToLower(ref)] = name
+		expectTable = false
+	}
+}
+
+// splitAnd splits a condition into its top-level AND conjuncts. A top-level OR means no
+// single index serves the condition, so nothing is returned.
+func splitAnd(toks []token) [][]token {
+	var conjuncts [][]token
+	var current []token
+	depth := 0
+	between := false
+	for _, t := range toks {
+		switch t.upper {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth == 0 {
+			switch t.upper {
+			case "OR", "||", "XOR":
+				return nil
+			case "BETWEEN":
+				between = true
+			case "AND", "&&":
+				if between {
+					between = false
+					break
+				}
+				if len(current) > 0 {
+					conjuncts = append(conjuncts, current)
+				}
+				current = nil
+				continue
+			}
+		}
+		current = append(current, t)
+	}
+	if len(current) > 0 {
+		conjuncts = append(conjuncts, current)
+	}
+	return conjuncts
+}
+
+// columnAt reads "col" or "qualifier.col" at toks[i] and returns the index after it.
+func columnAt(toks []token, i int) (qualifiedColumn, int, bool) {
+	if i >= len(toks) || !toks[i].ident || isKeyword(toks[i]) {
+		return qualifiedColumn{}, i, false
+	}
+	if i+2 < len(toks) && toks[i+1].upper == "." && toks[i+2].ident {
+		return qualifiedColumn{qualifier: toks[i].text, column: toks[i+2].text}, i + 3, true
+	}
+	if i+1 < len(toks) && toks[i+1].upper == "(" {
+		// A function call, not a column.
+		return qualifiedColumn{}, i, false
+	}
+	return qualifiedColumn{column: toks[i].text}, i + 1, true
+}
+
+func isKeyword(t token) bool {
+	switch t.upper {
+	case "NOT", "NULL", "EXISTS", "TRUE", "FALSE", "CASE", "INTERVAL", "SELECT", "DISTINCT", "BINARY":
+		return true
+	}
+	return false
+}
+
+// parsePredicate records "col = value", "col IN (...)", "col IS NULL" as equality and
+// "col < value", "col BETWEEN a AND b", "col LIKE 'prefix%'" as range predicates. A
+// comparison of two columns is a join condition, usable by whichever side is read second.
+func parsePredicate(q parsedQuery, toks []token) {
+	col, i, ok := columnAt(toks, 0)
+	if !ok || i >= len(toks) {
+		return
+	}
+	op := toks[i].upper
+	rest := toks[i+1:]
+	switch op {
+	case "=", "<=>":
+		if other, j, ok := columnAt(rest, 0); ok && j == len(rest) {
+			q.addJoin(col, other)
+			q.addJoin(other, col)
+			return
+		}
+		q.add(col, true)
+	case "IN":
+		q.add(col, true)
+	case "IS":
+		if len(rest) > 0 && rest[0].upper == "NULL" {
+			q.add(col, true)
+		}
+	case "<", ">", "<=", ">=", "BETWEEN":
+		q.add(col, false)
+	case "LIKE":
+		if len(rest) > 0 && !strings.HasPrefix(strings.Trim(rest[0].text, `'"`), "%") {
+			q.add(col, false)
+		}
+	}
+}
+
+func parseOrderBy(toks []token) ([]qualifiedColumn, bool) {
+	var cols []qualifiedColumn
+	for i := 0; i < len(toks); {
+		col, j, ok := columnAt(toks, i)
+		if !ok {
+			return nil, false
+		}
+		if j < len(toks) && (toks[j].upper == "ASC" || toks[j].upper == "DESC") {
+			col.desc = toks[j].upper == "DESC"
+			j++
+		}
+		if j < len(toks) && toks[j].upper != "," {
+			return nil, false
+		}
+		cols = append(cols, col)
+		i = j + 1
+	}
+	return cols, true
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package advisor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+func TestSuggestIndexes(t *testing.T) {
+	scan := func(table string) data.PlanRow { return data.PlanRow{Table: table, Type: "ALL"} }
+	tests := []struct {
+		name  string
+		query string
+		plan  []data.PlanRow
+		want  []Suggestion
+	}{
+		{
+			name:  "equality then range",
+			query: "SELECT * FROM orders WHERE created_at >= ? AND customer_id = ? AND status IN ('paid', 'shipped')",
+			plan:  []data.PlanRow{scan("orders")},
+			want: []Suggestion{{Table: "orders", Columns: []string{"customer_id", "status", "created_at"},
+				Reason: "type=ALL: equality on customer_id, status; range on created_at"}},
+		},
+		{
+			name:  "filesort adds ORDER BY columns",
+			query: "SELECT * FROM orders o WHERE o.customer_id = 7 ORDER BY o.created_at DESC, o.id DESC LIMIT 10",
+			plan:  []data.PlanRow{{Table: "o", Type: "ref", Key: "idx_customer", Extra: "Using where; Using filesort"}},
+			want: []Suggestion{{Table: "orders", Columns: []string{"customer_id", "created_at DESC", "id DESC"},
+				Reason: "Using filesort: equality on customer_id; ORDER BY created_at DESC, id DESC read in index order"}},
+		},
+		{
+			name:  "join column of the table read second",
+			query: "SELECT * FROM customers c JOIN orders o ON o.customer_id = c.id WHERE c.region = 'eu'",
+			plan:  []data.PlanRow{scan("c"), scan("o")},
+			want: []Suggestion{
+				{Table: "customers", Columns: []string{"region"}, Reason: "type=ALL: equality on region"},
+				{Table: "orders", Columns: []string{"customer_id"}, Reason: "type=ALL: join on customer_id"},
+			},
+		},
+		{
+			name:  "prefix LIKE is a range",
+			query: "SELECT * FROM customers WHERE name LIKE 'Zh%'",
+			plan:  []data.PlanRow{scan("customers")},
+			want:  []Suggestion{{Table: "customers", Columns: []string{"name"}, Reason: "type=ALL: range on name"}},
+		},
+		{
+			name:  "leading wildcard LIKE",
+			query: "SELECT * FROM customers WHERE name LIKE '%zh'",
+			plan:  []data.PlanRow{scan("customers")},
+		},
+		{
+			name:  "column wrapped in a function",
+			query: "SELECT * FROM orders WHERE DATE(created_at) = '2024-01-01'",
+			plan:  []data.PlanRow{scan("orders")},
+		},
+		{
+			name:  "top-level OR",
+			query: "SELECT * FROM orders WHERE customer_id = 1 OR status = 'paid'",
+			plan:  []data.PlanRow{scan("orders")},
+		},
+		{
+			name:  "subquery predicates stay out",
+			query: "SELECT * FROM orders WHERE id IN (SELECT order_id FROM refunds WHERE amount > 10) AND status = 'paid'",
+			plan:  []data.PlanRow{scan("orders")},
+			want:  []Suggestion{{Table: "orders", Columns: []string{"id", "status"}, Reason: "type=ALL: equality on id, status"}},
+		},
+		{
+			name:  "indexed access needs nothing",
+			query: "SELECT * FROM orders WHERE customer_id = 1",
+			plan:  []data.PlanRow{{Table: "orders", Type: "ref", Key: "idx_orders_customer_id"}},
+		},
+		{
+			name:  "filesort already covered by equality",
+			query: "SELECT * FROM orders WHERE status = 'paid' ORDER BY status",
+			plan:  []data.PlanRow{{Table: "orders", Type: "ref", Extra: "Using filesort"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuggestIndexes(tt.query, tt.plan)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SuggestIndexes:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestionDDL(t *testing.T) {
+	s := Suggestion{Table: "orders", Columns: []string{"customer_id", "created_at DESC"}}
+	if got, want := s.DDL(), "ALTER TABLE orders ADD INDEX idx_orders_customer_id_created_at (customer_id, created_at DESC)"; got != want {
+		t.Errorf("DDL = %q, want %q", got, want)
+	}
+	long := Suggestion{Table: "order_line_items", Columns: []string{"warehouse_identifier", "fulfillment_status", "shipped_at"}}
+	name := strings.Fields(long.DDL())[5]
+	if len(name) != 64 || !strings.HasPrefix("idx_order_line_items_warehouse_identifier_fulfillment_status_shipped_at", name) {
+		t.Errorf("index name %q, want the full name cut to 64 characters", name)
+	}
+}
@@ -0,0 +1,62 @@
+// Package buildinfo reports which build of slowlab produced a run.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Info is the provenance embedded by the Go toolchain (module version and VCS stamping).
+type Info struct {
+	Module    string `json:"module"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"vcs_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Read returns the build information of the running binary. `go run` and test binaries
+// carry no VCS stamp, in which case Revision is empty and Version is "(devel)".
+func Read() Info {
+	info := Info{Version: "(devel)"}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = bi.Main.Path
+	if bi.Main.Version != "" {
+		info.Version = bi.Main.Version
+	}
+	info.GoVersion = bi.GoVersion
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.Time = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// String renders the info on one line, e.g. "mysql-slow-query-lab (devel) rev 1a2b3c4d5e6f+dirty go1.25.3".
+func (i Info) String() string {
+	s := fmt.Sprintf("%s %s", i.Module, i.Version)
+	if i.Revision != "" {
+		rev := i.Revision
+		if len(rev) > 12 {
+			rev = rev[:12]
+		}
+		if i.Modified {
+			rev += "+dirty"
+		}
+		s += " rev " + rev
+	}
+	if i.GoVersion != "" {
+		s += " " + i.GoVersion
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Config captures the connection parameters for the optional Redis instance.
+type Config struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+// FromEnv reads the Redis settings; an empty Addr means the cache scenarios are disabled.
+func FromEnv() Config {
+	db, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
+	return Config{
+		Addr:     os.Getenv("REDIS_ADDR"),
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       db,
+	}
+}
+
+// Enabled reports whether a Redis address was configured.
+func (c Config) Enabled() bool {
+	return c.Addr != ""
+}
+
+// Redis adapts a go-redis client to the data.Cache interface.
+type Redis struct {
+	client *redis.Client
+}
+
+// Open connects to Redis and verifies the connection with PING.
+func Open(ctx context.Context, cfg Config) (*Redis, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &Redis{client: client}, nil
+}
+
+// Get returns the cached value and whether the key existed.
+func (r *Redis) Get(ctx context.Context, key string) (string, bool, error) {
+	val, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// Set stores value under key with the given TTL.
+func (r *Redis) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Del removes key from the cache.
+func (r *Redis) Del(ctx context.Context, key string) error {
+	return r.client.Del(ctx, key).Err()
+}
+
+// Close releases the underlying connection pool.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
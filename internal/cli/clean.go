@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+)
+
+func runCleanCommand(args []string) {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	dsn := fs.String("dsn", "", dsnUsage)
+	drop := fs.Bool("drop", false, "drop every lab table instead of emptying the dataset tables (the next run rebuilds the schema)")
+	yes := fs.Bool("yes", false, "actually run the statements; without it they are only printed")
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, defaultConfigFile, "connection"); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+
+	gdb, err := db.Open(connConfig(*dsn))
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+	ctx := context.Background()
+	stmts, err := data.CleanStatements(ctx, gdb, *drop)
+	if err != nil {
+		fatal("failed to inspect lab tables", "err", err)
+	}
+	if len(stmts) == 0 {
+		slog.Info("nothing to clean")
+		return
+	}
+	for _, stmt := range stmts {
+		fmt.Println(stmt + ";")
+	}
+	if !*yes {
+		fmt.Fprintln(os.Stderr, "dry run: rerun with -yes to execute these statements")
+		os.Exit(1)
+	}
+	if err := data.Clean(ctx, gdb, stmts); err != nil {
+		fatal("clean failed", "err", err)
+	}
+	slog.Info("lab reset", "statements", len(stmts))
+}
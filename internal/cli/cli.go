@@ -0,0 +1,730 @@
+// Package cli implements the slowlab command line; cmd/slowlab and pkg/slowlab both call Main.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/internal/advisor"
+	"mysql-slow-query-lab/internal/buildinfo"
+	"mysql-slow-query-lab/internal/cache"
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+	"mysql-slow-query-lab/internal/docker"
+	"mysql-slow-query-lab/internal/flamegraph"
+	"mysql-slow-query-lab/internal/health"
+	"mysql-slow-query-lab/internal/iotrace"
+	"mysql-slow-query-lab/internal/pack"
+	"mysql-slow-query-lab/internal/report"
+	"mysql-slow-query-lab/internal/retry"
+	"mysql-slow-query-lab/internal/rundir"
+	"mysql-slow-query-lab/internal/slowlog"
+
+	"gorm.io/gorm"
+)
+
+// Main runs the slowlab command line using os.Args.
+func Main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "pack":
+			runPackCommand(os.Args[2:])
+			return
+		case "explain":
+			runExplainCommand(os.Args[2:])
+			return
+		case "watch":
+			runWatchCommand(os.Args[2:])
+			return
+		case "import":
+			runImportCommand(os.Args[2:])
+			return
+		case "export":
+			runExportCommand(os.Args[2:])
+			return
+		case "clean":
+			runCleanCommand(os.Args[2:])
+			return
+		case "sizes":
+			runSizesCommand(os.Args[2:])
+			return
+		case "analyze-slowlog":
+			runAnalyzeSlowlogCommand(os.Args[2:])
+			return
+		case "tail-slowlog":
+			runTailSlowlogCommand(os.Args[2:])
+			return
+		case "replay":
+			runReplayCommand(os.Args[2:])
+			return
+		case "serve":
+			runServeCommand(os.Args[2:])
+			return
+		}
+	}
+
+	var (
+		orderCount      = flag.Int("orders", 1000000, "target number of orders to store")
+		batchSize       = flag.Int("batch", 1000, "batch size for bulk inserts")
+		realistic       = flag.Bool("realistic", false, "generate plausible customer names, notes with addresses and discount codes instead of the terse synthetic values (pass it again on later runs against that dataset)")
+		skipSeed        = flag.Bool("skip-seed", false, "skip inserting synthetic data")
+		skipScenarios   = flag.Bool("skip-scenarios", false, "skip running slow query scenarios")
+		showExplain     = flag.Bool("explain", true, "print EXPLAIN output for each scenario")
+		experiment      = flag.String("experiment", "", "run the named experiment instead of the scenarios (\"list\" to show all)")
+		target          = flag.String("target", "", "object the experiment acts on, e.g. orders.idx_orders_customer_id for invisible-index")
+		txSizes         = flag.String("tx-sizes", "", "comma-separated rows per transaction for the autocommit experiment (default 10,100,1000,10000)")
+		schema          = flag.String("schema", "standard", "schema mode: standard, or partitioned to also build orders_by_month and run the partition pruning scenarios")
+		locale          = flag.String("locale", "raw", "number/duration formatting in reports: "+strings.Join(report.Locales(), ", "))
+		lang            = flag.String("lang", "zh", langUsage)
+		packsDir        = flag.String("packs-dir", defaultPacksDir, "directory of installed scenario packs to run after the built-ins")
+		flameDir        = flag.String("flamegraph-dir", "", "capture performance_schema stages and write folded stacks per scenario into this directory")
+		ioDir           = flag.String("io-samples-dir", "", "sample InnoDB IO counters every second while each scenario runs and write one CSV per scenario into this directory")
+		destructive     = flag.Bool("destructive", false, "also run scenarios that delete a slice of orders (restored by the next seeding run)")
+		outDir          = flag.String("out-dir", "", "create a timestamped folder under this directory for the report, results.json, run.log and artifacts of this run")
+		outPath         = flag.String("out", "", "write the final results to this file instead of standard output: JSON for .json, Markdown tables for .md, otherwise the text tables")
+		suggest         = flag.Bool("suggest-indexes", true, "suggest a candidate index for scenarios whose plan has type=ALL or Using filesort, printed with the scenario notes")
+		serverSlowlog   = flag.Bool("server-slowlog", false, "turn the server slow log on (to mysql.slow_log) while the scenarios run and attach each query's logged lock time and rows examined to its result; settings are restored afterwards")
+		longQueryTime   = flag.Duration("long-query-time", 0, "long_query_time for -server-slowlog; 0 logs every statement")
+		indexUsage      = flag.Bool("index-usage", false, "after the scenarios, report which indexes on orders the run used and which it never touched (performance_schema and sys.schema_unused_indexes)")
+		healthMode      = flag.String("health", "enforce", "server health checks before and after the run: enforce (refuse to start on failures), warn, or off")
+		provision       = flag.String("provision", "", "start the MySQL server before the run: docker runs a tuned MySQL container (created on first use, reused afterwards) instead of relying on docker compose")
+		teardown        = flag.Bool("teardown", false, "with -provision docker, remove the container and its data volume after the run")
+		dsn             = flag.String("dsn", "", dsnUsage)
+		compareDSN      = flag.String("compare-dsn", "", "second MySQL server as a go-sql-driver DSN (user:pass@tcp(host:3306)/slowlab): prepare and seed it like the first, run every scenario on both and print a side-by-side diff of latencies and plans")
+		readOnly        = flag.Bool("read-only", false, "never write to MySQL: skip schema migration, seeding and the partitioned table build, and run only the scenarios that are plain SELECTs without setup (with their EXPLAIN output); for servers that must not be changed")
+		maxOpenConns    = flag.Int("max-open-conns", 0, "client pool limit on open connections (0: MYSQL_MAX_OPEN_CONNS or 25)")
+		maxIdleConns    = flag.Int("max-idle-conns", 0, "client pool limit on idle connections (0: MYSQL_MAX_IDLE_CONNS or 5)")
+		connLifetime    = flag.Duration("conn-max-lifetime", 0, "close pooled connections after this long (0: MYSQL_CONN_MAX_LIFETIME or 5m)")
+		retries         = flag.Int("retries", retry.Default.Attempts, "retries after a transient connection error (dropped connection, server restarting) when connecting, per seeding batch and per read-only scenario; 0 disables retrying")
+		retryBackoff    = flag.Duration("retry-backoff", retry.Default.Backoff, "delay before the first retry; each further retry waits twice as long")
+		retryMaxBackoff = flag.Duration("retry-max-backoff", retry.Default.MaxBackoff, "longest delay between retries")
+		only            = flag.String("only", "", "comma-separated substrings; run only the scenarios whose type or name contains one of them")
+		configPath      = flag.String("config", defaultConfigFile, "YAML file of connection, seed, scenario and output settings; flags and MYSQL_* variables override its values")
+		comparePG       = flag.Bool("postgres", false, "also run each scenario's query on PostgreSQL (PG_* settings, make up-postgres) after copying orders and customers there, and report it next to MySQL")
+		verboseSQL      = flag.Bool("verbose-sql", false, "log every SQL statement with its duration and rows affected (GORM logger at info level), tagged with the seed or scenario setup step it belongs to; for finding out why a setup step is itself slow")
+	)
+	seedOpts := addSeedFlags(flag.CommandLine)
+	logOpts := addLogFlags(flag.CommandLine)
+	flag.Parse()
+	if err := loadConfigFile(flag.CommandLine, *configPath); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+
+	format, err := report.NewFormatter(*locale)
+	if err != nil {
+		fatal("invalid -locale", "err", err)
+	}
+	language := parseLang(*lang)
+	format = format.WithLang(language)
+	switch *healthMode {
+	case "enforce", "warn", "off":
+	default:
+		fatal("unknown health mode (want enforce, warn or off)", "health", *healthMode)
+	}
+
+	if *compareDSN != "" && *experiment != "" {
+		fatal("-compare-dsn compares scenario runs and cannot be combined with -experiment")
+	}
+	if *readOnly {
+		if *experiment != "" && *experiment != "list" {
+			fatal("-read-only cannot be combined with -experiment: experiments change data and server settings")
+		}
+		if !*skipSeed {
+			slog.Info("read-only mode: seeding disabled; scenarios run against the existing data")
+			*skipSeed = true
+		}
+		if *serverSlowlog {
+			slog.Warn("read-only mode: -server-slowlog changes global settings and is ignored")
+			*serverSlowlog = false
+		}
+	}
+
+	if *experiment == "list" {
+		for _, exp := range data.Experiments() {
+			family := exp.Family
+			if family == "" {
+				family = "-"
+			}
+			fmt.Printf("%-20s %-8s %s\n", exp.Name, family, exp.Description)
+		}
+		return
+	}
+
+	if *orderCount < data.CoveringCustomerTarget {
+		slog.Warn("-orders is below what the hot customer scenarios need; raising it", "orders", *orderCount, "min", data.CoveringCustomerTarget)
+		*orderCount = data.CoveringCustomerTarget
+	}
+
+	meta := report.Metadata{StartedAt: time.Now(), Build: buildinfo.Read()}
+
+	// -out sends the final results to a file instead of standard output; JSON and Markdown are
+	// written once the results are in, so the text tables only go to the run folder then.
+	stdout := io.Writer(os.Stdout)
+	outFormat := resultsTable
+	var outFile *os.File
+	if *outPath != "" {
+		outFormat = resultsFormat(*outPath)
+		if outFile, err = os.Create(*outPath); err != nil {
+			fatal("failed to create -out file", "err", err)
+		}
+		defer outFile.Close()
+		stdout = outFile
+		if outFormat != resultsTable {
+			stdout = io.Discard
+		}
+	}
+
+	// With -out-dir everything the run produces also lands in its own folder; relative
+	// artifact directories are created inside it.
+	out := stdout
+	runDir := ""
+	if *outDir != "" {
+		runDir, err = rundir.Create(*outDir, meta.StartedAt)
+		if err != nil {
+			fatal("failed to create run folder", "err", err)
+		}
+		logFile, err := os.Create(filepath.Join(runDir, rundir.LogFile))
+		if err != nil {
+			fatal("failed to create run log", "err", err)
+		}
+		defer logFile.Close()
+		logOpts.install(io.MultiWriter(os.Stderr, logFile))
+		reportFile, err := os.Create(filepath.Join(runDir, rundir.ReportFile))
+		if err != nil {
+			fatal("failed to create report file", "err", err)
+		}
+		defer reportFile.Close()
+		out = io.MultiWriter(stdout, reportFile)
+		*flameDir = rundir.Resolve(runDir, *flameDir)
+		*ioDir = rundir.Resolve(runDir, *ioDir)
+		slog.Info("run output", "dir", runDir)
+	}
+	// writeResults stores results in the -out file when it takes JSON or Markdown (rendered by
+	// markdown) and as results.json in the run folder.
+	writeResults := func(results report.Results, markdown func(io.Writer) error) {
+		var err error
+		switch outFormat {
+		case resultsJSON:
+			err = results.WriteJSON(outFile)
+		case resultsMarkdown:
+			err = markdown(outFile)
+		}
+		if err != nil {
+			fatal("failed to write -out file", "path", *outPath, "err", err)
+		}
+		if runDir == "" {
+			return
+		}
+		if err := results.WriteFile(filepath.Join(runDir, rundir.ResultsFile)); err != nil {
+			slog.Error("failed to write results", "err", err)
+		}
+		if err := results.Metadata.WriteFile(filepath.Join(runDir, rundir.InfoFile)); err != nil {
+			slog.Error("failed to write run info", "err", err)
+		}
+	}
+
+	slog.Info("slowlab build", "build", meta.Build.String())
+
+	cfg := connConfig(*dsn)
+	cfg.Pool = db.Pool{MaxOpenConns: *maxOpenConns, MaxIdleConns: *maxIdleConns, ConnMaxLifetime: *connLifetime}.Or(cfg.Pool)
+	cfg.Retry = retry.Policy{Attempts: *retries, Backoff: *retryBackoff, MaxBackoff: *retryMaxBackoff}
+	var container *docker.Container
+	switch *provision {
+	case "":
+	case "docker":
+		c := docker.ContainerFromEnv()
+		state, err := c.Start(context.Background(), cfg)
+		if err != nil {
+			fatal("failed to provision MySQL", "err", err)
+		}
+		slog.Info("waiting for MySQL in container", "container", c.Name, "image", c.Image, "state", state, "addr", cfg.Host+":"+cfg.Port)
+		container = &c
+	default:
+		fatal("unknown provision mode (want docker)", "provision", *provision)
+	}
+	var gdb *gorm.DB
+	if container != nil {
+		// First start initializes the data directory, which takes a minute or two.
+		gdb, err = db.OpenWait(context.Background(), cfg, 3*time.Minute)
+	} else {
+		gdb, err = db.Open(cfg)
+	}
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+	if *verboseSQL {
+		logAllSQL(gdb)
+	}
+	meta.Target = fmt.Sprintf("%s:%s/%s", cfg.Host, cfg.Port, cfg.Database)
+
+	if !*readOnly {
+		if err := data.EnsureSchema(gdb); err != nil {
+			fatal("failed to migrate schema", "err", err)
+		}
+	}
+
+	// The second target of -compare-dsn gets the same schema, data and scenarios; instrumentation
+	// that writes per-scenario artifacts stays with the first.
+	var gdbB *gorm.DB
+	metaB := meta
+	if *compareDSN != "" {
+		err := cfg.Retry.Do(context.Background(), "connect", func() error {
+			var err error
+			gdbB, err = db.OpenDSN(*compareDSN, cfg.Pool)
+			return err
+		})
+		if err != nil {
+			fatal("failed to connect to the -compare-dsn server", "err", err)
+		}
+		if *verboseSQL {
+			logAllSQL(gdbB)
+		}
+		metaB.Target = db.DSNTarget(*compareDSN)
+		if !*readOnly {
+			if err := data.EnsureSchema(gdbB); err != nil {
+				fatal("failed to migrate schema", "target", metaB.Target, "err", err)
+			}
+		}
+	}
+
+	dataset := seedOpts.dataset(*realistic)
+	ctx := data.WithDataset(data.WithProgress(interruptContext(context.Background()), logf), dataset)
+	version, err := data.DetectServerVersion(ctx, gdb)
+	if err != nil {
+		slog.Warn("failed to detect server version", "err", err)
+		meta.Server = "unknown"
+	} else {
+		meta.Server = version.Raw
+	}
+	if gdbB != nil {
+		if versionB, err := data.DetectServerVersion(ctx, gdbB); err != nil {
+			slog.Warn("failed to detect server version", "target", metaB.Target, "err", err)
+			metaB.Server = "unknown"
+		} else {
+			metaB.Server = versionB.Raw
+		}
+	}
+
+	healthCfg := health.DefaultConfig()
+	healthCfg.DiskFree = docker.FromEnv().DiskFree
+	if container != nil {
+		healthCfg.DiskFree = container.DiskFree
+	}
+	if *healthMode != "off" {
+		findings := checkHealth(ctx, gdb, healthCfg, "preflight")
+		if *healthMode == "enforce" && health.Failed(findings) {
+			fatal("preflight health check failed; fix the server or rerun with -health warn")
+		}
+	}
+	postRun := func() {
+		if *healthMode != "off" {
+			checkHealth(ctx, gdb, healthCfg, "post-run")
+		}
+		if container != nil && *teardown {
+			if err := container.Remove(ctx); err != nil {
+				slog.Error("failed to remove container", "container", container.Name, "err", err)
+			} else {
+				slog.Info("container and volume removed", "container", container.Name, "volume", container.Volume)
+			}
+		}
+	}
+
+	// Validate the scenarios before seeding so a broken scenario or pack fails in seconds, not after a long run.
+	var runCfg data.RunConfig
+	if *experiment == "" && !*skipScenarios {
+		runCfg = data.RunConfig{CaptureStages: *flameDir != "", Partitioned: *schema == "partitioned", Destructive: *destructive, Realistic: *realistic, ReadOnly: *readOnly, Lang: language, Retry: cfg.Retry}
+		if *only != "" {
+			runCfg.Only = strings.Split(*only, ",")
+		}
+		if *ioDir != "" {
+			runCfg.SampleIO = time.Second
+		}
+		if version.TiDB() && (runCfg.CaptureStages || runCfg.SampleIO > 0) {
+			// Both read performance_schema stages and InnoDB counters, which TiDB does not have.
+			slog.Warn("TiDB server: -flamegraph-dir and -io-samples-dir are ignored")
+			runCfg.CaptureStages, runCfg.SampleIO = false, 0
+		}
+		runCfg.Extra = loadPackScenarios(*packsDir)
+		if cacheCfg := cache.FromEnv(); cacheCfg.Enabled() {
+			rc, err := cache.Open(ctx, cacheCfg)
+			if err != nil {
+				slog.Warn("redis unavailable, skipping cache scenarios", "addr", cacheCfg.Addr, "err", err)
+			} else {
+				defer rc.Close()
+				runCfg.Cache = rc
+			}
+		}
+		if err := data.ValidateScenarios(ctx, gdb, runCfg); err != nil {
+			fatal("scenario validation failed", "err", err)
+		}
+	}
+
+	if !*skipSeed {
+		start := time.Now()
+		seedCfg := seedOpts.config(*orderCount, *batchSize, dataset, cfg.Retry)
+		if err := data.SeedDataset(ctx, gdb, seedCfg); err != nil {
+			fatal("failed to seed dataset", "err", err)
+		}
+		slog.Info("dataset ready", "orders", *orderCount, "duration_ms", ms(time.Since(start)))
+		if gdbB != nil {
+			start := time.Now()
+			if err := data.SeedDataset(ctx, gdbB, seedCfg); err != nil {
+				fatal("failed to seed dataset", "target", metaB.Target, "err", err)
+			}
+			slog.Info("dataset ready", "target", metaB.Target, "duration_ms", ms(time.Since(start)))
+		}
+	} else {
+		slog.Info("skip-seed enabled; reusing existing data")
+	}
+
+	switch *schema {
+	case "standard":
+	case "partitioned":
+		if *readOnly {
+			slog.Info("read-only mode: using the existing table", "table", data.PartitionedOrdersTable)
+			break
+		}
+		start := time.Now()
+		if err := data.EnsurePartitionedOrders(ctx, gdb); err != nil {
+			fatal("failed to build partitioned schema", "err", err)
+		}
+		if gdbB != nil {
+			if err := data.EnsurePartitionedOrders(ctx, gdbB); err != nil {
+				fatal("failed to build partitioned schema", "target", metaB.Target, "err", err)
+			}
+		}
+		slog.Info("partitioned table ready", "table", data.PartitionedOrdersTable, "duration_ms", ms(time.Since(start)))
+	default:
+		fatal("unknown schema mode (want standard or partitioned)", "schema", *schema)
+	}
+
+	if err := logDatasetStats(ctx, gdb); err != nil {
+		slog.Warn("failed to collect dataset stats", "err", err)
+	}
+
+	if *experiment != "" {
+		dockerCfg := docker.FromEnv()
+		sizes, err := parseIntList(*txSizes)
+		if err != nil {
+			fatal("invalid -tx-sizes", "err", err)
+		}
+		expCfg := data.ExperimentConfig{
+			Restart:           dockerCfg.Restart,
+			ApplyServerConfig: dockerCfg.ApplyOverride,
+			Target:            *target,
+			TxSizes:           sizes,
+			Scenarios:         loadPackScenarios(*packsDir),
+		}
+		if container != nil {
+			expCfg.Restart, expCfg.ApplyServerConfig = container.Restart, container.ApplyOverride
+		}
+		result, err := data.RunExperiment(ctx, gdb, *experiment, expCfg)
+		if err != nil {
+			fatal("experiment failed", "experiment", *experiment, "err", err)
+		}
+		if err := report.ExperimentTable(out, meta, format, result); err != nil {
+			fatal("failed to write the experiment table", "err", err)
+		}
+		writeResults(report.ExperimentResults(meta, result), func(w io.Writer) error {
+			return report.ExperimentMarkdown(w, meta, format, result)
+		})
+		postRun()
+		return
+	}
+
+	if *skipScenarios {
+		postRun()
+		slog.Info("skip-scenarios enabled; exiting")
+		return
+	}
+
+	if *comparePG {
+		pg, err := db.OpenPostgres(db.PostgresFromEnv())
+		if err != nil {
+			fatal("failed to connect to postgres", "err", err)
+		}
+		start := time.Now()
+		if err := data.SyncPostgres(ctx, gdb, pg); err != nil {
+			fatal("failed to copy the dataset to postgres", "err", err)
+		}
+		slog.Info("postgres dataset ready", "duration_ms", ms(time.Since(start)))
+		runCfg.Postgres = pg
+	}
+
+	var usageBefore data.IndexUsageSnapshot
+	if *indexUsage {
+		if usageBefore, err = data.TakeIndexUsageSnapshot(ctx, gdb); err != nil {
+			slog.Warn("index usage counters unavailable, skipping the report", "err", err)
+			*indexUsage = false
+		}
+	}
+
+	var capture *slowlog.Capture
+	if *serverSlowlog {
+		if capture, err = slowlog.StartCapture(ctx, gdb, *longQueryTime); err != nil {
+			slog.Warn("failed to enable the server slow log, running without it", "err", err)
+		} else if err := db.DropIdle(gdb, cfg.Pool); err != nil {
+			slog.Warn("failed to reset pooled connections", "err", err)
+		}
+	}
+
+	results := data.RunScenarios(ctx, gdb, runCfg)
+	var resultsB []data.ScenarioResult
+	if gdbB != nil {
+		slog.Info("running the scenarios", "target", metaB.Target)
+		cfgB := runCfg
+		cfgB.ServerVersion, cfgB.CaptureStages, cfgB.SampleIO, cfgB.Postgres = data.ServerVersion{}, false, 0, nil
+		resultsB = data.RunScenarios(ctx, gdbB, cfgB)
+	}
+	// Restoring the server and reporting must not be cancelled along with the scenarios.
+	interrupted := ctx.Err() != nil
+	ctx = context.WithoutCancel(ctx)
+
+	if capture != nil {
+		entries, err := capture.Entries(ctx)
+		if err != nil {
+			slog.Error("failed to read mysql.slow_log", "err", err)
+		}
+		if err := capture.Stop(ctx); err != nil {
+			slog.Error("failed to restore slow log settings", "err", err)
+		}
+		paired := data.AttachSlowLog(results, entries)
+		slog.Info("server slow log captured", "statements", len(entries), "paired", paired)
+	}
+
+	if *suggest {
+		for i, res := range results {
+			for _, sug := range advisor.SuggestIndexes(res.Query, res.Plan) {
+				results[i].Notes = append(results[i].Notes, "index suggestion: "+sug.String())
+			}
+		}
+	}
+
+	for _, res := range results {
+		slog.Info("scenario finished", "scenario", res.Name, "type", res.Type, "status", report.Status(res),
+			"duration_ms", ms(res.Duration), "rows", res.RowCount)
+		for _, warning := range res.Warnings {
+			slog.Warn(warning, "scenario", res.Name)
+		}
+	}
+
+	if *showExplain {
+		for _, res := range results {
+			switch data.ErrorKind(res.Err) {
+			case "skipped":
+				continue
+			case "setup", "execution":
+				slog.Warn("skipped explain", "scenario", res.Name, "error_kind", data.ErrorKind(res.Err), "err", res.Err)
+				continue
+			}
+			scenario := slog.With("scenario", res.Name)
+			scenario.Info(res.Description)
+			if res.Err != nil {
+				scenario.Warn("scenario error", "error_kind", data.ErrorKind(res.Err), "err", res.Err)
+			}
+			if len(res.Counters) > 0 {
+				scenario.Info("counters", "counters", formatCounters(res.Counters))
+			}
+			if e := res.SlowLog; e != nil {
+				scenario.Info("server slow log", "query_time_ms", ms(e.QueryTime), "lock_time_ms", ms(e.LockTime),
+					"rows_examined", e.RowsExamined, "rows_sent", e.RowsSent)
+			}
+			for _, note := range res.Notes {
+				scenario.Info("note", "note", note)
+			}
+			for _, line := range res.Explain {
+				scenario.Info("explain", "line", line)
+			}
+			if p := res.Postgres; p != nil && p.Err == nil {
+				scenario.Info("postgres", "query", p.Query)
+				for _, note := range p.Notes {
+					scenario.Info("postgres note", "note", note)
+				}
+				for _, line := range p.Plan {
+					scenario.Info("postgres plan", "line", line)
+				}
+			}
+		}
+	}
+
+	if err := report.ScenarioTable(out, meta, format, results); err != nil {
+		fatal("failed to write the scenario table", "err", err)
+	}
+	runResults := report.ScenarioResults(meta, results)
+	if gdbB != nil {
+		if err := report.DiffTable(out, format, report.Side{Meta: meta, Results: results}, report.Side{Meta: metaB, Results: resultsB}); err != nil {
+			fatal("failed to write the diff table", "err", err)
+		}
+		compare := report.ScenarioResults(metaB, resultsB)
+		runResults.Compare = &compare
+	}
+	writeResults(runResults, func(w io.Writer) error {
+		if err := report.ScenarioMarkdown(w, meta, format, results); err != nil {
+			return err
+		}
+		if gdbB == nil {
+			return nil
+		}
+		return report.DiffMarkdown(w, format, report.Side{Meta: meta, Results: results}, report.Side{Meta: metaB, Results: resultsB})
+	})
+
+	if *indexUsage {
+		usage, err := data.IndexUsageSince(ctx, gdb, usageBefore)
+		if err != nil {
+			slog.Error("failed to read index usage", "err", err)
+		} else if err := report.IndexUsageTable(out, format, usage); err != nil {
+			fatal("failed to write the index usage table", "err", err)
+		}
+	}
+
+	if *flameDir != "" {
+		paths, err := flamegraph.WriteDir(*flameDir, results)
+		if err != nil {
+			slog.Error("failed to write flamegraph stacks", "err", err)
+		}
+		for _, path := range paths {
+			slog.Info("folded stacks written", "path", path)
+		}
+		if len(paths) == 0 {
+			slog.Warn("no stage data captured; check performance_schema consumers in mysql/conf.d/slow.cnf")
+		} else if err := meta.WriteFile(filepath.Join(*flameDir, "run-info.txt")); err != nil {
+			slog.Error("failed to write run info", "err", err)
+		}
+	}
+
+	if *ioDir != "" {
+		paths, err := iotrace.WriteDir(*ioDir, results)
+		if err != nil {
+			slog.Error("failed to write IO samples", "err", err)
+		}
+		for _, path := range paths {
+			slog.Info("IO samples written", "path", path)
+		}
+		if len(paths) > 0 {
+			if err := meta.WriteFile(filepath.Join(*ioDir, "run-info.txt")); err != nil {
+				slog.Error("failed to write run info", "err", err)
+			}
+		}
+	}
+
+	postRun()
+	if interrupted {
+		os.Exit(exitInterrupted)
+	}
+	if code := exitCode(results); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// Exit codes by the most severe scenario error; 1 and 2 stay reserved for fatal errors and usage.
+var exitCodes = map[string]int{
+	"expectation": 3,
+	"explain":     4,
+	"execution":   5,
+	"setup":       6,
+}
+
+func exitCode(results []data.ScenarioResult) int {
+	code := 0
+	for _, res := range results {
+		if c := exitCodes[data.ErrorKind(res.Err)]; c > code {
+			code = c
+		}
+	}
+	return code
+}
+
+// dsnUsage documents the -dsn flag of every command that connects to MySQL.
+const dsnUsage = "go-sql-driver DSN to connect with (user:pass@tcp(host:3306)/slowlab), instead of the MYSQL_* environment variables; parseTime=true and loc=Local are added unless the DSN sets them"
+
+// connConfig returns the -dsn connection settings when the flag is set, otherwise the
+// environment's; the pool limits always come from the environment.
+func connConfig(dsn string) db.Config {
+	pool, err := db.PoolFromEnv()
+	if err != nil {
+		fatal("invalid connection pool settings", "err", err)
+	}
+	cfg := db.FromEnv()
+	if dsn != "" {
+		if cfg, err = db.FromDSN(dsn); err != nil {
+			fatal("invalid -dsn", "err", err)
+		}
+	}
+	cfg.Pool = pool
+	return cfg
+}
+
+// checkHealth logs every finding that is not OK and returns all of them.
+func checkHealth(ctx context.Context, gdb *gorm.DB, cfg health.Config, phase string) []health.Finding {
+	findings := health.Check(ctx, gdb, cfg)
+	problems := 0
+	for _, f := range findings {
+		if f.Level != health.OK {
+			slog.Warn("health check", "phase", phase, "finding", f.String())
+			problems++
+		}
+	}
+	if problems == 0 {
+		slog.Info("health checks passed", "phase", phase)
+	}
+	return findings
+}
+
+// loadPackScenarios returns the scenarios of installed and compiled-in packs.
+func loadPackScenarios(dir string) []data.Scenario {
+	packs, err := pack.LoadAll(dir)
+	if err != nil {
+		fatal("failed to load scenario packs", "err", err)
+	}
+	for _, p := range packs {
+		slog.Info("loaded pack", "pack", p.Manifest.Name, "version", p.Manifest.Version, "scenarios", len(p.Scenarios))
+	}
+	scenarios := pack.Scenarios(packs)
+	for _, p := range pack.Registered() {
+		slog.Info("compiled-in pack", "pack", p.Name, "version", p.Version, "scenarios", len(p.Scenarios))
+	}
+	return append(scenarios, pack.RegisteredScenarios()...)
+}
+
+func logDatasetStats(ctx context.Context, gdb *gorm.DB) error {
+	var orders int64
+	if err := gdb.WithContext(ctx).Model(&data.Order{}).Count(&orders).Error; err != nil {
+		return err
+	}
+	minExpected := int64(data.CoveringCustomerTarget + data.DateRangeOrderTarget)
+	slog.Info("current dataset size", "orders", orders, "min_expected", minExpected, "hot_customer", data.CoveringCustomerTarget, "date_range", data.DateRangeOrderTarget)
+	return nil
+}
+
+func formatCounters(counters []data.CounterDelta) string {
+	parts := make([]string, 0, len(counters))
+	for _, c := range counters {
+		parts = append(parts, fmt.Sprintf("%s=%+d", c.Name, c.Delta))
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseIntList parses a comma-separated list of integers; an empty string yields nil.
+func parseIntList(s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var values []int
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
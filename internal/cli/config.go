@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is read from the working directory when -config is not given.
+const defaultConfigFile = "slowlab.yaml"
+
+// configSections maps the keys of each slowlab.yaml section to the main-run flag they set.
+var configSections = map[string]map[string]string{
+	"connection": {
+		"dsn":         "dsn",
+		"compare_dsn": "compare-dsn",
+		"provision":   "provision",
+		"teardown":    "teardown",
+		"health":      "health",
+
+		"max_open_conns":    "max-open-conns",
+		"max_idle_conns":    "max-idle-conns",
+		"conn_max_lifetime": "conn-max-lifetime",
+		"retries":           "retries",
+		"retry_backoff":     "retry-backoff",
+		"retry_max_backoff": "retry-max-backoff",
+	},
+	"seed": {
+		"orders":            "orders",
+		"batch":             "batch",
+		"method":            "seed-method",
+		"distribution":      "distribution",
+		"zipf_s":            "zipf-s",
+		"time_distribution": "time-distribution",
+		"time_span_days":    "time-span-days",
+		"seed":              "seed",
+		"anchor":            "seed-anchor",
+		"realistic":         "realistic",
+		"skip":              "skip-seed",
+	},
+	"scenarios": {
+		"only":            "only",
+		"skip":            "skip-scenarios",
+		"schema":          "schema",
+		"packs_dir":       "packs-dir",
+		"destructive":     "destructive",
+		"read_only":       "read-only",
+		"postgres":        "postgres",
+		"experiment":      "experiment",
+		"target":          "target",
+		"tx_sizes":        "tx-sizes",
+		"suggest_indexes": "suggest-indexes",
+		"index_usage":     "index-usage",
+		"server_slowlog":  "server-slowlog",
+		"long_query_time": "long-query-time",
+	},
+	"output": {
+		"explain":        "explain",
+		"locale":         "locale",
+		"lang":           "lang",
+		"out":            "out",
+		"out_dir":        "out-dir",
+		"flamegraph_dir": "flamegraph-dir",
+		"io_samples_dir": "io-samples-dir",
+		"log_format":     "log-format",
+		"log_level":      "log-level",
+		"quiet":          "quiet",
+		"verbose_sql":    "verbose-sql",
+	},
+}
+
+// connectionEnv maps the remaining connection keys to the environment variables they stand in for.
+var connectionEnv = map[string]string{
+	"user":            "MYSQL_USER",
+	"password":        "MYSQL_PASSWORD",
+	"host":            "MYSQL_HOST",
+	"port":            "MYSQL_PORT",
+	"database":        "MYSQL_DATABASE",
+	"params":          "MYSQL_PARAMS",
+	"tls":             "MYSQL_TLS",
+	"tls_ca":          "MYSQL_TLS_CA",
+	"tls_cert":        "MYSQL_TLS_CERT",
+	"tls_key":         "MYSQL_TLS_KEY",
+	"tls_skip_verify": "MYSQL_TLS_SKIP_VERIFY",
+}
+
+// fileConfig is a decoded slowlab.yaml: section -> key -> scalar value, lists joined with commas.
+type fileConfig map[string]map[string]string
+
+// readConfigFile decodes path, rejecting sections and keys slowlab does not know.
+func readConfigFile(path string) (fileConfig, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]map[string]yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg := make(fileConfig, len(doc))
+	for section, keys := range doc {
+		known, ok := configSections[section]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown section %q (want connection, seed, scenarios or output)", path, section)
+		}
+		cfg[section] = make(map[string]string, len(keys))
+		for key, node := range keys {
+			if node.Tag == "!!null" {
+				continue
+			}
+			_, isFlag := known[key]
+			_, isEnv := connectionEnv[key]
+			if !isFlag && !(isEnv && section == "connection") {
+				return nil, fmt.Errorf("%s: unknown key %s.%s", path, section, key)
+			}
+			value, err := scalarValue(node)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s.%s: %w", path, section, key, err)
+			}
+			cfg[section][key] = value
+		}
+	}
+	return cfg, nil
+}
+
+// scalarValue renders node the way its flag expects it: scalars verbatim, lists comma-separated.
+func scalarValue(node yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", errors.New("list items must be scalars")
+			}
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", errors.New("want a value or a list of values")
+}
+
+// apply sets every flag of fs that the command line left unset from the file, and every
+// MYSQL_* variable the environment leaves unset from the connection section, so flags and
+// environment both override the file. Only the given sections apply, all when none are given;
+// keys whose flag fs does not define are ignored.
+func (c fileConfig) apply(fs *flag.FlagSet, sections ...string) error {
+	if len(sections) == 0 {
+		for section := range c {
+			sections = append(sections, section)
+		}
+		sort.Strings(sections)
+	}
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for _, section := range sections {
+		for key, value := range c[section] {
+			if env, ok := connectionEnv[key]; ok && section == "connection" {
+				if os.Getenv(env) == "" {
+					os.Setenv(env, value)
+				}
+				continue
+			}
+			name := configSections[section][key]
+			if explicit[name] || fs.Lookup(name) == nil {
+				continue
+			}
+			if err := fs.Set(name, value); err != nil {
+				return fmt.Errorf("%s.%s: %w", section, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadConfigFile applies path to fs (see fileConfig.apply). A missing defaultConfigFile is not
+// an error; the lab then runs on flags and environment alone.
+func loadConfigFile(fs *flag.FlagSet, path string, sections ...string) error {
+	cfg, err := readConfigFile(path)
+	if errors.Is(err, os.ErrNotExist) && path == defaultConfigFile {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return cfg.apply(fs, sections...)
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"mysql-slow-query-lab/internal/advisor"
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+)
+
+func runExplainCommand(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	dsn := fs.String("dsn", "", dsnUsage)
+	analyze := fs.Bool("analyze", false, "also run EXPLAIN ANALYZE (executes the query; SELECT/WITH/TABLE only)")
+	indexes := fs.String("index", "", `proposed indexes to check against key length limits, e.g. "orders(note, customer_name(20)); UNIQUE t(a)"`)
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, defaultConfigFile, "connection"); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+	if fs.NArg() > 1 || (fs.NArg() == 0 && *indexes == "") {
+		fmt.Fprintln(os.Stderr, `usage: slowlab explain [-analyze] [-index "table(col, ...)"] "<sql>"`)
+		os.Exit(2)
+	}
+	var proposed []advisor.ProposedIndex
+	for _, spec := range strings.Split(*indexes, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		idx, err := advisor.ParseIndex(spec)
+		if err != nil {
+			fatal("invalid -index", "err", err)
+		}
+		proposed = append(proposed, idx)
+	}
+
+	gdb, err := db.Open(connConfig(*dsn))
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+	ctx := context.Background()
+
+	for _, idx := range proposed {
+		check, err := advisor.CheckKeyLength(ctx, gdb, idx)
+		if err != nil {
+			fatal("index check failed", "err", err)
+		}
+		fmt.Printf("index %s: %s (%d bytes, row_format=%s, key part limit %d)\n", idx, check.Verdict, check.TotalBytes, check.RowFormat, check.PartLimit)
+		for _, part := range check.Parts {
+			fmt.Printf("    %-24s %s %s %d chars = %d bytes\n", part.Column, part.Type, orNull(part.Charset), part.Chars, part.Bytes)
+		}
+		for _, note := range check.Notes {
+			fmt.Printf("    %s\n", note)
+		}
+	}
+	if fs.NArg() == 0 {
+		return
+	}
+	query := strings.TrimRight(strings.TrimSpace(fs.Arg(0)), ";")
+
+	rows, err := data.ExplainPlan(ctx, gdb, query)
+	if err != nil {
+		fatal("EXPLAIN failed", "err", err)
+	}
+	notes := advisor.Annotate(rows)
+	for _, row := range rows {
+		table := row.Table
+		if table == "" {
+			table = "-"
+		}
+		fmt.Printf("%d %-8s table=%s type=%s key=%s possible_keys=%s rows=%d filtered=%.2f extra=%s\n",
+			row.ID, row.SelectType, table, orNull(row.Type), orNull(row.Key), orNull(row.PossibleKeys), row.Rows, row.Filtered, orNull(row.Extra))
+		for _, n := range notes {
+			if n.Table == table {
+				fmt.Printf("    %-24s %s\n", n.Element, n.Note)
+			}
+		}
+	}
+	for _, sug := range advisor.SuggestIndexes(query, rows) {
+		fmt.Printf("suggested index: %s;\n    %s\n", sug.DDL(), sug.Reason)
+	}
+
+	if !*analyze {
+		return
+	}
+	if !data.ReadOnlySQL(query) {
+		fatal("EXPLAIN ANALYZE executes the statement; refusing to run anything but a plain SELECT (no FOR UPDATE/SHARE, no INTO)")
+	}
+	tree, err := data.ExplainAnalyze(ctx, gdb, query)
+	if err != nil {
+		fatal("EXPLAIN ANALYZE failed (requires MySQL 8.0.18+, MariaDB or TiDB)", "err", err)
+	}
+	fmt.Println()
+	for _, line := range tree {
+		fmt.Println(line)
+		if note := advisor.AnnotateTree(line); note != "" {
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			fmt.Printf("%s    # %s\n", strings.Repeat(" ", indent), note)
+		}
+	}
+}
+
+func orNull(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+
+	"gorm.io/gorm"
+)
+
+func runExportCommand(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dsn := fs.String("dsn", "", dsnUsage)
+	format := fs.String("format", "sql", "sql (one script, gzip-compressed when -out ends in .gz) or csv (a directory with schema.sql and one CSV per table)")
+	out := fs.String("out", "", "output file (sql) or directory (csv)")
+	tables := fs.String("tables", strings.Join(data.DefaultExportTables, ","), "comma-separated tables to export")
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, defaultConfigFile, "connection"); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+	if *out == "" || fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: slowlab export [-format sql|csv] [-tables orders,customers,seed_state] -out dataset.sql.gz|dir")
+		os.Exit(2)
+	}
+	var names []string
+	for _, t := range strings.Split(*tables, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			names = append(names, t)
+		}
+	}
+
+	gdb, err := db.Open(connConfig(*dsn))
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+	ctx := context.Background()
+	start := time.Now()
+
+	switch *format {
+	case "sql":
+		err = exportSQLFile(ctx, gdb, *out, names)
+	case "csv":
+		err = data.ExportCSV(ctx, gdb, *out, names)
+	default:
+		fatal("unknown export format (want sql or csv)", "format", *format)
+	}
+	if err != nil {
+		fatal("export failed", "err", err)
+	}
+	slog.Info("export finished", "tables", strings.Join(names, ", "), "out", *out, "duration_ms", ms(time.Since(start)))
+}
+
+func exportSQLFile(ctx context.Context, gdb *gorm.DB, path string, tables []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var w io.Writer = f
+	if strings.HasSuffix(path, ".gz") {
+		zw := gzip.NewWriter(f)
+		defer zw.Close()
+		w = zw
+	}
+	if err := data.ExportSQL(ctx, gdb, w, tables); err != nil {
+		return err
+	}
+	if zw, ok := w.(*gzip.Writer); ok {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
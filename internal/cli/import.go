@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+)
+
+func runImportCommand(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dsn := fs.String("dsn", "", dsnUsage)
+	table := fs.String("table", "orders", "table to load: "+strings.Join(data.ImportTables(), ", "))
+	mapping := fs.String("map", "", `rename CSV columns to table columns, e.g. "order_total=total_amount,internal_flag=-" (- skips a column)`)
+	replace := fs.Bool("replace", false, "empty the table before loading (for orders also resets the seeding checkpoint)")
+	batch := fs.Int("batch", 1000, "rows per INSERT")
+	mask := fs.String("mask", "", `mask personal data on the way in, e.g. "phone=hash,customer_name=scramble,total_amount=bucket:50" (table column names)`)
+	maskSalt := fs.String("mask-salt", "", "secret key for hash/scramble; reuse it across files so masked values still join (random when empty)")
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, defaultConfigFile, "connection"); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, `usage: slowlab import [-table orders] [-map "csv_col=column,..."] [-replace] [-mask "column=method,..."] file.csv`)
+		os.Exit(2)
+	}
+	columnMap, err := data.ParseColumnMapping(*mapping)
+	if err != nil {
+		fatal("invalid -map", "err", err)
+	}
+	var masker *data.Masker
+	if *mask != "" {
+		if *maskSalt == "" {
+			key := make([]byte, 16)
+			if _, err := rand.Read(key); err != nil {
+				fatal("failed to generate a mask salt", "err", err)
+			}
+			*maskSalt = hex.EncodeToString(key)
+			slog.Warn("masking with a random salt; pass it as -mask-salt when importing related files so masked values match", "mask_salt", *maskSalt)
+		}
+		if masker, err = data.ParseMasker(*mask, *maskSalt); err != nil {
+			fatal("invalid -mask", "err", err)
+		}
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fatal("failed to open CSV", "err", err)
+	}
+	defer f.Close()
+
+	gdb, err := db.Open(connConfig(*dsn))
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+	ctx := context.Background()
+	if err := data.EnsureSchema(gdb); err != nil {
+		fatal("failed to apply schema", "err", err)
+	}
+
+	start := time.Now()
+	written, ignored, err := data.ImportCSV(ctx, gdb, f, data.ImportConfig{
+		Table:     *table,
+		Mapping:   columnMap,
+		Replace:   *replace,
+		BatchSize: *batch,
+		Mask:      masker,
+	})
+	if len(ignored) > 0 {
+		slog.Warn("ignored CSV columns that match no table column", "table", *table, "columns", strings.Join(ignored, ", "))
+	}
+	if err != nil {
+		fatal("import failed", "table", *table, "rows", written, "err", err)
+	}
+	slog.Info("import finished", "table", *table, "rows", written, "duration_ms", ms(time.Since(start)))
+}
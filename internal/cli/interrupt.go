@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// exitInterrupted is the exit status of a run stopped by SIGINT or SIGTERM, as shells report
+// a process killed by SIGINT.
+const exitInterrupted = 130
+
+// interruptContext returns a context cancelled by the first SIGINT or SIGTERM, so the running
+// scenario stops and the results so far are still reported; a second signal exits at once.
+func interruptContext(parent context.Context) context.Context {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+		slog.Warn("interrupted: stopping the running scenario and reporting the results so far; interrupt again to exit immediately")
+	}()
+	return ctx
+}
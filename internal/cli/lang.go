@@ -0,0 +1,14 @@
+package cli
+
+import "mysql-slow-query-lab/internal/i18n"
+
+const langUsage = "language of scenario types, names and descriptions and of report headers: zh or en"
+
+// parseLang resolves -lang, exiting on an unknown value.
+func parseLang(s string) i18n.Lang {
+	lang, err := i18n.Parse(s)
+	if err != nil {
+		fatal("invalid -lang", "err", err)
+	}
+	return lang
+}
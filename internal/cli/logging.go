@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"mysql-slow-query-lab/internal/data"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// logOptions are the -log-format, -log-level and -quiet flags every command accepts.
+type logOptions struct {
+	format *string
+	level  *string
+	quiet  *bool
+}
+
+func addLogFlags(fs *flag.FlagSet) logOptions {
+	return logOptions{
+		format: fs.String("log-format", "text", "log output: text (key=value lines) or json (one object per line, for log pipelines)"),
+		level:  fs.String("log-level", "info", "least severe log level printed: debug, info, warn or error"),
+		quiet:  fs.Bool("quiet", false, "print only warnings and errors (no progress or per-scenario logging), for scripts and cron"),
+	}
+}
+
+// install makes a logger writing to w with the flags' format and level the slog default;
+// the standard log package then writes through it as well.
+func (o logOptions) install(w io.Writer) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*o.level)); err != nil {
+		log.Fatalf("invalid -log-level %q (want debug, info, warn or error)", *o.level)
+	}
+	if *o.quiet && level < slog.LevelWarn {
+		level = slog.LevelWarn
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch *o.format {
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		log.Fatalf("unknown -log-format %q (want text or json)", *o.format)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// fatal logs msg with args at error level and exits with status 1, like log.Fatal.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// logf adapts slog to the printf-style loggers of the data package (progress, seeding).
+func logf(format string, args ...interface{}) {
+	slog.Info(fmt.Sprintf(format, args...))
+}
+
+// ms renders d as fractional milliseconds for the duration_ms field.
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// sqlLogLimit caps the SQL text of a -verbose-sql record; multi-row seed INSERTs run to megabytes.
+const sqlLogLimit = 500
+
+// sqlLogger is the GORM logger of -verbose-sql: at logger.Info it logs every statement with
+// its duration, rows affected and the data package stage (seed, setup) it ran in.
+type sqlLogger struct {
+	level logger.LogLevel
+}
+
+// logAllSQL switches gdb, and every session derived from it, to sqlLogger at logger.Info.
+func logAllSQL(gdb *gorm.DB) {
+	gdb.Logger = sqlLogger{level: logger.Info}
+}
+
+func (l sqlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.level = level
+	return l
+}
+
+func (l sqlLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l sqlLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l sqlLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	query, rows := fc()
+	if len(query) > sqlLogLimit {
+		cut := sqlLogLimit
+		for !utf8.RuneStart(query[cut]) {
+			cut--
+		}
+		query = fmt.Sprintf("%s… (%d bytes)", query[:cut], len(query))
+	}
+	args := []any{"sql", query, "duration_ms", ms(elapsed), "rows", rows}
+	if stage := data.Stage(ctx); stage != "" {
+		args = append(args, "stage", stage)
+	}
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		if l.level >= logger.Error {
+			slog.ErrorContext(ctx, "statement failed", append(args, "err", err)...)
+		}
+	case l.level >= logger.Info:
+		slog.InfoContext(ctx, "statement", args...)
+	}
+}
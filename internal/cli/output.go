@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Formats of the -out file.
+const (
+	resultsTable    = "table"
+	resultsJSON     = "json"
+	resultsMarkdown = "markdown"
+)
+
+// resultsFormat returns the -out format selected by the file's extension.
+func resultsFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return resultsJSON
+	case ".md", ".markdown":
+		return resultsMarkdown
+	}
+	return resultsTable
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"mysql-slow-query-lab/internal/pack"
+)
+
+const defaultPacksDir = "packs"
+
+func runPackCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: slowlab pack install [-dir packs] <pack.zip> | slowlab pack list [-dir packs]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("pack "+args[0], flag.ExitOnError)
+	dir := fs.String("dir", defaultPacksDir, "directory holding installed scenario packs")
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	logOpts.install(os.Stderr)
+
+	switch args[0] {
+	case "install":
+		if fs.NArg() != 1 {
+			fatal("usage: slowlab pack install [-dir packs] <pack.zip>")
+		}
+		p, err := pack.Install(fs.Arg(0), *dir)
+		if err != nil {
+			fatal("failed to install pack", "file", fs.Arg(0), "err", err)
+		}
+		slog.Info("installed pack", "pack", p.Manifest.Name, "version", p.Manifest.Version, "scenarios", len(p.Scenarios), "dir", p.Dir)
+		for _, doc := range p.Docs {
+			slog.Info("pack doc", "path", doc)
+		}
+	case "list":
+		packs, err := pack.LoadAll(*dir)
+		if err != nil {
+			fatal("failed to load packs", "err", err)
+		}
+		registered := pack.Registered()
+		if len(packs) == 0 && len(registered) == 0 {
+			slog.Info("no packs installed", "dir", *dir)
+		}
+		for _, p := range packs {
+			fmt.Printf("%-20s %-8s %-4s %2d scenarios  %s\n", p.Manifest.Name, p.Manifest.Version, "zip", len(p.Scenarios), p.Manifest.Description)
+		}
+		for _, p := range registered {
+			fmt.Printf("%-20s %-8s %-4s %2d scenarios  %s\n", p.Name, p.Version, "go", len(p.Scenarios), p.Description)
+		}
+	default:
+		fatal("unknown pack command (want install or list)", "command", args[0])
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/internal/advisor"
+	"mysql-slow-query-lab/internal/buildinfo"
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+	"mysql-slow-query-lab/internal/report"
+	"mysql-slow-query-lab/internal/slowlog"
+)
+
+func runReplayCommand(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	dsn := fs.String("dsn", "", dsnUsage)
+	top := fs.Int("top", 10, "number of query classes to replay (0 for all)")
+	sortBy := fs.String("sort", slowlog.SortTotal, "pick query classes by: "+strings.Join(slowlog.SortOrders(), ", "))
+	mapping := fs.String("map", "", `rename logged tables to lab tables, e.g. "orders_v2=orders,shop.buyers=customers"`)
+	schema := fs.String("db", "", "only replay statements logged against this database")
+	writes := fs.Bool("writes", false, "also replay statements that modify data (they change the lab dataset)")
+	showExplain := fs.Bool("explain", true, "log each replayed query's EXPLAIN output")
+	suggest := fs.Bool("suggest-indexes", true, "add index suggestions for full scans and filesorts to the notes")
+	locale := fs.String("locale", "raw", "number/duration formatting: "+strings.Join(report.Locales(), ", "))
+	lang := fs.String("lang", "zh", langUsage)
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, defaultConfigFile, "connection"); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, `usage: slowlab replay [-top 10] [-sort total|count|avg|max|rows] [-map "logged=lab,..."] [-db name] [-writes] slow.log|slow.log.gz|-`)
+		os.Exit(2)
+	}
+	if !slices.Contains(slowlog.SortOrders(), *sortBy) {
+		fatal("unknown sort order", "sort", *sortBy, "want", strings.Join(slowlog.SortOrders(), ", "))
+	}
+	tables, err := data.ParseTableMapping(*mapping)
+	if err != nil {
+		fatal("invalid -map", "err", err)
+	}
+	format, err := report.NewFormatter(*locale)
+	if err != nil {
+		fatal("invalid -locale", "err", err)
+	}
+	language := parseLang(*lang)
+	format = format.WithLang(language)
+
+	r, closeLog, err := openSlowlog(fs.Arg(0))
+	if err != nil {
+		fatal("failed to open slow log", "err", err)
+	}
+	digest := slowlog.NewDigest()
+	err = slowlog.Parse(r, func(e slowlog.Entry) error {
+		if *schema == "" || e.DB == *schema {
+			digest.Add(e)
+		}
+		return nil
+	})
+	closeLog()
+	if err != nil {
+		fatal("failed to read slow log", "err", err)
+	}
+	if digest.Entries == 0 {
+		slog.Info("no statements to replay", "file", fs.Arg(0))
+		return
+	}
+	classes := digest.Top(*top, *sortBy)
+
+	meta := report.Metadata{StartedAt: time.Now(), Build: buildinfo.Read()}
+	cfg := connConfig(*dsn)
+	gdb, err := db.Open(cfg)
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+	meta.Target = fmt.Sprintf("%s:%s/%s", cfg.Host, cfg.Port, cfg.Database)
+	ctx := interruptContext(context.Background())
+	version, err := data.DetectServerVersion(ctx, gdb)
+	if err != nil {
+		slog.Warn("failed to detect server version", "err", err)
+		meta.Server = "unknown"
+	} else {
+		meta.Server = version.Raw
+	}
+
+	slog.Info("replaying query classes", "classes", len(classes), "statements", digest.Entries, "file", fs.Arg(0))
+	results := data.ReplayClasses(ctx, gdb, classes, data.ReplayConfig{
+		Tables: tables,
+		Writes: *writes,
+		Run:    data.RunConfig{ServerVersion: version, Lang: language},
+	})
+
+	if *suggest {
+		for i, res := range results {
+			for _, sug := range advisor.SuggestIndexes(res.Query, res.Plan) {
+				results[i].Notes = append(results[i].Notes, "index suggestion: "+sug.String())
+			}
+		}
+	}
+	for _, res := range results {
+		for _, warning := range res.Warnings {
+			slog.Warn(warning, "replay", res.Name)
+		}
+		if !*showExplain || res.Skipped() {
+			continue
+		}
+		class := slog.With("replay", res.Name)
+		class.Info(res.Description, "duration_ms", ms(res.Duration), "rows", res.RowCount)
+		if res.Err != nil {
+			class.Warn("replay error", "error_kind", data.ErrorKind(res.Err), "err", res.Err)
+		}
+		for _, note := range res.Notes {
+			class.Info("note", "note", note)
+		}
+		for _, line := range res.Explain {
+			class.Info("explain", "line", line)
+		}
+	}
+
+	if err := report.ScenarioTable(os.Stdout, meta, format, results); err != nil {
+		fatal("failed to write the replay table", "err", err)
+	}
+	if ctx.Err() != nil {
+		os.Exit(exitInterrupted)
+	}
+	if code := exitCode(results); code != 0 {
+		os.Exit(code)
+	}
+}
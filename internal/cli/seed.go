@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"flag"
+	"time"
+
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/retry"
+)
+
+// seedOptions are the flags that shape the synthetic dataset. The main command and serve
+// share them so both, and the seed section of slowlab.yaml, produce and resume the same data.
+type seedOptions struct {
+	method       *string
+	distribution *string
+	zipfS        *float64
+	timeDist     *string
+	timeSpanDays *int
+	seed         *int64
+	anchor       *string
+}
+
+func addSeedFlags(fs *flag.FlagSet) seedOptions {
+	return seedOptions{
+		method:       fs.String("seed-method", data.SeedMethodInsert, "how to seed orders: insert (batched INSERT) or loaddata (CSV batches via LOAD DATA LOCAL INFILE, falling back to insert when the server disallows it)"),
+		distribution: fs.String("distribution", data.DistributionUniform, "how seeded orders spread over customer ids: uniform, or zipf for a few hot customers owning most orders"),
+		zipfS:        fs.Float64("zipf-s", data.DefaultZipfS, "Zipf exponent for -distribution zipf (> 1; larger means more skew)"),
+		timeDist:     fs.String("time-distribution", data.TimeUniform, "how seeded created_at values spread: uniform, business-hours (weighted toward office hours) or recent (exponential decay with age)"),
+		timeSpanDays: fs.Int("time-span-days", data.DefaultTimeSpanDays, "how many days back seeded created_at values reach"),
+		seed:         fs.Int64("seed", data.DefaultSeed, "random seed of the synthetic dataset, including rows the scenario setup adds"),
+		anchor:       fs.String("seed-anchor", "", "date (YYYY-MM-DD) generated timestamps are relative to instead of now; with -seed the dataset is reproducible byte-for-byte"),
+	}
+}
+
+// dataset returns the dataset the flags describe, exiting on an invalid -seed-anchor.
+func (o seedOptions) dataset(realistic bool) data.DatasetOptions {
+	var anchor time.Time
+	if *o.anchor != "" {
+		var err error
+		if anchor, err = time.ParseInLocation("2006-01-02", *o.anchor, time.Local); err != nil {
+			fatal("invalid -seed-anchor", "err", err)
+		}
+	}
+	return data.DatasetOptions{Seed: *o.seed, Anchor: anchor, Realistic: realistic}
+}
+
+// config returns the seeding configuration for orders rows in batches of batchSize.
+func (o seedOptions) config(orders, batchSize int, dataset data.DatasetOptions, policy retry.Policy) data.SeedConfig {
+	return data.SeedConfig{
+		Retry:            policy,
+		Orders:           orders,
+		BatchSize:        batchSize,
+		Method:           *o.method,
+		Distribution:     *o.distribution,
+		ZipfS:            *o.zipfS,
+		TimeDistribution: *o.timeDist,
+		TimeSpanDays:     *o.timeSpanDays,
+		Dataset:          dataset,
+	}
+}
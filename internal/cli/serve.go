@@ -0,0 +1,523 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"mysql-slow-query-lab/internal/advisor"
+	"mysql-slow-query-lab/internal/buildinfo"
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+	"mysql-slow-query-lab/internal/report"
+
+	"gorm.io/gorm"
+)
+
+// shutdownTimeout bounds how long serve waits for open requests when it is stopped.
+const shutdownTimeout = 10 * time.Second
+
+func runServeCommand(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "address to listen on; the API has no authentication, so only expose it to trusted clients")
+	dsn := fs.String("dsn", "", dsnUsage)
+	orderCount := fs.Int("orders", 1000000, "target number of orders for POST /seed and the seeding status")
+	batchSize := fs.Int("batch", 1000, "batch size for bulk inserts")
+	realistic := fs.Bool("realistic", false, "seed and run against realistic names and notes (see the main command's -realistic)")
+	packsDir := fs.String("packs-dir", defaultPacksDir, "directory of installed scenario packs to list and run after the built-ins")
+	destructive := fs.Bool("destructive", false, "also run scenarios that delete a slice of orders (restored by the next seeding)")
+	readOnly := fs.Bool("read-only", false, "never write to MySQL: no schema migration or seeding, and runs include only plain SELECT scenarios")
+	lang := fs.String("lang", "zh", langUsage)
+	configPath := fs.String("config", defaultConfigFile, "YAML file of connection, seed, scenario and output settings; flags and MYSQL_* variables override its values")
+	seedOpts := addSeedFlags(fs)
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, *configPath); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: slowlab serve [-config slowlab.yaml] [-addr 127.0.0.1:8080] [-orders n] [-seed n] [-seed-anchor YYYY-MM-DD] [-read-only]")
+		os.Exit(2)
+	}
+	if *orderCount < data.CoveringCustomerTarget {
+		slog.Warn("-orders is below what the hot customer scenarios need; raising it", "orders", *orderCount, "min", data.CoveringCustomerTarget)
+		*orderCount = data.CoveringCustomerTarget
+	}
+
+	cfg := connConfig(*dsn)
+	gdb, err := db.Open(cfg)
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	dataset := seedOpts.dataset(*realistic)
+	ctx = data.WithDataset(ctx, dataset)
+	if !*readOnly {
+		if err := data.EnsureSchema(gdb); err != nil {
+			fatal("failed to migrate schema", "err", err)
+		}
+	}
+
+	meta := report.Metadata{Build: buildinfo.Read(), Target: fmt.Sprintf("%s:%s/%s", cfg.Host, cfg.Port, cfg.Database)}
+	version, err := data.DetectServerVersion(ctx, gdb)
+	if err != nil {
+		slog.Warn("failed to detect server version", "err", err)
+		meta.Server = "unknown"
+	} else {
+		meta.Server = version.Raw
+	}
+	s := &labServer{
+		gdb:  gdb,
+		ctx:  ctx,
+		meta: meta,
+		runCfg: data.RunConfig{
+			ServerVersion: version,
+			Destructive:   *destructive,
+			Realistic:     *realistic,
+			ReadOnly:      *readOnly,
+			Lang:          parseLang(*lang),
+			Retry:         cfg.Retry,
+			Extra:         loadPackScenarios(*packsDir),
+		},
+		seedCfg: seedOpts.config(*orderCount, *batchSize, dataset, cfg.Retry),
+	}
+	if err := data.ValidateScenarios(ctx, gdb, s.runCfg); err != nil {
+		fatal("scenario validation failed", "err", err)
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		slog.Info("stopping: cancelling the running scenario or seeding")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("failed to close open requests", "err", err)
+		}
+	}()
+	slog.Info("serving the lab API", "addr", *addr, "target", meta.Target)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fatal("server failed", "err", err)
+	}
+	s.jobs.Wait()
+	slog.Info("serve stopped")
+}
+
+// labServer is the state behind slowlab serve. Runs, seeding and EXPLAIN share the database,
+// so at most one of them is in progress at a time.
+type labServer struct {
+	gdb     *gorm.DB
+	ctx     context.Context // cancelled on shutdown; the running job stops with it
+	meta    report.Metadata
+	runCfg  data.RunConfig
+	seedCfg data.SeedConfig
+	jobs    sync.WaitGroup
+
+	mu   sync.Mutex
+	busy string // what holds the database ("run 3", "seed", "explain"), empty when idle
+	runs []*serveRun
+	seed seedJob
+}
+
+// serveRun is one scenario run triggered through POST /runs.
+type serveRun struct {
+	ID         int             `json:"id"`
+	Status     string          `json:"status"` // running, done or interrupted
+	Only       []string        `json:"only,omitempty"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Results    *report.Results `json:"results,omitempty"`
+}
+
+// seedJob is the last seeding started through POST /seed.
+type seedJob struct {
+	running    bool
+	startedAt  time.Time
+	finishedAt time.Time
+	err        error
+}
+
+func (s *labServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /scenarios", s.listScenarios)
+	mux.HandleFunc("GET /runs", s.listRuns)
+	mux.HandleFunc("POST /runs", s.startRun)
+	mux.HandleFunc("GET /runs/{id}", s.getRun)
+	mux.HandleFunc("GET /explain", s.explain)
+	mux.HandleFunc("POST /explain", s.explain)
+	mux.HandleFunc("GET /seed", s.seedStatus)
+	mux.HandleFunc("POST /seed", s.startSeed)
+	return mux
+}
+
+// scenarioRecord is one entry of GET /scenarios.
+type scenarioRecord struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Query       string `json:"query,omitempty"`
+}
+
+func (s *labServer) listScenarios(w http.ResponseWriter, r *http.Request) {
+	cfg := s.runCfg
+	cfg.Only = onlyParam(r)
+	records := []scenarioRecord{}
+	for _, sc := range data.ListScenarios(cfg) {
+		records = append(records, scenarioRecord{Type: sc.Type, Name: sc.Name, Description: sc.Description, Query: sc.Query})
+	}
+	writeJSON(w, http.StatusOK, records)
+}
+
+func (s *labServer) listRuns(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	runs := make([]serveRun, 0, len(s.runs))
+	for _, run := range s.runs {
+		summary := *run
+		summary.Results = nil
+		runs = append(runs, summary)
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *labServer) getRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	s.mu.Lock()
+	if err != nil || id < 1 || id > len(s.runs) {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "no such run")
+		return
+	}
+	run := *s.runs[id-1]
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, run)
+}
+
+// startRun runs the scenarios selected by ?only= in the background and answers with the run
+// to poll at /runs/{id}.
+func (s *labServer) startRun(w http.ResponseWriter, r *http.Request) {
+	cfg := s.runCfg
+	cfg.Only = onlyParam(r)
+	if len(data.ListScenarios(cfg)) == 0 {
+		writeError(w, http.StatusBadRequest, "no scenario matches only="+strings.Join(cfg.Only, ","))
+		return
+	}
+	s.mu.Lock()
+	if s.busy != "" {
+		busy := s.busy
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, busy+" is in progress")
+		return
+	}
+	run := &serveRun{ID: len(s.runs) + 1, Status: "running", Only: cfg.Only, StartedAt: time.Now()}
+	s.runs = append(s.runs, run)
+	s.busy = fmt.Sprintf("run %d", run.ID)
+	accepted := *run
+	s.jobs.Add(1)
+	s.mu.Unlock()
+
+	slog.Info("run started", "run", run.ID, "only", strings.Join(cfg.Only, ","))
+	go s.run(run, cfg)
+	w.Header().Set("Location", fmt.Sprintf("/runs/%d", run.ID))
+	writeJSON(w, http.StatusAccepted, accepted)
+}
+
+func (s *labServer) run(run *serveRun, cfg data.RunConfig) {
+	defer s.jobs.Done()
+	results := data.RunScenarios(data.WithProgress(s.ctx, logf), s.gdb, cfg)
+	for i, res := range results {
+		for _, sug := range advisor.SuggestIndexes(res.Query, res.Plan) {
+			results[i].Notes = append(results[i].Notes, "index suggestion: "+sug.String())
+		}
+	}
+	meta := s.meta
+	meta.StartedAt = run.StartedAt
+	records := report.ScenarioResults(meta, results)
+	finished := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run.Status = "done"
+	if s.ctx.Err() != nil {
+		run.Status = "interrupted"
+	}
+	run.FinishedAt, run.Results = &finished, &records
+	s.busy = ""
+	slog.Info("run finished", "run", run.ID, "status", run.Status, "scenarios", len(results), "duration_ms", ms(finished.Sub(run.StartedAt)))
+}
+
+// planRecord is one EXPLAIN row of /explain, named after the EXPLAIN columns, with the
+// advisor's notes on it.
+type planRecord struct {
+	ID           int64    `json:"id"`
+	SelectType   string   `json:"select_type"`
+	Table        string   `json:"table"`
+	Partitions   string   `json:"partitions,omitempty"`
+	Type         string   `json:"type"`
+	PossibleKeys string   `json:"possible_keys"`
+	Key          string   `json:"key"`
+	KeyLen       string   `json:"key_len"`
+	Ref          string   `json:"ref"`
+	Rows         int64    `json:"rows"`
+	Filtered     float64  `json:"filtered"`
+	Extra        string   `json:"extra"`
+	Notes        []string `json:"notes,omitempty"`
+}
+
+// explainRecord is the answer of GET and POST /explain.
+type explainRecord struct {
+	Query       string       `json:"query"`
+	Plan        []planRecord `json:"plan"`
+	Suggestions []string     `json:"suggestions,omitempty"`
+	Analyze     []string     `json:"analyze,omitempty"`
+}
+
+// explainRequest is the JSON body of POST /explain; GET /explain takes query and scenario as
+// URL parameters and cannot analyze.
+type explainRequest struct {
+	Query    string `json:"query"`
+	Scenario string `json:"scenario"`
+	Analyze  bool   `json:"analyze"`
+}
+
+// maxExplainBody bounds the JSON body of POST /explain.
+const maxExplainBody = 1 << 20
+
+// explain runs EXPLAIN for a query, or for the query of a named scenario. EXPLAIN ANALYZE
+// executes the statement, so it is only run for a POST with a JSON body, which a page in the
+// browser cannot send to another origin without the server's consent, never in -read-only
+// mode, only for plain reads and inside a read-only transaction (see data.ExplainAnalyze).
+// Either way it holds the database like a run, so it cannot disturb a run's timings.
+func (s *labServer) explain(w http.ResponseWriter, r *http.Request) {
+	var req explainRequest
+	if r.Method == http.MethodPost {
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, "POST /explain takes a JSON body (Content-Type: application/json)")
+			return
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxExplainBody)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+	} else {
+		params := r.URL.Query()
+		if params.Has("analyze") {
+			writeError(w, http.StatusMethodNotAllowed, `EXPLAIN ANALYZE executes the statement; send POST /explain with {"analyze": true}`)
+			return
+		}
+		req = explainRequest{Query: params.Get("query"), Scenario: params.Get("scenario")}
+	}
+	query := strings.TrimRight(strings.TrimSpace(req.Query), ";")
+	if req.Scenario != "" {
+		if query != "" {
+			writeError(w, http.StatusBadRequest, "pass query or scenario, not both")
+			return
+		}
+		for _, sc := range data.ListScenarios(s.runCfg) {
+			if sc.Name == req.Scenario {
+				query = sc.Query
+				break
+			}
+		}
+		if query == "" {
+			writeError(w, http.StatusNotFound, "no scenario with a query is named "+req.Scenario)
+			return
+		}
+	}
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "missing query or scenario")
+		return
+	}
+	if req.Analyze {
+		if s.runCfg.ReadOnly {
+			writeError(w, http.StatusForbidden, "read-only mode: EXPLAIN ANALYZE disabled")
+			return
+		}
+		if !data.ReadOnlySQL(query) {
+			writeError(w, http.StatusBadRequest, "EXPLAIN ANALYZE executes the statement; refusing to run anything but a plain SELECT (no FOR UPDATE/SHARE, no INTO)")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	busy := s.busy
+	if busy == "" {
+		s.busy = "explain"
+	}
+	s.mu.Unlock()
+	if busy != "" {
+		writeError(w, http.StatusConflict, busy+" is in progress")
+		return
+	}
+	defer func() {
+		s.mu.Lock()
+		s.busy = ""
+		s.mu.Unlock()
+	}()
+
+	rows, err := data.ExplainPlan(r.Context(), s.gdb, query)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "EXPLAIN failed: "+err.Error())
+		return
+	}
+	rec := explainRecord{Query: query, Plan: make([]planRecord, 0, len(rows))}
+	notes := advisor.Annotate(rows)
+	for _, row := range rows {
+		plan := planRecord{
+			ID: row.ID, SelectType: row.SelectType, Table: row.Table, Partitions: row.Partitions,
+			Type: row.Type, PossibleKeys: row.PossibleKeys, Key: row.Key, KeyLen: row.KeyLen,
+			Ref: row.Ref, Rows: row.Rows, Filtered: row.Filtered, Extra: row.Extra,
+		}
+		table := row.Table
+		if table == "" {
+			table = "-"
+		}
+		for _, n := range notes {
+			if n.Table == table {
+				plan.Notes = append(plan.Notes, n.Element+": "+n.Note)
+			}
+		}
+		rec.Plan = append(rec.Plan, plan)
+	}
+	for _, sug := range advisor.SuggestIndexes(query, rows) {
+		rec.Suggestions = append(rec.Suggestions, sug.String())
+	}
+	if req.Analyze {
+		if rec.Analyze, err = data.ExplainAnalyze(r.Context(), s.gdb, query); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "EXPLAIN ANALYZE failed (requires MySQL 8.0.18+, MariaDB or TiDB): "+err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
+
+// seedRecord is the answer of GET /seed and POST /seed.
+type seedRecord struct {
+	Target      int        `json:"target"`
+	RowsWritten int64      `json:"rows_written"`
+	Complete    bool       `json:"complete"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Running     bool       `json:"running"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+func (s *labServer) seedStatus(w http.ResponseWriter, r *http.Request) {
+	rec, err := s.seedRecord(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read the seeding checkpoint: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
+
+// startSeed seeds the dataset up to -orders in the background, resuming from the checkpoint;
+// GET /seed follows its progress.
+func (s *labServer) startSeed(w http.ResponseWriter, r *http.Request) {
+	if s.runCfg.ReadOnly {
+		writeError(w, http.StatusForbidden, "read-only mode: seeding disabled")
+		return
+	}
+	s.mu.Lock()
+	if s.busy != "" {
+		busy := s.busy
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, busy+" is in progress")
+		return
+	}
+	s.busy = "seed"
+	s.seed = seedJob{running: true, startedAt: time.Now()}
+	s.jobs.Add(1)
+	s.mu.Unlock()
+
+	slog.Info("seeding started", "orders", s.seedCfg.Orders)
+	go s.runSeed()
+	rec, err := s.seedRecord(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read the seeding checkpoint: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, rec)
+}
+
+func (s *labServer) runSeed() {
+	defer s.jobs.Done()
+	err := data.SeedDataset(data.WithProgress(s.ctx, logf), s.gdb, s.seedCfg)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seed.running, s.seed.finishedAt, s.seed.err = false, time.Now(), err
+	s.busy = ""
+	if err != nil {
+		slog.Error("seeding failed", "err", err)
+		return
+	}
+	slog.Info("dataset ready", "orders", s.seedCfg.Orders, "duration_ms", ms(s.seed.finishedAt.Sub(s.seed.startedAt)))
+}
+
+// seedRecord combines the checkpoint in the database with the state of the last POST /seed.
+func (s *labServer) seedRecord(ctx context.Context) (seedRecord, error) {
+	state, err := data.SeedStatus(ctx, s.gdb)
+	if err != nil {
+		return seedRecord{}, err
+	}
+	rec := seedRecord{Target: s.seedCfg.Orders, RowsWritten: state.RowsWritten, Complete: state.RowsWritten >= int64(s.seedCfg.Orders)}
+	if !state.UpdatedAt.IsZero() {
+		rec.UpdatedAt = &state.UpdatedAt
+	}
+	s.mu.Lock()
+	job := s.seed
+	s.mu.Unlock()
+	rec.Running = job.running
+	if !job.startedAt.IsZero() {
+		rec.StartedAt = &job.startedAt
+	}
+	if !job.finishedAt.IsZero() {
+		rec.FinishedAt = &job.finishedAt
+	}
+	if job.err != nil {
+		rec.Error = job.err.Error()
+	}
+	return rec, nil
+}
+
+// onlyParam splits the comma-separated ?only= filter, as -only does.
+func onlyParam(r *http.Request) []string {
+	var only []string
+	for _, pattern := range strings.Split(r.FormValue("only"), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			only = append(only, pattern)
+		}
+	}
+	return only
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Warn("failed to write response", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
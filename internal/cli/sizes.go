@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"strings"
+
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+	"mysql-slow-query-lab/internal/report"
+)
+
+func runSizesCommand(args []string) {
+	fs := flag.NewFlagSet("sizes", flag.ExitOnError)
+	dsn := fs.String("dsn", "", dsnUsage)
+	analyze := fs.Bool("analyze", false, "run ANALYZE TABLE first so row estimates and sizes reflect recent writes")
+	locale := fs.String("locale", "raw", "number/size formatting: "+strings.Join(report.Locales(), ", "))
+	lang := fs.String("lang", "zh", langUsage)
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, defaultConfigFile, "connection"); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+	format, err := report.NewFormatter(*locale)
+	if err != nil {
+		fatal("invalid -locale", "err", err)
+	}
+	format = format.WithLang(parseLang(*lang))
+
+	gdb, err := db.Open(connConfig(*dsn))
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+	sizes, err := data.TableSizes(context.Background(), gdb, *analyze)
+	if err != nil {
+		fatal("failed to read table sizes", "err", err)
+	}
+	if len(sizes) == 0 {
+		slog.Info("no lab tables in this database; run slowlab first to seed them")
+		return
+	}
+	if err := report.SizesTable(os.Stdout, format, sizes); err != nil {
+		fatal("failed to render sizes", "err", err)
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"mysql-slow-query-lab/internal/report"
+	"mysql-slow-query-lab/internal/slowlog"
+)
+
+func runAnalyzeSlowlogCommand(args []string) {
+	fs := flag.NewFlagSet("analyze-slowlog", flag.ExitOnError)
+	top := fs.Int("top", 10, "number of query classes to show (0 for all)")
+	sortBy := fs.String("sort", slowlog.SortTotal, "rank query classes by: "+strings.Join(slowlog.SortOrders(), ", "))
+	locale := fs.String("locale", "raw", "number/duration formatting: "+strings.Join(report.Locales(), ", "))
+	lang := fs.String("lang", "zh", langUsage)
+	output := fs.String("format", "table", "report layout: table, or pt for pt-query-digest's report")
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	logOpts.install(os.Stderr)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: slowlab analyze-slowlog [-top 10] [-sort total|count|avg|max|rows] [-format table|pt] slow.log|slow.log.gz|-")
+		os.Exit(2)
+	}
+	if !slices.Contains(slowlog.SortOrders(), *sortBy) {
+		fatal("unknown sort order", "sort", *sortBy, "want", strings.Join(slowlog.SortOrders(), ", "))
+	}
+	if *output != "table" && *output != "pt" {
+		fatal("unknown format (want table or pt)", "format", *output)
+	}
+	format, err := report.NewFormatter(*locale)
+	if err != nil {
+		fatal("invalid -locale", "err", err)
+	}
+	format = format.WithLang(parseLang(*lang))
+
+	r, closeLog, err := openSlowlog(fs.Arg(0))
+	if err != nil {
+		fatal("failed to open slow log", "err", err)
+	}
+	defer closeLog()
+	digest := slowlog.NewDigest()
+	err = slowlog.Parse(r, func(e slowlog.Entry) error {
+		digest.Add(e)
+		return nil
+	})
+	if err != nil {
+		fatal("failed to read slow log", "err", err)
+	}
+	if digest.Entries == 0 {
+		slog.Info("no statements found", "file", fs.Arg(0))
+		return
+	}
+	classes := digest.Top(*top, *sortBy)
+	if *output == "pt" {
+		err = slowlog.WritePTDigest(os.Stdout, digest, classes, []string{fs.Arg(0)})
+	} else {
+		err = report.DigestTable(os.Stdout, format, digest, classes)
+	}
+	if err != nil {
+		fatal("failed to write the digest", "err", err)
+	}
+}
+
+// openSlowlog opens a slow log file, "-" for stdin, decompressing it when the name ends in .gz.
+func openSlowlog(path string) (io.Reader, func(), error) {
+	if path == "-" {
+		return os.Stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, func() { f.Close() }, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return zr, func() { zr.Close(); f.Close() }, nil
+}
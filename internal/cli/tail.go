@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/internal/db"
+	"mysql-slow-query-lab/internal/report"
+	"mysql-slow-query-lab/internal/slowlog"
+	"mysql-slow-query-lab/pkg/fingerprint"
+)
+
+func runTailSlowlogCommand(args []string) {
+	fs := flag.NewFlagSet("tail-slowlog", flag.ExitOnError)
+	dsn := fs.String("dsn", "", dsnUsage)
+	table := fs.Bool("table", false, "follow mysql.slow_log instead of a file (needs log_output to include TABLE)")
+	fromStart := fs.Bool("from-start", false, "also print the entries already in the log")
+	interval := fs.Duration("interval", 500*time.Millisecond, "how often to check for new entries")
+	top := fs.Int("top", 10, "query classes in the digest printed on exit (0 for all)")
+	locale := fs.String("locale", "raw", "number/duration formatting: "+strings.Join(report.Locales(), ", "))
+	lang := fs.String("lang", "zh", langUsage)
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, defaultConfigFile, "connection"); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+	if *table == (fs.NArg() == 1) || fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: slowlab tail-slowlog [-from-start] slow.log|-\n       slowlab tail-slowlog -table [-from-start]")
+		os.Exit(2)
+	}
+	format, err := report.NewFormatter(*locale)
+	if err != nil {
+		fatal("invalid -locale", "err", err)
+	}
+	format = format.WithLang(parseLang(*lang))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	digest := slowlog.NewDigest()
+	show := func(e slowlog.Entry) error {
+		c := digest.Add(e)
+		fmt.Printf("%s %8s lock %-8s rows %s/%s %s [%s ×%d avg %s] %s\n",
+			e.Time.Local().Format("15:04:05.000"), format.Duration(e.QueryTime), format.Duration(e.LockTime),
+			format.Count(e.RowsSent), format.Count(e.RowsExamined), orNull(e.DB),
+			fingerprint.ID(c.Fingerprint), c.Count, format.Duration(c.AvgTime()), c.Fingerprint)
+		return nil
+	}
+
+	switch {
+	case *table:
+		gdb, err := db.Open(connConfig(*dsn))
+		if err != nil {
+			fatal("failed to connect to MySQL", "err", err)
+		}
+		slog.Info("following mysql.slow_log; Ctrl-C prints the digest", "interval", interval.String())
+		err = slowlog.FollowTable(ctx, gdb, *fromStart, *interval, show)
+	case fs.Arg(0) == "-":
+		slog.Info("following stdin; Ctrl-C prints the digest")
+		err = slowlog.FollowReader(ctx, os.Stdin, *interval, show)
+	default:
+		slog.Info("following slow log; Ctrl-C prints the digest", "file", fs.Arg(0), "interval", interval.String())
+		err = slowlog.FollowFile(ctx, fs.Arg(0), *fromStart, *interval, show)
+	}
+	if err != nil {
+		fatal("failed to follow slow log", "err", err)
+	}
+	if digest.Entries == 0 {
+		slog.Info("no slow queries captured")
+		return
+	}
+	fmt.Println()
+	if err := report.DigestTable(os.Stdout, format, digest, digest.Top(*top, slowlog.SortTotal)); err != nil {
+		fatal("failed to write the digest", "err", err)
+	}
+}
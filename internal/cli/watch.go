@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"mysql-slow-query-lab/internal/db"
+	"mysql-slow-query-lab/internal/planwatch"
+
+	"gorm.io/gorm"
+)
+
+func runWatchCommand(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	dsn := fs.String("dsn", "", dsnUsage)
+	configPath := fs.String("config", "watch.yaml", "watch configuration (queries, interval, baseline, webhook)")
+	once := fs.Bool("once", false, "check once and exit with status 1 when a plan changed")
+	accept := fs.Bool("accept", false, "overwrite the baseline with the current plans and exit")
+	logOpts := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		fatal("invalid arguments", "err", err)
+	}
+	if err := loadConfigFile(fs, defaultConfigFile, "connection"); err != nil {
+		fatal("failed to load config", "err", err)
+	}
+	logOpts.install(os.Stderr)
+
+	cfg, err := planwatch.LoadConfig(*configPath)
+	if err != nil {
+		fatal("failed to load watch config", "err", err)
+	}
+	interval, _ := time.ParseDuration(cfg.Interval)
+
+	gdb, err := db.Open(connConfig(*dsn))
+	if err != nil {
+		fatal("failed to connect to MySQL", "err", err)
+	}
+
+	baseline, err := planwatch.LoadBaseline(cfg.Baseline)
+	if err != nil {
+		fatal("failed to load baseline", "err", err)
+	}
+	if *accept {
+		baseline = make(map[string]planwatch.Plan)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	changed := watchOnce(ctx, gdb, cfg, baseline)
+	if *once || *accept {
+		if changed && !*accept {
+			os.Exit(1)
+		}
+		return
+	}
+
+	slog.Info("watching queries", "queries", len(cfg.Queries), "interval", interval.String(), "baseline", cfg.Baseline)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("watch stopped")
+			return
+		case <-ticker.C:
+			watchOnce(ctx, gdb, cfg, baseline)
+		}
+	}
+}
+
+// watchOnce runs one check, reports changes through the log and webhook, and reports whether any plan changed.
+func watchOnce(ctx context.Context, gdb *gorm.DB, cfg planwatch.Config, baseline map[string]planwatch.Plan) bool {
+	changes, updated, errs := planwatch.Check(ctx, gdb, cfg, baseline)
+	for _, err := range errs {
+		slog.Warn("plan check failed", "err", err)
+	}
+	if updated {
+		if err := planwatch.SaveBaseline(cfg.Baseline, baseline); err != nil {
+			slog.Warn("failed to save baseline", "err", err)
+		} else {
+			slog.Info("baseline recorded", "baseline", cfg.Baseline)
+		}
+	}
+	for _, change := range changes {
+		slog.Warn("PLAN CHANGED", "query", change.Query, "baseline", change.Baseline, "current", change.Current)
+		if cfg.Webhook != "" {
+			if err := planwatch.Notify(ctx, cfg.Webhook, change); err != nil {
+				slog.Warn("webhook failed", "err", err)
+			}
+		}
+	}
+	return len(changes) > 0
+}
@@ -0,0 +1,106 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// coreTables hold the seeded dataset; EnsureSchema recreates them.
+var coreTables = []string{"orders", "customers", "seed_state"}
+
+// scenarioIndexes are the indexes scenario setup hooks add to orders on first use.
+var scenarioIndexes = []string{
+	"idx_orders_created_at_date",
+	"idx_orders_created_at_desc",
+	"idx_orders_customer_created",
+	"idx_orders_status_total",
+}
+
+// helperTables lists the tables scenario setup hooks create, plus the ones experiments drop
+// when they finish and only leave behind when interrupted.
+func helperTables() []string {
+	tables := []string{
+		"orders_archive", dupesTable, fulltextTable, "gap_lock_demo", histogramTable, jsonTable,
+		PartitionedOrdersTable, prefixTable, spatialTable, staleStatsTable, "upsert_counters",
+		"commit_bench", "fk_bench_items", "fk_bench_orders", "fk_bench_customers", "flush_load",
+		"isolation_demo", "redo_burst", triggerAuditTable, triggerBenchTable,
+	}
+	for _, t := range contactTables {
+		tables = append(tables, t.name)
+	}
+	for _, v := range uuidVariants {
+		tables = append(tables, v.table)
+	}
+	return tables
+}
+
+// CleanStatements returns the statements that reset the lab in this database, limited to
+// what exists. Without drop the dataset tables are emptied but keep their schema, while
+// scenario indexes and helper tables (including the partitioned copy) are dropped. With drop
+// every lab table goes, taking the generated columns and indexes of migrations with it; the
+// next run rebuilds the schema from scratch.
+func CleanStatements(ctx context.Context, db *gorm.DB, drop bool) ([]string, error) {
+	var existing []string
+	err := db.WithContext(ctx).Raw(`SELECT TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME IN ?`, append(slices.Clone(coreTables), helperTables()...)).
+		Scan(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stmts []string
+	var dropTables []string
+	for _, t := range helperTables() {
+		if slices.Contains(existing, t) {
+			dropTables = append(dropTables, t)
+		}
+	}
+	for _, t := range coreTables {
+		if !slices.Contains(existing, t) {
+			continue
+		}
+		if drop {
+			dropTables = append(dropTables, t)
+		} else {
+			stmts = append(stmts, "TRUNCATE TABLE "+t)
+		}
+	}
+	if !drop && slices.Contains(existing, "orders") {
+		var indexes []string
+		err := db.WithContext(ctx).Raw(`SELECT DISTINCT INDEX_NAME FROM information_schema.STATISTICS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'orders' AND INDEX_NAME IN ?`, scenarioIndexes).
+			Scan(&indexes).Error
+		if err != nil {
+			return nil, err
+		}
+		slices.Sort(indexes)
+		for _, idx := range indexes {
+			stmts = append(stmts, fmt.Sprintf("DROP INDEX %s ON orders", idx))
+		}
+	}
+	if len(dropTables) > 0 {
+		// Clean turns foreign key checks off, so the order of the tables does not matter.
+		stmts = append(stmts, "DROP TABLE IF EXISTS "+strings.Join(dropTables, ", "))
+	}
+	return stmts, nil
+}
+
+// Clean runs statements from CleanStatements on one connection with foreign key checks off.
+func Clean(ctx context.Context, db *gorm.DB, stmts []string) error {
+	return db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SET FOREIGN_KEY_CHECKS = 1")
+		for _, stmt := range stmts {
+			if err := conn.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("%s: %w", stmt, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package data
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// DefaultSeed is the random seed of the synthetic dataset when none is configured.
+const DefaultSeed = 42
+
+// Random streams of the dataset: each part draws from its own generator so that adding rows
+// to one part never shifts the values of another.
+const (
+	streamOrders = iota
+	streamCustomers
+	streamPhoneHot
+	streamDateRange
+)
+
+type datasetKey struct{}
+
+// DatasetOptions shape the generated data beyond its size; see WithDataset.
+type DatasetOptions struct {
+	// Seed drives every random value (DefaultSeed when zero).
+	Seed int64
+	// Anchor, when set, replaces the current time as the reference for generated timestamps.
+	Anchor time.Time
+	// Realistic generates plausible names, notes and discount codes instead of the synthetic ones.
+	Realistic bool
+}
+
+// WithDataset returns a context under which SeedDataset and the scenario setup hooks generate
+// data according to opts. With Seed and Anchor fixed the dataset is reproducible byte-for-byte.
+func WithDataset(ctx context.Context, opts DatasetOptions) context.Context {
+	return context.WithValue(ctx, datasetKey{}, opts)
+}
+
+func datasetFrom(ctx context.Context) DatasetOptions {
+	opts, _ := ctx.Value(datasetKey{}).(DatasetOptions)
+	if opts.Seed == 0 {
+		opts.Seed = DefaultSeed
+	}
+	return opts
+}
+
+// datasetStyle is the orderStyle of the dataset in ctx.
+func datasetStyle(ctx context.Context) orderStyle {
+	return orderStyle{realistic: datasetFrom(ctx).Realistic}
+}
+
+// datasetRand returns the generator of one stream of the dataset.
+func datasetRand(ctx context.Context, stream int64) *rand.Rand {
+	// Stream 0 (orders) keeps the bare seed, so the default dataset is what it always was.
+	return rand.New(rand.NewSource(datasetFrom(ctx).Seed + stream*1_000_003))
+}
+
+// datasetNow is the reference time generated timestamps are relative to.
+func datasetNow(ctx context.Context) time.Time {
+	if anchor := datasetFrom(ctx).Anchor; !anchor.IsZero() {
+		return anchor
+	}
+	return time.Now()
+}
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Customer id distributions accepted by SeedConfig.Distribution.
+const (
+	DistributionUniform = "uniform"
+	DistributionZipf    = "zipf"
+)
+
+const (
+	// seedCustomers is how many distinct customer ids seeded orders spread over.
+	seedCustomers = 50000
+	// zipfScatter maps Zipf ranks onto customer ids; it is coprime with seedCustomers, so the
+	// mapping is a permutation and hot customers are spread over the id range instead of
+	// being ids 1, 2, 3...
+	zipfScatter = 7919
+	// DefaultZipfS is the Zipf exponent used when none is configured; with 50k customers the
+	// hottest one gets about 14% of the orders.
+	DefaultZipfS = 1.1
+)
+
+// customerPicker returns the customer id of the next seeded order.
+type customerPicker func() uint
+
+// newCustomerPicker draws customer ids from rnd following distribution; s is the Zipf exponent
+// and must be greater than 1.
+func newCustomerPicker(distribution string, s float64, rnd *rand.Rand) (customerPicker, error) {
+	switch distribution {
+	case "", DistributionUniform:
+		return func() uint { return uint(rnd.Intn(seedCustomers) + 1) }, nil
+	case DistributionZipf:
+		if s <= 1 {
+			return nil, fmt.Errorf("zipf exponent must be greater than 1, got %g", s)
+		}
+		zipf := rand.NewZipf(rnd, s, 1, seedCustomers-1)
+		return func() uint { return uint(zipf.Uint64()*zipfScatter%seedCustomers + 1) }, nil
+	default:
+		return nil, fmt.Errorf("unknown distribution %q (want %s or %s)", distribution, DistributionUniform, DistributionZipf)
+	}
+}
+
+// created_at distributions accepted by SeedConfig.TimeDistribution.
+const (
+	TimeUniform       = "uniform"
+	TimeBusinessHours = "business-hours"
+	TimeRecent        = "recent"
+)
+
+// DefaultTimeSpanDays is how far back seeded created_at values reach when no span is configured.
+const DefaultTimeSpanDays = 365
+
+// businessHourWeights are the relative order volumes per hour of day for TimeBusinessHours:
+// quiet nights, a morning ramp, a plateau through office hours and an evening tail.
+var businessHourWeights = [24]int{1, 1, 1, 1, 1, 1, 2, 4, 8, 12, 14, 14, 12, 14, 14, 13, 12, 10, 8, 7, 6, 4, 2, 1}
+
+// createdPicker returns the created_at of the next seeded order.
+type createdPicker func(now time.Time) time.Time
+
+// newCreatedPicker draws created_at values up to spanDays before now from rnd. Uniform spreads
+// them evenly by the hour; business-hours picks a uniform day and a businessHourWeights hour;
+// recent decays exponentially with age so the last weeks hold most orders.
+func newCreatedPicker(distribution string, spanDays int, rnd *rand.Rand) (createdPicker, error) {
+	if spanDays < 1 {
+		return nil, fmt.Errorf("time span must be at least one day, got %d", spanDays)
+	}
+	spanHours := spanDays * 24
+	switch distribution {
+	case "", TimeUniform:
+		return func(now time.Time) time.Time {
+			return now.Add(-time.Duration(rnd.Intn(spanHours)) * time.Hour)
+		}, nil
+	case TimeBusinessHours:
+		total := 0
+		for _, w := range businessHourWeights {
+			total += w
+		}
+		return func(now time.Time) time.Time {
+			day := now.AddDate(0, 0, -rnd.Intn(spanDays))
+			n := rnd.Intn(total)
+			hour := 0
+			for n >= businessHourWeights[hour] {
+				n -= businessHourWeights[hour]
+				hour++
+			}
+			t := time.Date(day.Year(), day.Month(), day.Day(), hour, rnd.Intn(60), rnd.Intn(60), 0, now.Location())
+			if t.After(now) {
+				t = t.AddDate(0, 0, -1)
+			}
+			return t
+		}, nil
+	case TimeRecent:
+		// A mean age of a fifth of the span leaves under 1% of orders beyond it; those are redrawn.
+		mean := float64(spanHours) / 5
+		return func(now time.Time) time.Time {
+			for {
+				age := rnd.ExpFloat64() * mean
+				if age < float64(spanHours) {
+					return now.Add(-time.Duration(age * float64(time.Hour)))
+				}
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown time distribution %q (want %s, %s or %s)", distribution, TimeUniform, TimeBusinessHours, TimeRecent)
+	}
+}
@@ -0,0 +1,34 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// driverConfig returns the go-sql-driver settings the gorm DB was opened with, for scenarios
+// that need connections outside its pool.
+func driverConfig(db *gorm.DB) (*mysql.Config, error) {
+	d, ok := db.Dialector.(*gormmysql.Dialector)
+	if !ok || d.DSN == "" {
+		return nil, errors.New("connection settings are not available (not opened from a MySQL DSN)")
+	}
+	return mysql.ParseDSN(d.DSN)
+}
+
+// openVariant opens a separate *sql.DB with the gorm DB's settings changed by adjust.
+func openVariant(db *gorm.DB, adjust func(*mysql.Config)) (*sql.DB, error) {
+	cfg, err := driverConfig(db)
+	if err != nil {
+		return nil, err
+	}
+	adjust(cfg)
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
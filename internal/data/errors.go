@@ -0,0 +1,81 @@
+package data
+
+import (
+	"errors"
+	"strings"
+)
+
+// The error types below classify ScenarioResult.Err so callers can tell a broken lab
+// environment (setup) from a failing query (execution) or a plan that drifted (expectation).
+
+// SetupError means the scenario could not be prepared; its query never ran.
+type SetupError struct {
+	// Stage is the step that failed: setup, setup sql, args or optimizer_switch.
+	Stage string
+	Err   error
+}
+
+func (e *SetupError) Error() string { return e.Stage + ": " + e.Err.Error() }
+func (e *SetupError) Unwrap() error { return e.Err }
+
+// ExecutionError means the scenario query (or its Run hook) failed.
+type ExecutionError struct {
+	Err error
+}
+
+func (e *ExecutionError) Error() string { return e.Err.Error() }
+func (e *ExecutionError) Unwrap() error { return e.Err }
+
+// ExplainError means the query ran but its plan could not be collected.
+type ExplainError struct {
+	Err error
+}
+
+func (e *ExplainError) Error() string { return "explain: " + e.Err.Error() }
+func (e *ExplainError) Unwrap() error { return e.Err }
+
+// ExpectationError means the query ran but its plan differs from the scenario's ExpectPlan.
+type ExpectationError struct {
+	Mismatches []string
+}
+
+func (e *ExpectationError) Error() string {
+	return "unexpected plan: " + strings.Join(e.Mismatches, "; ")
+}
+
+// SkippedError means the scenario does not apply to this server (e.g. its MinVersion is not met).
+type SkippedError struct {
+	Reason string
+}
+
+func (e *SkippedError) Error() string { return e.Reason }
+
+// ErrorKind names the class of a scenario error: setup, execution, explain, expectation or skipped.
+// Errors of other types are reported as execution failures.
+func ErrorKind(err error) string {
+	var (
+		setup   *SetupError
+		explain *ExplainError
+		expect  *ExpectationError
+		skipped *SkippedError
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &skipped):
+		return "skipped"
+	case errors.As(err, &setup):
+		return "setup"
+	case errors.As(err, &explain):
+		return "explain"
+	case errors.As(err, &expect):
+		return "expectation"
+	default:
+		return "execution"
+	}
+}
+
+// Skipped reports whether the scenario was not run at all.
+func (r ScenarioResult) Skipped() bool {
+	return ErrorKind(r.Err) == "skipped"
+}
@@ -0,0 +1,196 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Experiment is a workload-level demonstration (bulk writes, concurrency, server settings)
+// whose outcome is a table of variants rather than a single query timing.
+type Experiment struct {
+	Name string
+	// Family groups related experiments in listings, e.g. the server tuning lab.
+	Family      string
+	Description string
+	Run         func(context.Context, *gorm.DB, ExperimentConfig) (ExperimentReport, error)
+}
+
+// ExperimentConfig carries the hooks experiments need from the host environment.
+type ExperimentConfig struct {
+	// Restart restarts the MySQL server; experiments that need it fail when it is nil.
+	Restart func(context.Context) error
+	// ApplyServerConfig persists [mysqld] settings (nil resets them) and restarts the server,
+	// for variables that cannot be changed with SET GLOBAL.
+	ApplyServerConfig func(context.Context, map[string]string) error
+	// Target names the object an experiment acts on, e.g. "orders.idx_orders_customer_id" for invisible-index.
+	Target string
+	// TxSizes are the transaction sizes (rows per COMMIT) the autocommit experiment compares; empty means DefaultTxSizes.
+	TxSizes []int
+	// Scenarios are extra scenarios (e.g. from packs) that scenario-driven experiments consider besides the built-ins.
+	Scenarios []Scenario
+}
+
+// ExperimentReport is the tabular outcome of an experiment; each row is one variant.
+type ExperimentReport struct {
+	Name    string
+	Columns []string
+	Rows    [][]string
+	Notes   []string
+}
+
+const familyServerTuning = "服务器调优"
+
+// Experiments lists the built-in experiments in display order.
+func Experiments() []Experiment {
+	return []Experiment{
+		asyncWriteExperiment(),
+		backupImpactExperiment(),
+		bufferPoolWarmupExperiment(),
+		redoLogExperiment(),
+		flushMethodExperiment(),
+		invisibleIndexExperiment(),
+		uuidPrimaryKeyExperiment(),
+		triggerOverheadExperiment(),
+		foreignKeyExperiment(),
+		isolationExperiment(),
+		autocommitExperiment(),
+		maxConnectionsExperiment(),
+	}
+}
+
+// RunExperiment executes the named experiment.
+func RunExperiment(ctx context.Context, db *gorm.DB, name string, cfg ExperimentConfig) (ExperimentReport, error) {
+	for _, exp := range Experiments() {
+		if exp.Name == name {
+			report, err := exp.Run(ctx, db, cfg)
+			report.Name = exp.Name
+			return report, err
+		}
+	}
+	names := make([]string, 0)
+	for _, exp := range Experiments() {
+		names = append(names, exp.Name)
+	}
+	return ExperimentReport{}, fmt.Errorf("unknown experiment %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// latencyStats summarises per-operation latencies for experiment reports.
+type latencyStats struct {
+	Avg time.Duration
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+func summarizeLatencies(samples []time.Duration) latencyStats {
+	if len(samples) == 0 {
+		return latencyStats{}
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	pick := func(p float64) time.Duration {
+		idx := int(p * float64(len(sorted)-1))
+		return sorted[idx]
+	}
+	return latencyStats{
+		Avg: total / time.Duration(len(sorted)),
+		P50: pick(0.50),
+		P95: pick(0.95),
+		P99: pick(0.99),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+func perSecond(n int, elapsed time.Duration) string {
+	if elapsed <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", float64(n)/elapsed.Seconds())
+}
+
+// deleteOrdersByID removes rows an experiment inserted, in primary-key chunks.
+func deleteOrdersByID(ctx context.Context, db *gorm.DB, ids []uint) error {
+	const chunk = 1000
+	for start := 0; start < len(ids); start += chunk {
+		end := start + chunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := db.WithContext(ctx).Where("id IN ?", ids[start:end]).Delete(&Order{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForServer polls until MySQL accepts queries again, e.g. after a restart.
+func waitForServer(ctx context.Context, db *gorm.DB, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := db.WithContext(ctx).Exec("SELECT 1").Error
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("server not ready after %s: %w", timeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func globalStatus(ctx context.Context, db *gorm.DB, name string) (string, error) {
+	var value string
+	err := db.WithContext(ctx).
+		Raw("SELECT VARIABLE_VALUE FROM performance_schema.global_status WHERE VARIABLE_NAME = ?", name).
+		Row().Scan(&value)
+	return value, err
+}
+
+func globalStatusInt(ctx context.Context, db *gorm.DB, name string) (int64, error) {
+	value, err := globalStatus(ctx, db, name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func globalVariable(ctx context.Context, db *gorm.DB, name string) (string, error) {
+	var value string
+	err := db.WithContext(ctx).
+		Raw("SELECT VARIABLE_VALUE FROM performance_schema.global_variables WHERE VARIABLE_NAME = ?", name).
+		Row().Scan(&value)
+	return value, err
+}
+
+// enableMetric turns on an INNODB_METRICS counter (most are off by default) and reports whether it is enabled.
+func enableMetric(ctx context.Context, db *gorm.DB, name string) bool {
+	if err := db.WithContext(ctx).Exec("SET GLOBAL innodb_monitor_enable = ?", name).Error; err != nil {
+		return false
+	}
+	var status string
+	err := db.WithContext(ctx).Raw("SELECT STATUS FROM information_schema.INNODB_METRICS WHERE NAME = ?", name).
+		Row().Scan(&status)
+	return err == nil && status == "enabled"
+}
+
+func innodbMetric(ctx context.Context, db *gorm.DB, name string) (int64, error) {
+	var count int64
+	err := db.WithContext(ctx).Raw("SELECT COUNT FROM information_schema.INNODB_METRICS WHERE NAME = ?", name).
+		Row().Scan(&count)
+	return count, err
+}
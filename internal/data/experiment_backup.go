@@ -0,0 +1,182 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	backupProbeDuration = 10 * time.Second
+	backupProbeWorkers  = 4
+)
+
+func backupImpactExperiment() Experiment {
+	return Experiment{
+		Name: "backup-impact",
+		Description: fmt.Sprintf("探针负载（主键读 + 聚合读 + 单行更新）各跑 %s：空闲 vs 同时进行 --single-transaction 风格的全表逻辑导出。",
+			backupProbeDuration),
+		Run: runBackupImpactExperiment,
+	}
+}
+
+type probeOutcome struct {
+	pointReads []time.Duration
+	aggReads   []time.Duration
+	writes     []time.Duration
+	err        error
+}
+
+func runBackupImpactExperiment(ctx context.Context, db *gorm.DB, _ ExperimentConfig) (ExperimentReport, error) {
+	report := ExperimentReport{
+		Columns: []string{"变体", "主键读 次数/P95", "聚合读 次数/P95", "更新 次数/P95", "导出行数", "undo history 增长"},
+	}
+	minID, maxID, err := orderIDRange(ctx, db)
+	if err != nil {
+		return report, err
+	}
+
+	for _, withDump := range []bool{false, true} {
+		historyBefore, historyErr := undoHistoryLength(ctx, db)
+
+		probeCtx, cancel := context.WithTimeout(ctx, backupProbeDuration)
+		var dumped int64
+		var dumpErr error
+		var dumpWG sync.WaitGroup
+		if withDump {
+			dumpWG.Add(1)
+			go func() {
+				defer dumpWG.Done()
+				dumped, dumpErr = simulateLogicalDump(probeCtx, db)
+			}()
+		}
+		outcome := runProbeWorkload(probeCtx, db, minID, maxID)
+		dumpWG.Wait()
+
+		historyAfter, historyAfterErr := undoHistoryLength(ctx, db)
+		cancel()
+
+		if outcome.err != nil {
+			return report, outcome.err
+		}
+		if dumpErr != nil && probeCtx.Err() == nil {
+			return report, fmt.Errorf("dump: %w", dumpErr)
+		}
+
+		name := "无备份"
+		dumpCol := "-"
+		if withDump {
+			name = "一致性快照导出中"
+			dumpCol = fmt.Sprint(dumped)
+		}
+		historyCol := "-"
+		if historyErr == nil && historyAfterErr == nil {
+			historyCol = fmt.Sprintf("%+d", historyAfter-historyBefore)
+		}
+		report.Rows = append(report.Rows, []string{
+			name,
+			formatProbe(outcome.pointReads),
+			formatProbe(outcome.aggReads),
+			formatProbe(outcome.writes),
+			dumpCol,
+			historyCol,
+		})
+	}
+	report.Notes = append(report.Notes,
+		"导出连接持有 REPEATABLE READ 一致性快照并顺序读取整张 orders：与业务争用 buffer pool 和 IO，且快照期间的更新无法 purge，undo history 持续增长。",
+		"undo history 长度来自 information_schema.INNODB_METRICS（需要 PROCESS 权限），不可用时显示为 -。")
+	return report, nil
+}
+
+// simulateLogicalDump mirrors what `mysqldump --single-transaction` does for one table.
+func simulateLogicalDump(ctx context.Context, db *gorm.DB) (int64, error) {
+	var rowsRead int64
+	err := db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ").Error; err != nil {
+			return err
+		}
+		if err := conn.Exec("START TRANSACTION WITH CONSISTENT SNAPSHOT").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("ROLLBACK")
+
+		rows, err := conn.Raw("SELECT * FROM orders").Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var order Order
+			if err := conn.ScanRows(rows, &order); err != nil {
+				return err
+			}
+			rowsRead++
+		}
+		return rows.Err()
+	})
+	return rowsRead, err
+}
+
+func runProbeWorkload(ctx context.Context, db *gorm.DB, minID, maxID uint64) probeOutcome {
+	var (
+		mu      sync.Mutex
+		outcome probeOutcome
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < backupProbeWorkers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for i := 0; ctx.Err() == nil; i++ {
+				id := minID + uint64(rnd.Int63n(int64(maxID-minID+1)))
+				start := time.Now()
+				var err error
+				var bucket *[]time.Duration
+				switch i % 3 {
+				case 0:
+					var order Order
+					err = db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&order).Error
+					bucket = &outcome.pointReads
+				case 1:
+					var n int64
+					err = db.WithContext(ctx).Model(&Order{}).Where("customer_id = ?", rnd.Intn(50000)+1).Count(&n).Error
+					bucket = &outcome.aggReads
+				default:
+					err = db.WithContext(ctx).Model(&Order{}).Where("id = ?", id).
+						UpdateColumn("updated_at", time.Now()).Error
+					bucket = &outcome.writes
+				}
+				latency := time.Since(start)
+				if ctx.Err() != nil {
+					return
+				}
+				mu.Lock()
+				if err != nil && outcome.err == nil {
+					outcome.err = err
+				}
+				*bucket = append(*bucket, latency)
+				mu.Unlock()
+			}
+		}(int64(w + 1))
+	}
+	wg.Wait()
+	return outcome
+}
+
+func formatProbe(samples []time.Duration) string {
+	stats := summarizeLatencies(samples)
+	return fmt.Sprintf("%d / %s", len(samples), stats.P95.Round(time.Microsecond))
+}
+
+func undoHistoryLength(ctx context.Context, db *gorm.DB) (int64, error) {
+	var length int64
+	err := db.WithContext(ctx).
+		Raw("SELECT `COUNT` FROM information_schema.INNODB_METRICS WHERE NAME = 'trx_rseg_history_len'").
+		Row().Scan(&length)
+	return length, err
+}
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	bufferPoolWorkingSetQuery = "SELECT COUNT(*), COALESCE(SUM(total_amount), 0) FROM orders WHERE customer_id BETWEEN 1 AND 5000"
+	bufferPoolRestartTimeout  = 2 * time.Minute
+	bufferPoolStatusTimeout   = 5 * time.Minute
+)
+
+func bufferPoolWarmupExperiment() Experiment {
+	return Experiment{
+		Name:        "buffer-pool-warmup",
+		Family:      familyServerTuning,
+		Description: "同一工作集查询：预热后 vs 重启后冷启动（禁止启动时加载）vs innodb_buffer_pool_load_now 恢复之后。",
+		Run:         runBufferPoolWarmupExperiment,
+	}
+}
+
+func runBufferPoolWarmupExperiment(ctx context.Context, db *gorm.DB, cfg ExperimentConfig) (ExperimentReport, error) {
+	report := ExperimentReport{
+		Columns: []string{"阶段", "查询耗时", "磁盘读页数(Innodb_buffer_pool_reads)", "buffer pool 数据页"},
+	}
+	if cfg.Restart == nil {
+		return report, errors.New("buffer-pool-warmup needs a restart hook (docker compose)")
+	}
+
+	measure := func(stage string) error {
+		readsBefore, err := globalStatusInt(ctx, db, "Innodb_buffer_pool_reads")
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		if err := db.WithContext(ctx).Exec(bufferPoolWorkingSetQuery).Error; err != nil {
+			return err
+		}
+		elapsed := time.Since(start)
+		readsAfter, err := globalStatusInt(ctx, db, "Innodb_buffer_pool_reads")
+		if err != nil {
+			return err
+		}
+		pages, err := globalStatus(ctx, db, "Innodb_buffer_pool_pages_data")
+		if err != nil {
+			return err
+		}
+		report.Rows = append(report.Rows, []string{stage, elapsed.Round(time.Millisecond).String(), fmt.Sprint(readsAfter - readsBefore), pages})
+		return nil
+	}
+
+	// Warm the working set, then measure it hot and dump the pool's page list.
+	if err := db.WithContext(ctx).Exec(bufferPoolWorkingSetQuery).Error; err != nil {
+		return report, err
+	}
+	if err := measure("预热后"); err != nil {
+		return report, err
+	}
+	if err := db.WithContext(ctx).Exec("SET GLOBAL innodb_buffer_pool_dump_now = ON").Error; err != nil {
+		return report, fmt.Errorf("dump buffer pool: %w", err)
+	}
+	if err := waitForStatus(ctx, db, "Innodb_buffer_pool_dump_status", "completed"); err != nil {
+		return report, err
+	}
+
+	// Restart without the automatic startup load to get a genuinely cold pool.
+	if err := db.WithContext(ctx).Exec("SET PERSIST_ONLY innodb_buffer_pool_load_at_startup = OFF").Error; err != nil {
+		return report, fmt.Errorf("disable load at startup: %w", err)
+	}
+	defer func() {
+		// Restore even when the experiment context was cancelled, or the setting stays in mysqld-auto.cnf.
+		if err := db.WithContext(context.Background()).Exec("RESET PERSIST IF EXISTS innodb_buffer_pool_load_at_startup").Error; err != nil {
+			slog.Warn("could not reset the persisted innodb_buffer_pool_load_at_startup; run RESET PERSIST innodb_buffer_pool_load_at_startup", "err", err)
+		}
+	}()
+	if err := cfg.Restart(ctx); err != nil {
+		return report, fmt.Errorf("restart: %w", err)
+	}
+	restartStart := time.Now()
+	if err := waitForServer(ctx, db, bufferPoolRestartTimeout); err != nil {
+		return report, err
+	}
+	report.Notes = append(report.Notes, fmt.Sprintf("重启后 %s 恢复连接。", time.Since(restartStart).Round(time.Second)))
+	if err := measure("重启后冷启动"); err != nil {
+		return report, err
+	}
+
+	// Restart again to drop the pages the cold run pulled in, then restore from the dump.
+	if err := cfg.Restart(ctx); err != nil {
+		return report, fmt.Errorf("restart: %w", err)
+	}
+	if err := waitForServer(ctx, db, bufferPoolRestartTimeout); err != nil {
+		return report, err
+	}
+	loadStart := time.Now()
+	if err := db.WithContext(ctx).Exec("SET GLOBAL innodb_buffer_pool_load_now = ON").Error; err != nil {
+		return report, fmt.Errorf("load buffer pool: %w", err)
+	}
+	if err := waitForStatus(ctx, db, "Innodb_buffer_pool_load_status", "completed"); err != nil {
+		return report, err
+	}
+	report.Notes = append(report.Notes, fmt.Sprintf("innodb_buffer_pool_load_now 耗时 %s（后台顺序读，期间查询仍可执行）。", time.Since(loadStart).Round(time.Millisecond)))
+	if err := measure("load_now 恢复后"); err != nil {
+		return report, err
+	}
+
+	report.Notes = append(report.Notes,
+		"每次关闭时 innodb_buffer_pool_dump_at_shutdown 都会重写 ib_buffer_pool；实验期间通过 SET PERSIST_ONLY 关闭启动加载，结束后 RESET PERSIST 恢复。")
+	return report, nil
+}
+
+// waitForStatus polls a global status string until it contains want (case-insensitive).
+func waitForStatus(ctx context.Context, db *gorm.DB, name, want string) error {
+	deadline := time.Now().Add(bufferPoolStatusTimeout)
+	for {
+		value, err := globalStatus(ctx, db, name)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(strings.ToLower(value), want) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s still %q after %s", name, value, bufferPoolStatusTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	fkBenchOrders        = 100000
+	fkBenchItemsPerOrder = 3
+	fkBenchBatch         = 500
+	// fkBenchDeleteShare of the orders (the lowest ids) and their items are deleted afterwards.
+	fkBenchDeleteShare = 10
+)
+
+// fkVariant is one schema layout: the same three tables, with or without enforced foreign keys.
+type fkVariant struct {
+	name string
+	// orderFK and itemFK are appended to the CREATE TABLE column lists; empty without constraints.
+	orderFK, itemFK string
+	// deleteQuery removes the oldest orders together with their items.
+	deleteQuery string
+}
+
+var fkVariants = []fkVariant{
+	{
+		name:        "无外键（应用保证一致性）",
+		deleteQuery: "DELETE o, i FROM fk_bench_orders o LEFT JOIN fk_bench_items i ON i.order_id = o.id WHERE o.id <= ?",
+	},
+	{
+		name:        "FOREIGN KEY + ON DELETE CASCADE",
+		orderFK:     ",\n\t\tCONSTRAINT fk_bench_orders_customer FOREIGN KEY (customer_id) REFERENCES fk_bench_customers (id)",
+		itemFK:      ",\n\t\tCONSTRAINT fk_bench_items_order FOREIGN KEY (order_id) REFERENCES fk_bench_orders (id) ON DELETE CASCADE",
+		deleteQuery: "DELETE FROM fk_bench_orders WHERE id <= ?",
+	},
+}
+
+func foreignKeyExperiment() Experiment {
+	return Experiment{
+		Name: "foreign-keys",
+		Description: fmt.Sprintf("customers → orders → order_items 三张表分别在无外键与强制外键下批量写入 %d 笔订单（每笔 %d 条明细），再删除最早的 1/%d 订单及其明细，对比耗时与加锁情况。",
+			fkBenchOrders, fkBenchItemsPerOrder, fkBenchDeleteShare),
+		Run: runForeignKeyExperiment,
+	}
+}
+
+func runForeignKeyExperiment(ctx context.Context, db *gorm.DB, cfg ExperimentConfig) (ExperimentReport, error) {
+	report := ExperimentReport{
+		Columns: []string{"约束", "订单写入", "明细写入", "一批订单加锁(行/锁结构)", "删除耗时", "删除加锁(行/锁结构)", "删除行数"},
+	}
+	if err := ensureCustomers(ctx, db); err != nil {
+		return report, err
+	}
+	defer dropFKBenchTables(context.Background(), db)
+
+	for _, v := range fkVariants {
+		if err := createFKBenchTables(ctx, db, v); err != nil {
+			return report, fmt.Errorf("%s: %w", v.name, err)
+		}
+		ordersTook, itemsTook, err := loadFKBench(ctx, db)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", v.name, err)
+		}
+		// One more batch of orders in a transaction that is rolled back, to see what it locks.
+		probeQuery, probeArgs := fkBenchOrderBatch(rand.New(rand.NewSource(2)), fkBenchBatch)
+		probe, err := execInTx(ctx, db, false, probeQuery, probeArgs...)
+		if err != nil {
+			return report, fmt.Errorf("%s: lock probe: %w", v.name, err)
+		}
+		start := time.Now()
+		deleted, err := execInTx(ctx, db, true, v.deleteQuery, fkBenchOrders/fkBenchDeleteShare)
+		if err != nil {
+			return report, fmt.Errorf("%s: delete: %w", v.name, err)
+		}
+		deleteTook := time.Since(start)
+		report.Rows = append(report.Rows, []string{
+			v.name,
+			ordersTook.Round(time.Millisecond).String(),
+			itemsTook.Round(time.Millisecond).String(),
+			fmt.Sprintf("%d / %d", probe.rowsLocked, probe.lockStructs),
+			deleteTook.Round(time.Millisecond).String(),
+			fmt.Sprintf("%d / %d", deleted.rowsLocked, deleted.lockStructs),
+			fmt.Sprintf("%d", deleted.affected),
+		})
+	}
+
+	report.Notes = append(report.Notes,
+		"写入子表时 InnoDB 要到父表主键上确认父行存在，并对父行加共享锁直到事务结束：并发事务这时无法删除或更新这些客户/订单，写入也多了一次父表索引查找。",
+		"ON DELETE CASCADE 的级联删除由 InnoDB 在同一事务内逐行完成，不会触发子表上的触发器；删除加锁包含级联到的每一条明细。",
+		"无外键时一致性由应用负责：这里用多表 DELETE 一次删除订单和明细，加锁范围相同，但写入不再锁父行。",
+		"实验表在结束后删除。")
+	return report, nil
+}
+
+func dropFKBenchTables(ctx context.Context, db *gorm.DB) error {
+	// Children first, so the constraints never block the drop.
+	return db.WithContext(ctx).Exec("DROP TABLE IF EXISTS fk_bench_items, fk_bench_orders, fk_bench_customers").Error
+}
+
+func createFKBenchTables(ctx context.Context, db *gorm.DB, v fkVariant) error {
+	if err := dropFKBenchTables(ctx, db); err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE fk_bench_customers (
+		id BIGINT UNSIGNED PRIMARY KEY,
+		name VARCHAR(64) NOT NULL
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+		"INSERT INTO fk_bench_customers (id, name) SELECT id, name FROM customers",
+		`CREATE TABLE fk_bench_orders (
+		id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+		customer_id BIGINT UNSIGNED NOT NULL,
+		total_amount DOUBLE NOT NULL,
+		created_at DATETIME NOT NULL,
+		INDEX idx_fk_bench_orders_customer_id (customer_id)` + v.orderFK + `
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+		`CREATE TABLE fk_bench_items (
+		id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+		order_id BIGINT UNSIGNED NOT NULL,
+		sku VARCHAR(32) NOT NULL,
+		quantity INT NOT NULL,
+		INDEX idx_fk_bench_items_order_id (order_id)` + v.itemFK + `
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+	} {
+		if err := db.WithContext(ctx).Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadFKBench writes the same orders, then the same items, for every variant. Order ids are
+// 1..fkBenchOrders since the table starts empty.
+func loadFKBench(ctx context.Context, db *gorm.DB) (orders, items time.Duration, err error) {
+	rnd := rand.New(rand.NewSource(1))
+	start := time.Now()
+	for offset := 0; offset < fkBenchOrders; offset += fkBenchBatch {
+		query, args := fkBenchOrderBatch(rnd, min(fkBenchBatch, fkBenchOrders-offset))
+		if err := db.WithContext(ctx).Exec(query, args...).Error; err != nil {
+			return 0, 0, fmt.Errorf("insert orders: %w", err)
+		}
+	}
+	orders = time.Since(start)
+
+	start = time.Now()
+	for first := 1; first <= fkBenchOrders; first += fkBenchBatch {
+		n := min(fkBenchBatch, fkBenchOrders-first+1)
+		args := make([]interface{}, 0, n*fkBenchItemsPerOrder*3)
+		for id := first; id < first+n; id++ {
+			for i := 0; i < fkBenchItemsPerOrder; i++ {
+				args = append(args, id, fmt.Sprintf("SKU-%05d", rnd.Intn(20000)), rnd.Intn(5)+1)
+			}
+		}
+		query := "INSERT INTO fk_bench_items (order_id, sku, quantity) VALUES " +
+			strings.TrimSuffix(strings.Repeat("(?, ?, ?),", n*fkBenchItemsPerOrder), ",")
+		if err := db.WithContext(ctx).Exec(query, args...).Error; err != nil {
+			return 0, 0, fmt.Errorf("insert items: %w", err)
+		}
+	}
+	return orders, time.Since(start), nil
+}
+
+func fkBenchOrderBatch(rnd *rand.Rand, n int) (string, []interface{}) {
+	args := make([]interface{}, 0, n*2)
+	for i := 0; i < n; i++ {
+		args = append(args, rnd.Intn(customerTarget)+1, float64(rnd.Intn(100000))/100)
+	}
+	return "INSERT INTO fk_bench_orders (customer_id, total_amount, created_at) VALUES " +
+		strings.TrimSuffix(strings.Repeat("(?, ?, NOW()),", n), ","), args
+}
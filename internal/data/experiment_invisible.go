@@ -0,0 +1,187 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func invisibleIndexExperiment() Experiment {
+	return Experiment{
+		Name:        "invisible-index",
+		Family:      "索引",
+		Description: "把 -target 指定的索引临时设为 INVISIBLE，重跑所有用到它的场景后恢复，评估删除该索引的影响。",
+		Run:         runInvisibleIndexExperiment,
+	}
+}
+
+func runInvisibleIndexExperiment(ctx context.Context, db *gorm.DB, cfg ExperimentConfig) (ExperimentReport, error) {
+	report := ExperimentReport{Columns: []string{"场景", "可见: 计划", "可见: 耗时", "不可见: 计划", "不可见: 耗时", "变化"}}
+
+	table, index, ok := strings.Cut(cfg.Target, ".")
+	if !ok || table == "" || index == "" {
+		return report, fmt.Errorf("invisible-index needs -target <table>.<index>, got %q", cfg.Target)
+	}
+	if strings.EqualFold(index, "PRIMARY") {
+		return report, fmt.Errorf("the primary key cannot be made invisible")
+	}
+	var visible []string
+	if err := db.WithContext(ctx).Raw(`SELECT DISTINCT IS_VISIBLE FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?`, table, index).
+		Scan(&visible).Error; err != nil {
+		return report, err
+	}
+	if len(visible) == 0 {
+		return report, fmt.Errorf("index %s not found on table %s", index, table)
+	}
+	if visible[0] != "YES" {
+		return report, fmt.Errorf("index %s.%s is already invisible", table, index)
+	}
+
+	scenarios := append(builtinScenarios(RunConfig{}), cfg.Scenarios...)
+	var affected []Scenario
+	for _, sc := range scenarios {
+		if sc.Query == "" || sc.Run != nil {
+			continue
+		}
+		if strings.Contains(sc.OptimizerSwitch, "use_invisible_indexes=on") {
+			// These scenarios see invisible indexes anyway; hiding the target would not change them.
+			continue
+		}
+		plan, err := scenarioPlan(ctx, db, sc)
+		if err != nil {
+			continue
+		}
+		if planUsesIndex(plan, index) {
+			affected = append(affected, sc)
+		}
+	}
+	if len(affected) == 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("没有场景的执行计划用到 %s.%s（依赖 Setup 建表的场景需先完整运行一次才会被检查）。", table, index))
+		return report, nil
+	}
+
+	runCfg := RunConfig{}
+	if version, err := DetectServerVersion(ctx, db); err == nil {
+		runCfg.ServerVersion = version
+	}
+	before := make([]ScenarioResult, len(affected))
+	beforePlans := make([]string, len(affected))
+	for i, sc := range affected {
+		before[i] = runScenario(ctx, db, sc, runCfg)
+		beforePlans[i] = describeScenarioPlan(ctx, db, sc)
+	}
+
+	alter := fmt.Sprintf("ALTER TABLE `%s` ALTER INDEX `%s` %%s", table, index)
+	if err := db.WithContext(ctx).Exec(fmt.Sprintf(alter, "INVISIBLE")).Error; err != nil {
+		return report, fmt.Errorf("hide index: %w", err)
+	}
+	restored := false
+	restore := func() error {
+		if restored {
+			return nil
+		}
+		restored = true
+		// Restore even when the experiment context was cancelled.
+		return db.WithContext(context.Background()).Exec(fmt.Sprintf(alter, "VISIBLE")).Error
+	}
+	defer restore()
+
+	for i, sc := range affected {
+		after := runScenario(ctx, db, sc, runCfg)
+		afterPlan := describeScenarioPlan(ctx, db, sc)
+		report.Rows = append(report.Rows, []string{
+			sc.Name,
+			beforePlans[i], formatScenarioDuration(before[i]),
+			afterPlan, formatScenarioDuration(after),
+			durationRatio(before[i], after),
+		})
+	}
+
+	if err := restore(); err != nil {
+		return report, fmt.Errorf("restore index visibility: %w", err)
+	}
+	report.Notes = append(report.Notes,
+		fmt.Sprintf("%s.%s 仅在重跑期间不可见，现已恢复 VISIBLE。", table, index),
+		"INVISIBLE 只影响优化器，索引仍随写入维护；确认无回退后再 DROP 才能真正省下写入与空间成本。",
+		"开启 use_invisible_indexes 的场景不受影响，已排除。")
+	return report, nil
+}
+
+// scenarioPlan explains the scenario with its optimizer_switch applied, the same way runScenario executes it.
+func scenarioPlan(ctx context.Context, db *gorm.DB, sc Scenario) ([]PlanRow, error) {
+	var plan []PlanRow
+	err := db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if sc.OptimizerSwitch != "" {
+			if err := conn.Exec("SET SESSION optimizer_switch = ?", sc.OptimizerSwitch).Error; err != nil {
+				return err
+			}
+			defer conn.Exec("SET SESSION optimizer_switch = DEFAULT")
+		}
+		var err error
+		plan, err = ExplainPlan(ctx, conn, sc.SQL(), sc.Args...)
+		return err
+	})
+	return plan, err
+}
+
+// planUsesIndex matches by index name only, since plan rows show table aliases.
+func planUsesIndex(plan []PlanRow, index string) bool {
+	for _, row := range plan {
+		// Index merge lists several keys separated by commas.
+		for _, key := range strings.Split(row.Key, ",") {
+			if key == index {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// describeScenarioPlan renders the access path of every plan row as "type/key".
+func describeScenarioPlan(ctx context.Context, db *gorm.DB, sc Scenario) string {
+	plan, err := scenarioPlan(ctx, db, sc)
+	if err != nil {
+		return "ERR"
+	}
+	var parts []string
+	for _, row := range plan {
+		key := row.Key
+		if key == "" {
+			key = "NULL"
+		}
+		parts = append(parts, fmt.Sprintf("%s/%s", row.Type, key))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+// measured reports whether the scenario query itself ran; explain and expectation errors still leave a timing.
+func measured(res ScenarioResult) bool {
+	switch ErrorKind(res.Err) {
+	case "skipped", "setup", "execution":
+		return false
+	}
+	return true
+}
+
+func formatScenarioDuration(res ScenarioResult) string {
+	if res.Skipped() {
+		return "SKIP"
+	}
+	if !measured(res) {
+		return "ERR"
+	}
+	return res.Duration.String()
+}
+
+func durationRatio(before, after ScenarioResult) string {
+	if !measured(before) || !measured(after) || before.Duration <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("x%.1f", float64(after.Duration)/float64(before.Duration))
+}
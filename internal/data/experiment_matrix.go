@@ -0,0 +1,109 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const matrixRestartWait = 2 * time.Minute
+
+// runConfigMatrix applies each settings combination through cfg.ApplyServerConfig (restarting
+// the server), runs measure, and returns one report row per combination prefixed by its settings.
+// The override is removed afterwards so the server returns to its baseline configuration.
+func runConfigMatrix(ctx context.Context, db *gorm.DB, cfg ExperimentConfig, matrix []map[string]string,
+	measure func(context.Context, *gorm.DB) ([]string, error)) ([][]string, error) {
+	if cfg.ApplyServerConfig == nil {
+		return nil, errors.New("config matrix needs a server config hook (docker compose)")
+	}
+	defer func() {
+		cfg.ApplyServerConfig(context.Background(), nil)
+		waitForServer(context.Background(), db, matrixRestartWait)
+	}()
+
+	var rows [][]string
+	for _, settings := range matrix {
+		if err := cfg.ApplyServerConfig(ctx, settings); err != nil {
+			return rows, fmt.Errorf("apply %s: %w", formatSettings(settings), err)
+		}
+		if err := waitForServer(ctx, db, matrixRestartWait); err != nil {
+			return rows, err
+		}
+		cells, err := measure(ctx, db)
+		if err != nil {
+			return rows, fmt.Errorf("%s: %w", formatSettings(settings), err)
+		}
+		rows = append(rows, append([]string{formatSettings(settings)}, cells...))
+	}
+	return rows, nil
+}
+
+func formatSettings(settings map[string]string) string {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+settings[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+// ensureScratchTable (re)creates an empty wide-row table used by write-heavy experiments.
+func ensureScratchTable(ctx context.Context, db *gorm.DB, table string) error {
+	if err := db.WithContext(ctx).Exec("DROP TABLE IF EXISTS " + table).Error; err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Exec(fmt.Sprintf(`CREATE TABLE %s (
+		id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+		payload CHAR(255) NOT NULL,
+		created_at DATETIME NOT NULL
+	) ENGINE=InnoDB`, table)).Error
+}
+
+// bulkInsertScratch writes rows into a scratch table using workers concurrent multi-row INSERTs.
+func bulkInsertScratch(ctx context.Context, db *gorm.DB, table string, rows, workers, batch int) (time.Duration, error) {
+	payload := strings.Repeat("r", 255)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	jobs := make(chan int)
+	start := time.Now()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				placeholders := strings.TrimSuffix(strings.Repeat("(?, NOW()),", n), ",")
+				args := make([]interface{}, n)
+				for i := range args {
+					args[i] = payload
+				}
+				stmt := fmt.Sprintf("INSERT INTO %s (payload, created_at) VALUES %s", table, placeholders)
+				if err := db.WithContext(ctx).Exec(stmt, args...).Error; err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for remaining := rows; remaining > 0; remaining -= batch {
+		jobs <- min(batch, remaining)
+	}
+	close(jobs)
+	wg.Wait()
+	return time.Since(start), firstErr
+}
@@ -0,0 +1,195 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	// errTooManyConnections is ER_CON_COUNT_ERROR.
+	errTooManyConnections = 1040
+	// maxConnSaturationLimit keeps the demo away from servers configured for thousands of connections.
+	maxConnSaturationLimit = 1000
+	maxConnBusyFor         = 3 * time.Second
+	maxConnSampleEvery     = 250 * time.Millisecond
+)
+
+func maxConnectionsExperiment() Experiment {
+	return Experiment{
+		Name:        "max-connections",
+		Description: "开连接直到服务器拒绝（max_connections），让它们全部执行 SLEEP，再观察额外的连接请求如何立即失败（ERROR 1040）、连接池里额外的查询如何排队，同时按时间线记录 Threads_connected / Threads_running。",
+		Run:         runMaxConnectionsExperiment,
+	}
+}
+
+// connTimeline samples Threads_connected/Threads_running on a connection opened before the
+// server fills up, labelling each sample with the current phase.
+type connTimeline struct {
+	mu    sync.Mutex
+	start time.Time
+	phase string
+	rows  [][]string
+}
+
+func (t *connTimeline) setPhase(phase string) {
+	t.mu.Lock()
+	t.phase = phase
+	t.mu.Unlock()
+}
+
+func (t *connTimeline) add(connected, running, event string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows = append(t.rows, []string{fmt.Sprintf("+%dms", time.Since(t.start).Milliseconds()), t.phase, connected, running, event})
+}
+
+func (t *connTimeline) sample(ctx context.Context, mon *sql.Conn, event string) {
+	var connected, running string
+	err := mon.QueryRowContext(ctx, `SELECT
+		MAX(IF(VARIABLE_NAME = 'Threads_connected', VARIABLE_VALUE, NULL)),
+		MAX(IF(VARIABLE_NAME = 'Threads_running', VARIABLE_VALUE, NULL))
+		FROM performance_schema.global_status WHERE VARIABLE_NAME IN ('Threads_connected', 'Threads_running')`).
+		Scan(&connected, &running)
+	if err != nil {
+		connected, running = "?", "?"
+	}
+	t.add(connected, running, event)
+}
+
+func runMaxConnectionsExperiment(ctx context.Context, db *gorm.DB, cfg ExperimentConfig) (ExperimentReport, error) {
+	report := ExperimentReport{
+		Columns: []string{"时刻", "阶段", "Threads_connected", "Threads_running", "事件"},
+	}
+	pool, err := db.DB()
+	if err != nil {
+		return report, err
+	}
+	// The monitor connection is taken before the server fills up; without CONNECTION_ADMIN no new one could be opened later.
+	mon, err := pool.Conn(ctx)
+	if err != nil {
+		return report, err
+	}
+	defer mon.Close()
+
+	var maxConnections, connected int
+	if err := mon.QueryRowContext(ctx, "SELECT @@max_connections").Scan(&maxConnections); err != nil {
+		return report, err
+	}
+	if err := mon.QueryRowContext(ctx, "SELECT VARIABLE_VALUE FROM performance_schema.global_status WHERE VARIABLE_NAME = 'Threads_connected'").Scan(&connected); err != nil {
+		return report, err
+	}
+	if maxConnections > maxConnSaturationLimit {
+		return report, fmt.Errorf("max_connections is %d; this demo only saturates servers with at most %d", maxConnections, maxConnSaturationLimit)
+	}
+
+	sat, err := openVariant(db, func(*mysql.Config) {})
+	if err != nil {
+		return report, err
+	}
+	defer sat.Close()
+
+	timeline := &connTimeline{start: time.Now(), phase: "开始"}
+	timeline.sample(ctx, mon, fmt.Sprintf("max_connections=%d", maxConnections))
+	stopSampling := make(chan struct{})
+	var samplerDone sync.WaitGroup
+	samplerDone.Add(1)
+	go func() {
+		defer samplerDone.Done()
+		ticker := time.NewTicker(maxConnSampleEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopSampling:
+				return
+			case <-ticker.C:
+				timeline.sample(ctx, mon, "")
+			}
+		}
+	}()
+	var stopOnce sync.Once
+	stop := func() {
+		stopOnce.Do(func() {
+			close(stopSampling)
+			samplerDone.Wait()
+		})
+	}
+	defer stop()
+
+	// Phase 1: open connections until the server refuses. Slack covers clients that come and go meanwhile.
+	timeline.setPhase("打满连接")
+	var conns []*sql.Conn
+	defer func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}()
+	var refused error
+	for i := 0; i < maxConnections-connected+5; i++ {
+		c, err := sat.Conn(ctx)
+		if err != nil {
+			refused = err
+			break
+		}
+		conns = append(conns, c)
+	}
+	var myErr *mysql.MySQLError
+	if !errors.As(refused, &myErr) || myErr.Number != errTooManyConnections {
+		return report, fmt.Errorf("expected ERROR %d after %d connections, got %v", errTooManyConnections, len(conns), refused)
+	}
+	timeline.sample(ctx, mon, fmt.Sprintf("opened %d connections, next one refused: %v", len(conns), refused))
+
+	// Phase 2: keep every connection busy.
+	timeline.setPhase("全部忙碌")
+	sat.SetMaxOpenConns(len(conns))
+	var busy sync.WaitGroup
+	for _, c := range conns {
+		busy.Add(1)
+		go func() {
+			defer busy.Done()
+			c.ExecContext(ctx, "DO SLEEP(?)", maxConnBusyFor.Seconds())
+			c.Close()
+		}()
+	}
+	time.Sleep(maxConnSampleEvery)
+
+	// Phase 3: one more client fails outright, one more query through the full pool queues.
+	timeline.setPhase("额外请求")
+	extra, err := openVariant(db, func(*mysql.Config) {})
+	if err != nil {
+		return report, err
+	}
+	start := time.Now()
+	err = extra.PingContext(ctx)
+	extra.Close()
+	timeline.sample(ctx, mon, fmt.Sprintf("new client connection failed after %s: %v", time.Since(start).Round(time.Millisecond), err))
+
+	start = time.Now()
+	var one int
+	if err := sat.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return report, fmt.Errorf("queued query: %w", err)
+	}
+	timeline.sample(ctx, mon, fmt.Sprintf("query queued in the client pool for %s until a connection came free", time.Since(start).Round(time.Millisecond)))
+
+	busy.Wait()
+	conns = nil
+	sat.Close()
+	timeline.setPhase("释放")
+	time.Sleep(2 * maxConnSampleEvery)
+	timeline.sample(ctx, mon, "all saturation connections closed")
+	stop()
+
+	report.Rows = timeline.rows
+	report.Notes = append(report.Notes,
+		"服务器连接数满后，新连接在握手阶段就被拒绝（ERROR 1040 Too many connections），失败很快，但对应用来说就是请求直接报错。",
+		"连接池上限打满时，多出来的查询不会报错，而是在客户端排队等待空闲连接：服务端慢查询日志里完全看不到这段等待，只能从应用侧延迟或 Threads_connected 贴着上限发现。",
+		"Threads_running 才是真正在执行的线程数；连接多但 Threads_running 低说明连接大多空闲，可以缩小连接池，反之要找出占着连接不放的慢语句。",
+		"max_connections 会为具备 CONNECTION_ADMIN 权限的账号多保留一个连接，便于管理员在打满时登录排查。")
+	return report, nil
+}
@@ -0,0 +1,269 @@
+package data
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultExportTables are the tables the seeded dataset lives in; scenario tables are rebuilt
+// by their setup hooks and are not worth shipping.
+var DefaultExportTables = []string{"orders", "customers", "seed_state"}
+
+// exportInsertRows is how many rows each INSERT of a SQL export carries.
+const exportInsertRows = 500
+
+// exportColumn is a stored (non-generated) column of an exported table.
+type exportColumn struct {
+	Name     string
+	DataType string
+}
+
+// ExportSQL writes a self-contained SQL script recreating tables and their rows: DROP TABLE,
+// SHOW CREATE TABLE output and multi-row INSERTs. Generated columns are left to MySQL. All
+// tables are read in one REPEATABLE READ snapshot, so the dump is consistent.
+func ExportSQL(ctx context.Context, db *gorm.DB, w io.Writer, tables []string) error {
+	bw := bufio.NewWriterSize(w, 1<<20)
+	fmt.Fprintf(bw, "-- slowlab dataset export, %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintln(bw, "SET NAMES utf8mb4;\nSET FOREIGN_KEY_CHECKS = 0;\nSET UNIQUE_CHECKS = 0;")
+	err := inSnapshot(ctx, db, func(tx *gorm.DB) error {
+		for _, table := range tables {
+			if err := exportTableSQL(tx, bw, table); err != nil {
+				return fmt.Errorf("export %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(bw, "SET UNIQUE_CHECKS = 1;\nSET FOREIGN_KEY_CHECKS = 1;")
+	return bw.Flush()
+}
+
+// ExportCSV writes schema.sql (DDL only) and one <table>.csv with a header row per table into
+// dir; orders.csv and customers.csv load back with ImportCSV. NULL is written as an empty field.
+func ExportCSV(ctx context.Context, db *gorm.DB, dir string, tables []string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return inSnapshot(ctx, db, func(tx *gorm.DB) error {
+		schema, err := os.Create(filepath.Join(dir, "schema.sql"))
+		if err != nil {
+			return err
+		}
+		defer schema.Close()
+		for _, table := range tables {
+			ddl, err := showCreateTable(tx, table)
+			if err != nil {
+				return fmt.Errorf("export %s: %w", table, err)
+			}
+			fmt.Fprintf(schema, "DROP TABLE IF EXISTS `%s`;\n%s;\n\n", table, ddl)
+			if err := exportTableCSV(tx, filepath.Join(dir, table+".csv"), table); err != nil {
+				return fmt.Errorf("export %s: %w", table, err)
+			}
+		}
+		return schema.Close()
+	})
+}
+
+// inSnapshot runs fn in a read-only REPEATABLE READ transaction.
+func inSnapshot(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	tx := db.WithContext(ctx).Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer tx.Rollback()
+	return fn(tx)
+}
+
+func showCreateTable(tx *gorm.DB, table string) (string, error) {
+	var name, ddl string
+	if err := tx.Raw("SHOW CREATE TABLE `"+table+"`").Row().Scan(&name, &ddl); err != nil {
+		return "", err
+	}
+	return ddl, nil
+}
+
+func storedColumns(tx *gorm.DB, table string) ([]exportColumn, error) {
+	var columns []exportColumn
+	err := tx.Raw(`SELECT COLUMN_NAME AS name, DATA_TYPE AS data_type FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND EXTRA NOT LIKE '%GENERATED%'
+		ORDER BY ORDINAL_POSITION`, table).Scan(&columns).Error
+	if err == nil && len(columns) == 0 {
+		err = fmt.Errorf("table %s not found", table)
+	}
+	return columns, err
+}
+
+// scanTable streams every row of table's stored columns to fn.
+func scanTable(tx *gorm.DB, table string, columns []exportColumn, fn func(values []interface{}) error) error {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = "`" + c.Name + "`"
+	}
+	rows, err := tx.Raw(fmt.Sprintf("SELECT %s FROM `%s`", strings.Join(names, ", "), table)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func exportTableSQL(tx *gorm.DB, w io.Writer, table string) error {
+	ddl, err := showCreateTable(tx, table)
+	if err != nil {
+		return err
+	}
+	columns, err := storedColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nDROP TABLE IF EXISTS `%s`;\n%s;\n", table, ddl)
+
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = "`" + c.Name + "`"
+	}
+	insert := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES\n", table, strings.Join(names, ", "))
+	inBatch := 0
+	literals := make([]string, len(columns))
+	err = scanTable(tx, table, columns, func(values []interface{}) error {
+		for i, v := range values {
+			literals[i] = sqlLiteral(v, columns[i].DataType)
+		}
+		sep := ",\n"
+		if inBatch == 0 {
+			sep = insert
+		}
+		inBatch++
+		_, err := fmt.Fprintf(w, "%s(%s)", sep, strings.Join(literals, ", "))
+		if inBatch == exportInsertRows {
+			fmt.Fprint(w, ";\n")
+			inBatch = 0
+		}
+		return err
+	})
+	if inBatch > 0 {
+		fmt.Fprint(w, ";\n")
+	}
+	return err
+}
+
+func exportTableCSV(tx *gorm.DB, path, table string) error {
+	columns, err := storedColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cw := csv.NewWriter(bufio.NewWriterSize(f, 1<<20))
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		record[i] = c.Name
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+	err = scanTable(tx, table, columns, func(values []interface{}) error {
+		for i, v := range values {
+			record[i] = csvField(v, columns[i].DataType)
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// numericTypes are the DATA_TYPE values the text protocol returns as digits safe to write unquoted.
+var numericTypes = map[string]bool{
+	"tinyint": true, "smallint": true, "mediumint": true, "int": true, "bigint": true,
+	"decimal": true, "float": true, "double": true,
+}
+
+// binaryTypes are the DATA_TYPE values exported as hex literals rather than quoted strings.
+var binaryTypes = map[string]bool{
+	"binary": true, "varbinary": true, "tinyblob": true, "blob": true, "mediumblob": true, "longblob": true,
+	"geometry": true, "point": true, "linestring": true, "polygon": true,
+	"multipoint": true, "multilinestring": true, "multipolygon": true, "geometrycollection": true,
+}
+
+func sqlLiteral(v interface{}, dataType string) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return "'" + v.Format(exportTimeLayout) + "'"
+	case []byte:
+		switch {
+		case binaryTypes[dataType]:
+			return "X'" + hex.EncodeToString(v) + "'"
+		case numericTypes[dataType]:
+			return string(v)
+		}
+		return quoteSQLString(string(v))
+	default:
+		return quoteSQLString(fmt.Sprint(v))
+	}
+}
+
+func csvField(v interface{}, dataType string) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(exportTimeLayout)
+	case []byte:
+		if binaryTypes[dataType] {
+			return hex.EncodeToString(v)
+		}
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+const exportTimeLayout = "2006-01-02 15:04:05.999999"
+
+var sqlStringEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`)
+
+func quoteSQLString(s string) string {
+	return "'" + sqlStringEscaper.Replace(s) + "'"
+}
@@ -0,0 +1,98 @@
+package data
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// orderStyle picks the free-text fields of generated orders: the terse synthetic values the
+// scenarios are written against by default, or plausible names, notes and discount codes
+// when realistic (SeedConfig.Realistic) for demos and screenshots.
+type orderStyle struct {
+	realistic bool
+}
+
+// customerName returns the name of customer id; realistic names are fixed per id, so orders
+// and the customers table agree.
+func (s orderStyle) customerName(id uint) string {
+	if !s.realistic {
+		return customerName(id)
+	}
+	h := uint64(id) * 0x9E3779B97F4A7C15
+	return fakeFirstNames[h%uint64(len(fakeFirstNames))] + " " + fakeLastNames[(h>>32)%uint64(len(fakeLastNames))]
+}
+
+func (s orderStyle) note(rnd *rand.Rand) string {
+	if !s.realistic {
+		return randomChoice(loremSamples, rnd)
+	}
+	note := fakeNotes[rnd.Intn(len(fakeNotes))]
+	if rnd.Intn(3) == 0 {
+		note += " Ship to " + fakeAddress(rnd) + "."
+	}
+	return note
+}
+
+func (s orderStyle) discountCode(rnd *rand.Rand) string {
+	if !s.realistic {
+		return discountCode(rnd)
+	}
+	// Most real orders carry no code at all.
+	if rnd.Intn(10) < 7 {
+		return ""
+	}
+	return fakeDiscountCodes[rnd.Intn(len(fakeDiscountCodes))]
+}
+
+func fakeAddress(rnd *rand.Rand) string {
+	return fmt.Sprintf("%d %s, %s", rnd.Intn(9800)+1, fakeStreets[rnd.Intn(len(fakeStreets))], fakeCities[rnd.Intn(len(fakeCities))])
+}
+
+var (
+	fakeFirstNames = []string{
+		"Olivia", "Liam", "Emma", "Noah", "Ava", "Oliver", "Sophia", "Elijah", "Isabella", "Lucas",
+		"Mia", "Mateo", "Amelia", "Levi", "Harper", "Ethan", "Evelyn", "James", "Aria", "Benjamin",
+		"Chloe", "Daniel", "Priya", "Wei", "Yuki", "Carlos", "Fatima", "Mohammed", "Ana", "Ivan",
+		"Zara", "Kenji", "Lucia", "Omar", "Nina", "Hiro", "Sara", "Diego", "Leila", "Tomas",
+	}
+	fakeLastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+		"Lee", "Chen", "Wang", "Zhang", "Liu", "Kim", "Park", "Tanaka", "Sato", "Nguyen",
+		"Patel", "Singh", "Khan", "Ali", "Silva", "Santos", "Rossi", "Müller", "Schmidt", "Novak",
+	}
+	fakeStreets = []string{
+		"Maple Ave", "Oak St", "Pine Rd", "Cedar Ln", "Elm St", "Washington Blvd", "Lakeview Dr", "Hillcrest Rd",
+		"Sunset Blvd", "River Rd", "Park Ave", "Highland Ave", "Church St", "Mill Rd", "Meadow Ln", "Harbor Way",
+	}
+	fakeCities = []string{
+		"Springfield", "Riverside", "Fairview", "Madison", "Georgetown", "Franklin", "Clinton", "Salem",
+		"Ashland", "Bristol", "Dover", "Kingston", "Oxford", "Lexington", "Milton", "Newport",
+	}
+	fakeNotes = []string{
+		"Please leave the package with the front desk.",
+		"Gift order - do not include the invoice.",
+		"Call before delivery, the buzzer is broken.",
+		"Customer asked to combine with their previous order.",
+		"Deliver after 6pm on weekdays.",
+		"Fragile: double box the glassware.",
+		"Requested eco-friendly packaging.",
+		"Address updated by customer support.",
+		"Second attempt, first delivery failed.",
+		"Corporate purchase, PO number to follow.",
+		"Birthday present, add a greeting card.",
+		"Leave at the back door if nobody answers.",
+		"Express shipping upgrade applied.",
+		"Customer reported a damaged item last time; inspect before shipping.",
+		"Split shipment allowed.",
+		"Hold for pickup at the local store.",
+		"Waiting on size confirmation from the customer.",
+		"Loyalty member, include free sample.",
+		"",
+		"",
+	}
+	fakeDiscountCodes = []string{
+		"WELCOME10", "SPRING15", "SUMMER20", "FALLSALE", "BLACKFRIDAY", "CYBER25", "FREESHIP",
+		"VIP30", "STUDENT10", "BDAY15", "NEWYEAR", "FLASH40", "LOYALTY5", "APPONLY12",
+	}
+)
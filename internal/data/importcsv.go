@@ -0,0 +1,186 @@
+package data
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// importableColumns are the columns ImportCSV may write per table. Generated columns are
+// left out on purpose: MySQL computes them and rejects explicit values.
+var importableColumns = map[string][]string{
+	"orders":    append([]string{"id"}, loadDataColumns...),
+	"customers": {"id", "name", "region", "signup_date"},
+}
+
+// ImportConfig describes one CSV file to load into a lab table.
+type ImportConfig struct {
+	// Table is "orders" or "customers".
+	Table string
+	// Mapping renames CSV header columns to table columns; a CSV column mapped to "" or "-"
+	// is skipped. Unmapped headers are used as they are when they name an importable column.
+	Mapping map[string]string
+	// Replace empties the table before loading; for orders it also resets the seed checkpoint.
+	Replace bool
+	// BatchSize is how many rows each INSERT carries (1000 when zero).
+	BatchSize int
+	// Mask, when set, masks personal data before it reaches the table.
+	Mask *Masker
+}
+
+// ImportTables lists the tables ImportCSV accepts.
+func ImportTables() []string {
+	tables := make([]string, 0, len(importableColumns))
+	for t := range importableColumns {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// ParseColumnMapping parses "csv_col=table_col,other=-" into an ImportConfig.Mapping.
+func ParseColumnMapping(spec string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("column mapping %q: want csv_column=table_column", pair)
+		}
+		mapping[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+	return mapping, nil
+}
+
+// ImportCSV loads r, a CSV file with a header row, into cfg.Table and returns the rows written.
+// Empty fields become NULL. It reports the CSV columns it ignored so a typo in the mapping
+// does not silently drop data.
+func ImportCSV(ctx context.Context, db *gorm.DB, r io.Reader, cfg ImportConfig) (int64, []string, error) {
+	allowed, ok := importableColumns[cfg.Table]
+	if !ok {
+		return 0, nil, fmt.Errorf("cannot import into %q (want one of %s)", cfg.Table, strings.Join(ImportTables(), ", "))
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return 0, nil, fmt.Errorf("read CSV header: %w", err)
+	}
+
+	// fields[i] is the CSV field feeding columns[i].
+	var (
+		columns []string
+		fields  []int
+		ignored []string
+		seen    = make(map[string]string)
+	)
+	header = slices.Clone(header)
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		header[i] = name
+		target := name
+		if mapped, ok := cfg.Mapping[name]; ok {
+			target = mapped
+		}
+		if target == "" || target == "-" {
+			continue
+		}
+		if !slices.Contains(allowed, target) {
+			ignored = append(ignored, name)
+			continue
+		}
+		if prev, dup := seen[target]; dup {
+			return 0, nil, fmt.Errorf("CSV columns %q and %q both map to %s.%s", prev, name, cfg.Table, target)
+		}
+		seen[target] = name
+		columns = append(columns, target)
+		fields = append(fields, i)
+	}
+	for from := range cfg.Mapping {
+		if !slices.Contains(header, from) {
+			return 0, nil, fmt.Errorf("mapped column %q is not in the CSV header", from)
+		}
+	}
+	if len(columns) == 0 {
+		return 0, ignored, fmt.Errorf("no CSV column maps to a column of %s (importable: %s)", cfg.Table, strings.Join(allowed, ", "))
+	}
+	if cfg.Mask != nil {
+		for _, c := range cfg.Mask.Columns() {
+			if !slices.Contains(allowed, c) {
+				return 0, ignored, fmt.Errorf("mask rule for %s: not a column of %s", c, cfg.Table)
+			}
+		}
+	}
+
+	if cfg.Replace {
+		if err := db.WithContext(ctx).Exec("TRUNCATE TABLE " + cfg.Table).Error; err != nil {
+			return 0, ignored, err
+		}
+		if cfg.Table == "orders" {
+			if err := db.WithContext(ctx).Where("name = ?", seedOrdersState).Delete(&SeedState{}).Error; err != nil {
+				return 0, ignored, err
+			}
+		}
+	}
+
+	rowExpr := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	insert := func(args []interface{}, rows int) error {
+		stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", cfg.Table, strings.Join(columns, ", "),
+			strings.TrimSuffix(strings.Repeat(rowExpr+", ", rows), ", "))
+		return db.WithContext(ctx).Exec(stmt, args...).Error
+	}
+	var (
+		written int64
+		args    = make([]interface{}, 0, cfg.BatchSize*len(columns))
+		line    = 1
+	)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return written, ignored, err
+		}
+		for i, f := range fields {
+			value := record[f]
+			if value == "" {
+				args = append(args, nil)
+				continue
+			}
+			if cfg.Mask != nil {
+				if value, err = cfg.Mask.mask(columns[i], value); err != nil {
+					return written, ignored, fmt.Errorf("line %d: %w", line, err)
+				}
+			}
+			args = append(args, value)
+		}
+		if len(args) == cap(args) {
+			if err := insert(args, cfg.BatchSize); err != nil {
+				return written, ignored, fmt.Errorf("rows ending at line %d: %w", line, err)
+			}
+			written += int64(cfg.BatchSize)
+			args = args[:0]
+		}
+	}
+	if rows := len(args) / len(columns); rows > 0 {
+		if err := insert(args, rows); err != nil {
+			return written, ignored, fmt.Errorf("rows ending at line %d: %w", line, err)
+		}
+		written += int64(rows)
+	}
+	return written, ignored, nil
+}
@@ -0,0 +1,88 @@
+package data
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// IndexUsage is how often one index of orders was used during a scenario run.
+type IndexUsage struct {
+	Index   string `gorm:"column:index_name"`
+	Columns string `gorm:"column:key_columns"`
+	Unique  bool   `gorm:"column:is_unique"`
+	// Uses counts the row operations performance_schema attributed to the index during the
+	// run: lookups and scans, including those of UPDATE and DELETE.
+	Uses int64
+	// ServerUnused is true when sys.schema_unused_indexes lists the index, i.e. nothing has
+	// used it since the server started; ServerKnown is false when the view was not readable.
+	ServerUnused bool
+	ServerKnown  bool
+}
+
+// IndexUsageSnapshot holds per-index counters of orders taken before a run.
+type IndexUsageSnapshot map[string]int64
+
+// TakeIndexUsageSnapshot reads the per-index IO counters of orders from
+// performance_schema.table_io_waits_summary_by_index_usage.
+func TakeIndexUsageSnapshot(ctx context.Context, db *gorm.DB) (IndexUsageSnapshot, error) {
+	var rows []struct {
+		IndexName string
+		CountStar int64
+	}
+	err := db.WithContext(ctx).Raw(`SELECT INDEX_NAME AS index_name, COUNT_STAR AS count_star
+		FROM performance_schema.table_io_waits_summary_by_index_usage
+		WHERE OBJECT_SCHEMA = DATABASE() AND OBJECT_NAME = 'orders' AND INDEX_NAME IS NOT NULL`).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(IndexUsageSnapshot, len(rows))
+	for _, r := range rows {
+		snapshot[r.IndexName] = r.CountStar
+	}
+	return snapshot, nil
+}
+
+// IndexUsageSince lists every index of orders with its uses since before was taken, unused
+// indexes first. Indexes that scenario setup created during the run count from zero, and
+// counters reset by DDL on orders count from the reset.
+func IndexUsageSince(ctx context.Context, db *gorm.DB, before IndexUsageSnapshot) ([]IndexUsage, error) {
+	after, err := TakeIndexUsageSnapshot(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var usage []IndexUsage
+	err = db.WithContext(ctx).Raw(`SELECT INDEX_NAME AS index_name, MAX(NON_UNIQUE) = 0 AS is_unique,
+		GROUP_CONCAT(COALESCE(COLUMN_NAME, CONCAT('(', EXPRESSION, ')')) ORDER BY SEQ_IN_INDEX SEPARATOR ', ') AS key_columns
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'orders'
+		GROUP BY INDEX_NAME ORDER BY INDEX_NAME`).Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var serverUnused []string
+	serverErr := db.WithContext(ctx).Raw(`SELECT index_name FROM sys.schema_unused_indexes
+		WHERE object_schema = DATABASE() AND object_name = 'orders'`).Scan(&serverUnused).Error
+	for i := range usage {
+		u := &usage[i]
+		u.Uses = after[u.Index] - before[u.Index]
+		if u.Uses < 0 {
+			u.Uses = after[u.Index]
+		}
+		if serverErr == nil {
+			u.ServerKnown = true
+			for _, name := range serverUnused {
+				u.ServerUnused = u.ServerUnused || name == u.Index
+			}
+		}
+	}
+	// Unused indexes lead; used ones follow from the least used up.
+	slices.SortStableFunc(usage, func(a, b IndexUsage) int {
+		return cmp.Compare(a.Uses, b.Uses)
+	})
+	return usage, nil
+}
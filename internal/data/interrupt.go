@@ -0,0 +1,56 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInterrupted is the execution error of the scenario that was running when the run's
+// context was cancelled (SIGINT or SIGTERM); no further scenarios are started.
+var ErrInterrupted = errors.New("interrupted")
+
+// killTimeout bounds the KILL QUERY sent for a cancelled scenario query.
+const killTimeout = 5 * time.Second
+
+// markInterrupted reports whether ctx was cancelled by the time res finished. A failure is then
+// replaced with ErrInterrupted, as whatever failed did so because of the cancellation.
+func markInterrupted(ctx context.Context, res *ScenarioResult) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	if res.Err != nil && ErrorKind(res.Err) != "skipped" {
+		res.Err = &ExecutionError{Err: ErrInterrupted}
+	}
+	return true
+}
+
+// killOnCancel stops the statement running on server connection connID once ctx is cancelled.
+// The driver only drops its end of the connection, and the server does not notice until it
+// next sends rows, so a full scan would otherwise run to completion. The returned func ends
+// the watch and must be called once the statement is done.
+func killOnCancel(ctx context.Context, db *gorm.DB, connID uint64) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			killCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), killTimeout)
+			defer cancel()
+			// Best effort: the statement may have finished, or the account may lack the privilege
+			// to kill it (only its own connections need none).
+			db.WithContext(killCtx).Exec(fmt.Sprintf("KILL QUERY %d", connID))
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
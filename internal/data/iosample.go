@@ -0,0 +1,66 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ioStatusNames are the global InnoDB counters sampled while a scenario query runs.
+var ioStatusNames = []string{"Innodb_data_reads", "Innodb_data_writes", "Innodb_rows_read", "Innodb_data_fsyncs", "Innodb_os_log_fsyncs"}
+
+// IOSample is the change of the InnoDB IO counters over one sampling interval.
+// The counters are server-wide, so concurrent activity from other sessions is included.
+type IOSample struct {
+	// Elapsed is the end of the interval, measured from the start of the query.
+	Elapsed  time.Duration
+	Interval time.Duration
+	Counters []CounterDelta
+}
+
+// sampleIO polls the global counters every interval on its own pooled connection until the returned
+// stop function is called; stop takes a final sample so queries shorter than one interval still yield one row.
+func sampleIO(ctx context.Context, db *gorm.DB, interval time.Duration) (stop func() []IOSample) {
+	start := time.Now()
+	prev, prevAt := map[string]int64(nil), start
+	var samples []IOSample
+	take := func() {
+		now := time.Now()
+		cur, err := statusCounters(db.WithContext(ctx), "global_status", ioStatusNames)
+		if err != nil {
+			return
+		}
+		if prev != nil {
+			samples = append(samples, IOSample{
+				Elapsed:  now.Sub(start),
+				Interval: now.Sub(prevAt),
+				Counters: counterDeltas(ioStatusNames, prev, cur),
+			})
+		}
+		prev, prevAt = cur, now
+	}
+	take()
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				take()
+				return
+			case <-ticker.C:
+				take()
+			}
+		}
+	}()
+	return func() []IOSample {
+		close(done)
+		<-finished
+		return samples
+	}
+}
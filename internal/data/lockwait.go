@@ -0,0 +1,201 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockWaitPoll is how often data_lock_waits is sampled while a victim statement is blocked.
+const lockWaitPoll = 10 * time.Millisecond
+
+// lockWait is what performance_schema.data_lock_waits showed about a blocked statement.
+type lockWait struct {
+	table, index  string
+	requestedMode string
+	blockingMode  string
+	lockData      string
+	blockingTrx   uint64
+	// observed spans the first to the last sample that saw the wait.
+	observed time.Duration
+}
+
+func (w lockWait) String() string {
+	return fmt.Sprintf("waited on %s.%s (%s) for %s, lock data %s, held %s by trx %d",
+		w.table, w.index, w.requestedMode, w.observed.Round(time.Millisecond), w.lockData, w.blockingMode, w.blockingTrx)
+}
+
+// blocked is the outcome of a statement run against held locks.
+type blocked struct {
+	elapsed time.Duration
+	// lockTime is the statement's LOCK_TIME from events_statements_history, which includes row lock waits (8.0.28+).
+	lockTime time.Duration
+	// wait is nil when the statement never showed up in data_lock_waits.
+	wait *lockWait
+	// err is the statement's own error, e.g. a lock wait timeout.
+	err error
+}
+
+// lockVictim is a statement run in its own transaction, always rolled back. lockWaitTimeout,
+// when set, replaces innodb_lock_wait_timeout (seconds) for that session.
+type lockVictim struct {
+	query           string
+	args            []interface{}
+	lockWaitTimeout int
+}
+
+// holdLocks runs query in a transaction (at the given isolation level; sql.LevelDefault keeps
+// the server's) that is left open, so the locks it takes stay held until the returned
+// transaction is rolled back.
+func holdLocks(ctx context.Context, db *gorm.DB, isolation sql.IsolationLevel, query string, args ...interface{}) (*gorm.DB, error) {
+	tx := db.WithContext(ctx).Begin(&sql.TxOptions{Isolation: isolation})
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	rows, err := tx.Raw(query, args...).Rows()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for rows.Next() {
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// runBlocked runs the victim on its own connection while another transaction holds locks it
+// needs, sampling data_lock_waits until it finishes. After holdFor, release (if not nil) is
+// called to let the victim through; without release the victim has to time out on its own.
+func runBlocked(ctx context.Context, db *gorm.DB, v lockVictim, holdFor time.Duration, release func() error) (blocked, error) {
+	type outcome struct {
+		elapsed  time.Duration
+		lockTime time.Duration
+		err      error
+	}
+	ready := make(chan uint64, 1)
+	done := make(chan outcome, 1)
+	setupErr := make(chan error, 1)
+	go func() {
+		tx := db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			setupErr <- tx.Error
+			return
+		}
+		defer tx.Rollback()
+		threadID, err := currentThreadID(tx)
+		if err != nil {
+			setupErr <- err
+			return
+		}
+		if v.lockWaitTimeout > 0 {
+			if err := tx.Exec("SET SESSION innodb_lock_wait_timeout = ?", v.lockWaitTimeout).Error; err != nil {
+				setupErr <- err
+				return
+			}
+			// The connection goes back to the pool afterwards.
+			defer tx.Exec("SET SESSION innodb_lock_wait_timeout = DEFAULT")
+		}
+		ready <- threadID
+		start := time.Now()
+		err = tx.Exec(v.query, v.args...).Error
+		elapsed := time.Since(start)
+		// The victim is still the thread's latest completed statement.
+		lockTime, _ := lastStatementLockTime(tx, threadID)
+		done <- outcome{elapsed, lockTime, err}
+	}()
+
+	var threadID uint64
+	select {
+	case err := <-setupErr:
+		return blocked{}, err
+	case threadID = <-ready:
+	}
+
+	var (
+		result         blocked
+		first, last    time.Time
+		released       bool
+		deadline       = time.Now().Add(holdFor)
+		ticker         = time.NewTicker(lockWaitPoll)
+		victimFinished outcome
+	)
+	defer ticker.Stop()
+poll:
+	for {
+		select {
+		case victimFinished = <-done:
+			break poll
+		case <-ticker.C:
+		}
+		if w, ok := currentLockWait(ctx, db, threadID); ok {
+			if first.IsZero() {
+				first = time.Now()
+			}
+			last = time.Now()
+			result.wait = &w
+		}
+		if release != nil && !released && time.Now().After(deadline) {
+			released = true
+			if err := release(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if release != nil && !released {
+		if err := release(); err != nil {
+			return result, err
+		}
+	}
+	if result.wait != nil {
+		result.wait.observed = last.Sub(first)
+	}
+	result.elapsed, result.lockTime, result.err = victimFinished.elapsed, victimFinished.lockTime, victimFinished.err
+	return result, nil
+}
+
+// currentLockWait returns the lock the thread is waiting for, if any.
+func currentLockWait(ctx context.Context, db *gorm.DB, threadID uint64) (lockWait, bool) {
+	var rows []struct {
+		ObjectName    string
+		IndexName     string
+		RequestedMode string
+		BlockingMode  string
+		LockData      string
+		BlockingTrx   uint64
+	}
+	err := db.WithContext(ctx).Raw(`SELECT r.OBJECT_NAME AS object_name, COALESCE(r.INDEX_NAME, '') AS index_name,
+			r.LOCK_MODE AS requested_mode, b.LOCK_MODE AS blocking_mode, COALESCE(r.LOCK_DATA, '') AS lock_data,
+			w.BLOCKING_ENGINE_TRANSACTION_ID AS blocking_trx
+		FROM performance_schema.data_lock_waits w
+		JOIN performance_schema.data_locks r ON r.ENGINE_LOCK_ID = w.REQUESTING_ENGINE_LOCK_ID
+		JOIN performance_schema.data_locks b ON b.ENGINE_LOCK_ID = w.BLOCKING_ENGINE_LOCK_ID
+		WHERE w.REQUESTING_THREAD_ID = ? LIMIT 1`, threadID).Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return lockWait{}, false
+	}
+	r := rows[0]
+	return lockWait{
+		table:         r.ObjectName,
+		index:         r.IndexName,
+		requestedMode: r.RequestedMode,
+		blockingMode:  r.BlockingMode,
+		lockData:      r.LockData,
+		blockingTrx:   r.BlockingTrx,
+	}, true
+}
+
+func lastStatementLockTime(conn *gorm.DB, threadID uint64) (time.Duration, error) {
+	var lockTime uint64
+	err := conn.Raw(`SELECT LOCK_TIME FROM performance_schema.events_statements_history
+		WHERE THREAD_ID = ? ORDER BY EVENT_ID DESC LIMIT 1`, threadID).
+		Row().Scan(&lockTime)
+	return psTimer(lockTime), err
+}
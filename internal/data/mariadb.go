@@ -0,0 +1,95 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mariaDBMinVersion is the oldest MariaDB the lab supports: 10.6 introduced IGNORED indexes,
+// its counterpart of MySQL's INVISIBLE indexes, which the schema migrations rely on.
+const mariaDBMinVersion = "10.6.0"
+
+// MariaDBSupport describes how a scenario behaves on MariaDB. The zero value runs it unchanged
+// when it has no MinVersion; a MinVersion names a MySQL release, so such scenarios are taken
+// for MySQL-only unless MinVersion here says which MariaDB release has the feature.
+type MariaDBSupport struct {
+	// MinVersion is the first MariaDB release that can run the scenario.
+	MinVersion string
+	// Skip, when set, is why the scenario cannot run on MariaDB.
+	Skip string
+	// Note is added to the result on MariaDB, for plans or behavior that differ from MySQL.
+	Note string
+}
+
+// mariaDBSwitchNames maps MySQL optimizer_switch flags to their MariaDB names; an empty name
+// marks a flag MariaDB has no equivalent for.
+var mariaDBSwitchNames = map[string]string{
+	"batched_key_access":         "join_cache_bka",
+	"use_index_extensions":       "extended_keys",
+	"derived_condition_pushdown": "condition_pushdown_for_derived",
+	"use_invisible_indexes":      "",
+	"skip_scan":                  "",
+	"hash_join":                  "",
+	"block_nested_loop":          "",
+	"subquery_to_derived":        "",
+	"prefer_ordering_index":      "",
+	"hypergraph_optimizer":       "",
+}
+
+// mariaDBOptimizerSwitch renames the flags of a MySQL optimizer_switch value for MariaDB. It
+// fails on flags without a MariaDB equivalent.
+func mariaDBOptimizerSwitch(value string) (string, error) {
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		flag, setting, _ := strings.Cut(strings.TrimSpace(part), "=")
+		name, known := mariaDBSwitchNames[flag]
+		switch {
+		case !known:
+			continue
+		case name == "":
+			return "", fmt.Errorf("optimizer_switch flag %s does not exist on MariaDB", flag)
+		}
+		parts[i] = name + "=" + setting
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// skipReason returns why sc cannot run on the server v, or "" when it can.
+func skipReason(sc Scenario, v ServerVersion) string {
+	if v.TiDB() {
+		return tidbSkipReason(sc)
+	}
+	if !v.MariaDB() {
+		if !v.AtLeast(sc.MinVersion) {
+			return fmt.Sprintf("requires MySQL %s+, server is %s", sc.MinVersion, v)
+		}
+		return ""
+	}
+	switch {
+	case sc.MariaDB.Skip != "":
+		return "not supported on MariaDB: " + sc.MariaDB.Skip
+	case sc.MariaDB.MinVersion != "":
+		if !v.AtLeast(sc.MariaDB.MinVersion) {
+			return fmt.Sprintf("requires MariaDB %s+, server is %s", sc.MariaDB.MinVersion, v)
+		}
+	case sc.MinVersion != "":
+		return fmt.Sprintf("MySQL %s+ feature, not available on MariaDB", sc.MinVersion)
+	}
+	if sc.Hints != "" {
+		return "optimizer hints are MySQL-only; MariaDB ignores them as comments"
+	}
+	if sc.OptimizerSwitch != "" {
+		if _, err := mariaDBOptimizerSwitch(sc.OptimizerSwitch); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// mariaDBStatement rewrites MySQL DDL for MariaDB, which calls invisible indexes IGNORED.
+func mariaDBStatement(stmt string) string {
+	if strings.HasSuffix(stmt, " INVISIBLE") {
+		return strings.TrimSuffix(stmt, " INVISIBLE") + " IGNORED"
+	}
+	return stmt
+}
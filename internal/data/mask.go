@@ -0,0 +1,131 @@
+package data
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Masking methods accepted by ParseMasker.
+const (
+	MaskHash     = "hash"
+	MaskScramble = "scramble"
+	MaskBucket   = "bucket"
+)
+
+// maskRule is how one column is masked; width is the bucket size of MaskBucket.
+type maskRule struct {
+	method string
+	width  float64
+}
+
+// Masker replaces personal data while importing. Hashing and scrambling are keyed with a
+// secret salt and deterministic, so equal inputs stay equal: cardinality, duplicates and
+// joins between files masked with the same salt survive, the original values do not.
+type Masker struct {
+	rules map[string]maskRule
+	salt  []byte
+}
+
+// ParseMasker parses "phone=hash,customer_name=scramble,total_amount=bucket:50" into a Masker
+// keyed with salt. Column names are table columns, i.e. after ImportConfig.Mapping.
+func ParseMasker(spec, salt string) (*Masker, error) {
+	m := &Masker{rules: make(map[string]maskRule), salt: []byte(salt)}
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		column, method, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("mask rule %q: want column=method", pair)
+		}
+		column, method = strings.TrimSpace(column), strings.TrimSpace(method)
+		rule := maskRule{method: method}
+		switch {
+		case method == MaskHash, method == MaskScramble:
+		case strings.HasPrefix(method, MaskBucket+":"):
+			width, err := strconv.ParseFloat(strings.TrimPrefix(method, MaskBucket+":"), 64)
+			if err != nil || width <= 0 {
+				return nil, fmt.Errorf("mask rule %q: bucket width must be a positive number", pair)
+			}
+			rule = maskRule{method: MaskBucket, width: width}
+		default:
+			return nil, fmt.Errorf("mask rule %q: unknown method (want %s, %s or %s:<width>)", pair, MaskHash, MaskScramble, MaskBucket)
+		}
+		m.rules[column] = rule
+	}
+	return m, nil
+}
+
+// Columns lists the masked columns.
+func (m *Masker) Columns() []string {
+	columns := make([]string, 0, len(m.rules))
+	for c := range m.rules {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// mask returns value with column's rule applied; columns without a rule pass through.
+func (m *Masker) mask(column, value string) (string, error) {
+	rule, ok := m.rules[column]
+	if !ok {
+		return value, nil
+	}
+	switch rule.method {
+	case MaskHash:
+		return m.hash(value), nil
+	case MaskScramble:
+		sum := m.sum(value)
+		h := binary.BigEndian.Uint64(sum[:8])
+		return fakeFirstNames[h%uint64(len(fakeFirstNames))] + " " + fakeLastNames[(h>>32)%uint64(len(fakeLastNames))] +
+			" " + strings.ToUpper(hex.EncodeToString(sum[8:10])), nil
+	default:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("mask %s: %q is not a number", column, value)
+		}
+		return strconv.FormatFloat(math.Floor(v/rule.width)*rule.width, 'f', -1, 64), nil
+	}
+}
+
+func (m *Masker) sum(value string) []byte {
+	mac := hmac.New(sha256.New, m.salt)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// hash keeps the shape of phone-like values (digits stay digits, separators stay put) so
+// indexes and LIKE scenarios behave as before; anything else becomes 16 hex characters.
+func (m *Masker) hash(value string) string {
+	sum := m.sum(value)
+	digits := 0
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case strings.ContainsRune("+-() .", r):
+		default:
+			return hex.EncodeToString(sum[:8])
+		}
+	}
+	if digits == 0 {
+		return hex.EncodeToString(sum[:8])
+	}
+	out := []byte(value)
+	n := 0
+	for i, c := range out {
+		if c >= '0' && c <= '9' {
+			out[i] = '0' + sum[n%len(sum)]%10
+			n++
+		}
+	}
+	return string(out)
+}
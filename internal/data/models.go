@@ -18,3 +18,19 @@ type Order struct {
 	UpdatedAt       time.Time  `gorm:"index"`
 	ShippedAt       *time.Time `gorm:"index"`
 }
+
+// Customer is a small dimension table joined against orders in multi-table scenarios.
+type Customer struct {
+	ID         uint      `gorm:"primaryKey"`
+	Name       string    `gorm:"size:64"`
+	Region     string    `gorm:"size:32"`
+	SignupDate time.Time `gorm:"type:date;index"`
+}
+
+// SeedState is the seeding checkpoint: how many synthetic rows of a dataset have been committed.
+// It is updated in the same transaction as each batch, so an interrupted seed resumes exactly.
+type SeedState struct {
+	Name        string `gorm:"primaryKey;size:64"`
+	RowsWritten int64
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,151 @@
+package data
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StageEvent is a performance_schema stage observed while a scenario query ran.
+type StageEvent struct {
+	Name     string
+	Duration time.Duration
+	Waits    []WaitEvent
+}
+
+// WaitEvent aggregates the waits of one instrument nested inside a stage.
+type WaitEvent struct {
+	Name     string
+	Count    int64
+	Duration time.Duration
+}
+
+// psTimer converts a performance_schema timer value (picoseconds) into a duration.
+func psTimer(ps uint64) time.Duration {
+	return time.Duration(ps / 1000)
+}
+
+func currentThreadID(conn *gorm.DB) (uint64, error) {
+	var threadID uint64
+	err := conn.Raw("SELECT THREAD_ID FROM performance_schema.threads WHERE PROCESSLIST_ID = CONNECTION_ID()").
+		Scan(&threadID).Error
+	return threadID, err
+}
+
+func lastStatementEventID(conn *gorm.DB, threadID uint64) (uint64, error) {
+	var eventID uint64
+	err := conn.Raw("SELECT COALESCE(MAX(EVENT_ID), 0) FROM performance_schema.events_statements_history WHERE THREAD_ID = ?", threadID).
+		Scan(&eventID).Error
+	return eventID, err
+}
+
+// collectStages returns the stage/wait hierarchy of the slowest statement the thread completed after afterEventID.
+// Stages and waits are read from the *_history_long tables, so the consumers must be enabled (see mysql/conf.d/slow.cnf).
+func collectStages(conn *gorm.DB, threadID, afterEventID uint64) ([]StageEvent, error) {
+	var statementID uint64
+	if err := conn.Raw(`SELECT EVENT_ID FROM performance_schema.events_statements_history
+		WHERE THREAD_ID = ? AND EVENT_ID > ? AND (SQL_TEXT IS NULL OR SQL_TEXT NOT LIKE '%performance_schema%')
+		ORDER BY TIMER_WAIT DESC LIMIT 1`, threadID, afterEventID).
+		Scan(&statementID).Error; err != nil {
+		return nil, err
+	}
+	if statementID == 0 {
+		return nil, nil
+	}
+
+	var stageRows []struct {
+		EventID   uint64
+		EventName string
+		TimerWait uint64
+	}
+	if err := conn.Raw(`SELECT EVENT_ID AS event_id, EVENT_NAME AS event_name, COALESCE(TIMER_WAIT, 0) AS timer_wait
+		FROM performance_schema.events_stages_history_long
+		WHERE THREAD_ID = ? AND NESTING_EVENT_ID = ? ORDER BY EVENT_ID`, threadID, statementID).
+		Scan(&stageRows).Error; err != nil {
+		return nil, err
+	}
+	if len(stageRows) == 0 {
+		return nil, nil
+	}
+
+	stageIDs := make([]uint64, 0, len(stageRows))
+	for _, row := range stageRows {
+		stageIDs = append(stageIDs, row.EventID)
+	}
+
+	var waitRows []struct {
+		NestingEventID uint64
+		EventName      string
+		Cnt            int64
+		TimerWait      uint64
+	}
+	if err := conn.Raw(`SELECT NESTING_EVENT_ID AS nesting_event_id, EVENT_NAME AS event_name,
+			COUNT(*) AS cnt, COALESCE(SUM(TIMER_WAIT), 0) AS timer_wait
+		FROM performance_schema.events_waits_history_long
+		WHERE THREAD_ID = ? AND NESTING_EVENT_ID IN ?
+		GROUP BY NESTING_EVENT_ID, EVENT_NAME`, threadID, stageIDs).
+		Scan(&waitRows).Error; err != nil {
+		return nil, err
+	}
+
+	waitsByStage := make(map[uint64][]WaitEvent)
+	for _, row := range waitRows {
+		waitsByStage[row.NestingEventID] = append(waitsByStage[row.NestingEventID], WaitEvent{
+			Name:     row.EventName,
+			Count:    row.Cnt,
+			Duration: psTimer(row.TimerWait),
+		})
+	}
+
+	stages := make([]StageEvent, 0, len(stageRows))
+	for _, row := range stageRows {
+		stages = append(stages, StageEvent{
+			Name:     row.EventName,
+			Duration: psTimer(row.TimerWait),
+			Waits:    waitsByStage[row.EventID],
+		})
+	}
+	return stages, nil
+}
+
+// CounterDelta is the change of a session status variable across a scenario query.
+type CounterDelta struct {
+	Name  string
+	Delta int64
+}
+
+func sessionCounters(conn *gorm.DB, names []string) (map[string]int64, error) {
+	return statusCounters(conn, "session_status", names)
+}
+
+// statusCounters reads numeric status variables from performance_schema.session_status or global_status.
+func statusCounters(conn *gorm.DB, table string, names []string) (map[string]int64, error) {
+	var rows []struct {
+		VariableName  string
+		VariableValue string
+	}
+	if err := conn.Raw("SELECT VARIABLE_NAME AS variable_name, VARIABLE_VALUE AS variable_value FROM performance_schema."+table+" WHERE VARIABLE_NAME IN ?", names).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	values := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		v, err := strconv.ParseInt(row.VariableValue, 10, 64)
+		if err != nil {
+			continue
+		}
+		values[strings.ToLower(row.VariableName)] = v
+	}
+	return values, nil
+}
+
+func counterDeltas(names []string, before, after map[string]int64) []CounterDelta {
+	deltas := make([]CounterDelta, 0, len(names))
+	for _, name := range names {
+		key := strings.ToLower(name)
+		deltas = append(deltas, CounterDelta{Name: name, Delta: after[key] - before[key]})
+	}
+	return deltas
+}
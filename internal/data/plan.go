@@ -0,0 +1,144 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PlanRow is one row of traditional (tabular) EXPLAIN output.
+type PlanRow struct {
+	ID           int64
+	SelectType   string
+	Table        string
+	Partitions   string
+	Type         string
+	PossibleKeys string
+	Key          string
+	KeyLen       string
+	Ref          string
+	Rows         int64
+	Filtered     float64
+	Extra        string
+}
+
+// ExplainPlan runs a traditional EXPLAIN and returns its rows. TiDB's operator tree is
+// recognized by its estRows column and reduced to one row per table scan, see tidbPlanRows.
+func ExplainPlan(ctx context.Context, db *gorm.DB, query string, args ...interface{}) ([]PlanRow, error) {
+	rows, err := db.WithContext(ctx).Raw("EXPLAIN "+query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) > 1 && cols[1] == "estRows" {
+		table, err := scanText(rows, len(cols))
+		if err != nil {
+			return nil, err
+		}
+		return tidbPlanRows(cols, table), nil
+	}
+	var plan []PlanRow
+	for rows.Next() {
+		var row PlanRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return nil, err
+		}
+		plan = append(plan, row)
+	}
+	return plan, rows.Err()
+}
+
+// PlanExpectation describes what the first plan row for a table should look like.
+// Empty fields are not checked; Extra matches by substring.
+type PlanExpectation struct {
+	Table string
+	Type  string
+	Key   string
+	Extra string
+}
+
+// Check compares the expectation against plan rows and describes every mismatch.
+func (e PlanExpectation) Check(rows []PlanRow) []string {
+	var row *PlanRow
+	for i := range rows {
+		if e.Table == "" || rows[i].Table == e.Table {
+			row = &rows[i]
+			break
+		}
+	}
+	if row == nil {
+		return []string{fmt.Sprintf("table %q not found in plan", e.Table)}
+	}
+
+	var mismatches []string
+	if e.Type != "" && !strings.EqualFold(row.Type, e.Type) {
+		mismatches = append(mismatches, fmt.Sprintf("table %s: type=%s, expected %s", row.Table, row.Type, e.Type))
+	}
+	if e.Key != "" && row.Key != e.Key {
+		mismatches = append(mismatches, fmt.Sprintf("table %s: key=%q, expected %q", row.Table, row.Key, e.Key))
+	}
+	if e.Extra != "" && !strings.Contains(row.Extra, e.Extra) {
+		mismatches = append(mismatches, fmt.Sprintf("table %s: Extra=%q, expected to contain %q", row.Table, row.Extra, e.Extra))
+	}
+	return mismatches
+}
+
+// ExplainAnalyze runs EXPLAIN ANALYZE (MySQL 8.0.18+) and returns the iterator tree, one line per node.
+// On MariaDB it runs ANALYZE FORMAT=JSON instead and returns the JSON document line by line;
+// on TiDB it returns the operator table with actual rows and execution info, see tidbExplain.
+// The statement is executed for real, so callers must only pass read-only queries; as a second
+// line of defense it runs in a read-only transaction that is rolled back.
+func ExplainAnalyze(ctx context.Context, db *gorm.DB, query string, args ...interface{}) ([]string, error) {
+	v, _ := DetectServerVersion(ctx, db)
+	var lines []string
+	err := inSnapshot(ctx, db, func(tx *gorm.DB) error {
+		var err error
+		switch {
+		case v.MariaDB():
+			lines, err = analyzeJSON(ctx, tx, query, args...)
+			return err
+		case v.TiDB():
+			lines, err = tidbExplain(ctx, tx, "EXPLAIN ANALYZE "+query, args...)
+			return err
+		}
+		var tree string
+		if err := tx.Raw("EXPLAIN ANALYZE "+query, args...).Row().Scan(&tree); err != nil {
+			return err
+		}
+		lines = strings.Split(strings.TrimRight(tree, "\n"), "\n")
+		return nil
+	})
+	return lines, err
+}
+
+// estimateInspector returns an Inspect hook noting the optimizer's row estimate for every plan row:
+// rows examined and the rows expected to survive the WHERE clause (rows × filtered).
+func estimateInspector(query string, args ...interface{}) func(context.Context, *gorm.DB) ([]string, error) {
+	return func(ctx context.Context, db *gorm.DB) ([]string, error) {
+		plan, err := ExplainPlan(ctx, db, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		notes := make([]string, 0, len(plan))
+		for _, row := range plan {
+			notes = append(notes, fmt.Sprintf("estimate %s: rows=%d filtered=%.2f%% → %.0f rows", row.Table, row.Rows, row.Filtered, float64(row.Rows)*row.Filtered/100))
+		}
+		return notes, nil
+	}
+}
+
+// analyzeJSON runs MariaDB's ANALYZE FORMAT=JSON, which executes the statement and reports
+// r_rows, r_filtered and r_total_time_ms next to the estimates, one line per JSON line.
+func analyzeJSON(ctx context.Context, db *gorm.DB, query string, args ...interface{}) ([]string, error) {
+	var doc string
+	if err := db.WithContext(ctx).Raw("ANALYZE FORMAT=JSON "+query, args...).Row().Scan(&doc); err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(doc, "\n"), "\n"), nil
+}
@@ -0,0 +1,300 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// postgresSyncBatch is how many rows SyncPostgres reads from MySQL per round trip.
+const postgresSyncBatch = 5000
+
+// PostgresSupport describes how a scenario is compared on PostgreSQL. The zero value runs the
+// automatic translation of Query (see TranslatePostgres) for scenarios without setup hooks.
+type PostgresSupport struct {
+	// Query replaces the translated Query, for statements the translation cannot express.
+	Query string
+	// SetupSQL runs on PostgreSQL before the query, for scenarios whose MySQL setup builds
+	// indexes or tables; statements must be idempotent.
+	SetupSQL []string
+	// Skip, when set, is why the scenario has no PostgreSQL counterpart.
+	Skip string
+	// Note is added to the comparison, for differences worth pointing out.
+	Note string
+}
+
+// PostgresResult is the same scenario measured on PostgreSQL: one EXPLAIN (ANALYZE) run of the
+// translated query inside a transaction that is rolled back, so writes leave no trace.
+type PostgresResult struct {
+	Query string
+	// Duration is the Execution Time EXPLAIN ANALYZE reports; RowCount the rows the plan returned.
+	Duration time.Duration
+	RowCount int64
+	Plan     []string
+	// Scans names how each table was read, e.g. "Seq Scan on orders".
+	Scans []string
+	Notes []string
+	// Err is nil on success, a SkippedError for scenarios without a counterpart, or an ExecutionError.
+	Err error
+}
+
+// Summary is a one-line comparison note: time, rows and scans, or the error.
+func (r *PostgresResult) Summary() string {
+	if r.Err != nil {
+		return "postgres: " + r.Err.Error()
+	}
+	summary := fmt.Sprintf("postgres: %s, %d rows", r.Duration, r.RowCount)
+	if len(r.Scans) > 0 {
+		summary += "; " + strings.Join(r.Scans, ", ")
+	}
+	return summary
+}
+
+// SyncPostgres creates orders and customers on pg and copies them from mysql unless both
+// tables already hold the same number of rows, then refreshes the planner statistics.
+func SyncPostgres(ctx context.Context, mysql, pg *gorm.DB) error {
+	if err := pg.WithContext(ctx).AutoMigrate(&Order{}, &Customer{}); err != nil {
+		return err
+	}
+	same := true
+	for _, model := range []interface{}{&Order{}, &Customer{}} {
+		var want, have int64
+		if err := mysql.WithContext(ctx).Model(model).Count(&want).Error; err != nil {
+			return err
+		}
+		if err := pg.WithContext(ctx).Model(model).Count(&have).Error; err != nil {
+			return err
+		}
+		same = same && want == have
+	}
+	if same {
+		return nil
+	}
+
+	if err := pg.WithContext(ctx).Exec("TRUNCATE orders, customers").Error; err != nil {
+		return err
+	}
+	if err := copyToPostgres[Customer](ctx, mysql, pg, "postgres customers"); err != nil {
+		return err
+	}
+	if err := copyToPostgres[Order](ctx, mysql, pg, "postgres orders"); err != nil {
+		return err
+	}
+	return pg.WithContext(ctx).Exec("ANALYZE orders, customers").Error
+}
+
+// copyToPostgres copies a table in primary key order, keeping the ids.
+func copyToPostgres[T any](ctx context.Context, mysql, pg *gorm.DB, label string) error {
+	var total int64
+	if err := mysql.WithContext(ctx).Model(new(T)).Count(&total).Error; err != nil {
+		return err
+	}
+	prog := newProgress(ctx, label, total)
+	var lastID uint
+	for {
+		var ids []uint
+		if err := mysql.WithContext(ctx).Model(new(T)).Where("id > ?", lastID).Order("id").Limit(postgresSyncBatch).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		var batch []T
+		if err := mysql.WithContext(ctx).Where("id > ? AND id <= ?", lastID, ids[len(ids)-1]).Order("id").Find(&batch).Error; err != nil {
+			return err
+		}
+		if err := pg.WithContext(ctx).CreateInBatches(batch, 1000).Error; err != nil {
+			return err
+		}
+		lastID = ids[len(ids)-1]
+		prog.add(len(batch))
+	}
+}
+
+// postgresUnsupported are MySQL constructs without a mechanical PostgreSQL translation.
+var postgresUnsupported = []struct {
+	re   *regexp.Regexp
+	what string
+}{
+	{regexp.MustCompile(`(?i)\bMATCH\s*\(`), "MATCH ... AGAINST"},
+	{regexp.MustCompile(`(?i)\b(ST_|MBR)\w*\s*\(`), "spatial functions"},
+	{regexp.MustCompile(`(?i)\bJSON_\w+\s*\(|->>?|\bMEMBER\s+OF\b`), "MySQL JSON functions"},
+	{regexp.MustCompile(`(?i)\bON\s+DUPLICATE\s+KEY\b`), "ON DUPLICATE KEY UPDATE"},
+	{regexp.MustCompile(`(?i)\bSTRAIGHT_JOIN\b`), "STRAIGHT_JOIN"},
+	{regexp.MustCompile(`(?i)\bLIMIT\s+\?\s*,`), "LIMIT ?, ? (argument order differs)"},
+}
+
+// postgresRewrites translate MySQL syntax to its PostgreSQL spelling, in order.
+var postgresRewrites = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// PostgreSQL has no index hints; the planner decides on its own.
+	{regexp.MustCompile(`(?i)\s+(FORCE|USE|IGNORE)\s+INDEX\s*\([^)]*\)`), ""},
+	{regexp.MustCompile(`(?i)\bIFNULL\s*\(`), "COALESCE("},
+	{regexp.MustCompile(`(?i)\bRAND\s*\(\s*\)`), "random()"},
+	{regexp.MustCompile(`(?i)\bDATABASE\s*\(\s*\)`), "current_database()"},
+	{regexp.MustCompile(`(?i)\bINTERVAL\s+(\d+)\s+(SECOND|MINUTE|HOUR|DAY|WEEK|MONTH|YEAR)\b`), "INTERVAL '$1 $2'"},
+	{regexp.MustCompile(`(?i)\bLIMIT\s+(\d+)\s*,\s*(\d+)`), "LIMIT $2 OFFSET $1"},
+	{regexp.MustCompile("`"), `"`},
+}
+
+// TranslatePostgres rewrites a MySQL statement for PostgreSQL: backquotes, index hints,
+// IFNULL, RAND(), INTERVAL n UNIT and LIMIT offset, count. Statements using MySQL-only
+// features return an error. Placeholders stay "?"; gorm binds them as $n.
+func TranslatePostgres(query string) (string, error) {
+	for _, u := range postgresUnsupported {
+		if u.re.MatchString(query) {
+			return "", fmt.Errorf("no PostgreSQL translation for %s", u.what)
+		}
+	}
+	for _, r := range postgresRewrites {
+		query = r.re.ReplaceAllString(query, r.repl)
+	}
+	return query, nil
+}
+
+// errRollback ends the comparison transaction after the plan has been read.
+var errRollback = errors.New("rollback")
+
+// comparePostgres runs the scenario's query on pg; ArgsFunc, when set, is evaluated on the
+// MySQL database, which holds the same rows after SyncPostgres.
+func comparePostgres(ctx context.Context, mysql, pg *gorm.DB, sc Scenario) *PostgresResult {
+	res := &PostgresResult{}
+	skip := func(reason string) *PostgresResult {
+		res.Err = &SkippedError{Reason: reason}
+		return res
+	}
+	query := sc.Postgres.Query
+	switch {
+	case sc.Postgres.Skip != "":
+		return skip(sc.Postgres.Skip)
+	case query != "":
+	case sc.Query == "":
+		return skip("no single query to compare")
+	case sc.Run != nil:
+		return skip("the scenario orchestrates several MySQL sessions")
+	case (sc.Setup != nil || len(sc.SetupSQL) > 0) && len(sc.Postgres.SetupSQL) == 0:
+		return skip("its setup builds MySQL-only tables or indexes")
+	default:
+		translated, err := TranslatePostgres(sc.Query)
+		if err != nil {
+			return skip(err.Error())
+		}
+		query = translated
+	}
+	res.Query = query
+	if sc.Postgres.Note != "" {
+		res.Notes = append(res.Notes, sc.Postgres.Note)
+	}
+	if sc.Hints != "" || sc.OptimizerSwitch != "" {
+		res.Notes = append(res.Notes, "MySQL optimizer hints and optimizer_switch do not apply")
+	}
+
+	args := sc.Args
+	if sc.ArgsFunc != nil {
+		var err error
+		if args, err = sc.ArgsFunc(ctx, mysql); err != nil {
+			res.Err = &SetupError{Stage: "args", Err: err}
+			return res
+		}
+	}
+	for _, stmt := range sc.Postgres.SetupSQL {
+		if err := pg.WithContext(ctx).Exec(stmt).Error; err != nil {
+			res.Err = &SetupError{Stage: "postgres setup", Err: err}
+			return res
+		}
+	}
+
+	var doc string
+	err := pg.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) "+query, args...).Row().Scan(&doc); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		res.Err = &ExecutionError{Err: err}
+		return res
+	}
+	if err := res.parsePlan(doc); err != nil {
+		res.Err = &ExplainError{Err: err}
+	}
+	return res
+}
+
+// pgPlanNode is one node of EXPLAIN (ANALYZE, FORMAT JSON) output.
+type pgPlanNode struct {
+	NodeType         string       `json:"Node Type"`
+	RelationName     string       `json:"Relation Name"`
+	Alias            string       `json:"Alias"`
+	IndexName        string       `json:"Index Name"`
+	PlanRows         float64      `json:"Plan Rows"`
+	ActualRows       float64      `json:"Actual Rows"`
+	ActualLoops      float64      `json:"Actual Loops"`
+	ActualTotalTime  float64      `json:"Actual Total Time"`
+	Filter           string       `json:"Filter"`
+	IndexCond        string       `json:"Index Cond"`
+	RemovedByFilter  float64      `json:"Rows Removed by Filter"`
+	SortMethod       string       `json:"Sort Method"`
+	SharedHitBlocks  int64        `json:"Shared Hit Blocks"`
+	SharedReadBlocks int64        `json:"Shared Read Blocks"`
+	Plans            []pgPlanNode `json:"Plans"`
+}
+
+// parsePlan fills Duration, RowCount, Plan and Scans from the JSON plan document.
+func (r *PostgresResult) parsePlan(doc string) error {
+	var plans []struct {
+		Plan          pgPlanNode `json:"Plan"`
+		ExecutionTime float64    `json:"Execution Time"`
+	}
+	if err := json.Unmarshal([]byte(doc), &plans); err != nil {
+		return fmt.Errorf("parse EXPLAIN JSON: %w", err)
+	}
+	if len(plans) == 0 {
+		return errors.New("empty EXPLAIN output")
+	}
+	r.Duration = time.Duration(plans[0].ExecutionTime * float64(time.Millisecond))
+	r.RowCount = int64(plans[0].Plan.ActualRows)
+	r.walk(plans[0].Plan, 0)
+	return nil
+}
+
+// walk renders node and its children as indented lines, like EXPLAIN ANALYZE's text format.
+func (r *PostgresResult) walk(n pgPlanNode, depth int) {
+	label := n.NodeType
+	if n.IndexName != "" {
+		label += " using " + n.IndexName
+	}
+	if n.RelationName != "" {
+		label += " on " + n.RelationName
+		if n.Alias != "" && n.Alias != n.RelationName {
+			label += " " + n.Alias
+		}
+		r.Scans = append(r.Scans, label)
+	}
+	line := fmt.Sprintf("%s-> %s (estimated rows=%.0f) (actual rows=%.0f loops=%.0f time=%.3fms, buffers hit=%d read=%d)",
+		strings.Repeat("    ", depth), label, n.PlanRows, n.ActualRows, n.ActualLoops, n.ActualTotalTime, n.SharedHitBlocks, n.SharedReadBlocks)
+	for _, detail := range []struct{ name, value string }{
+		{"Index Cond", n.IndexCond},
+		{"Filter", n.Filter},
+		{"Sort Method", n.SortMethod},
+	} {
+		if detail.value != "" {
+			line += "; " + detail.name + ": " + detail.value
+		}
+	}
+	if n.RemovedByFilter > 0 {
+		line += fmt.Sprintf("; Rows Removed by Filter: %.0f", n.RemovedByFilter)
+	}
+	r.Plan = append(r.Plan, line)
+	for _, child := range n.Plans {
+		r.walk(child, depth+1)
+	}
+}
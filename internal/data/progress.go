@@ -0,0 +1,58 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// progressEvery is how often long-running inserts report progress; shorter jobs stay silent.
+const progressEvery = 5 * time.Second
+
+type progressKey struct{}
+
+// WithProgress returns a context under which SeedDataset and the scenario setup hooks that
+// insert rows report rows written, throughput and ETA to logf while they run.
+func WithProgress(ctx context.Context, logf func(format string, args ...interface{})) context.Context {
+	return context.WithValue(ctx, progressKey{}, logf)
+}
+
+// progress tracks one bulk insert toward a known row count.
+type progress struct {
+	logf     func(format string, args ...interface{})
+	label    string
+	total    int64
+	done     int64
+	start    time.Time
+	reported time.Time
+}
+
+// newProgress starts tracking total rows for label; it is a no-op unless ctx came from WithProgress.
+func newProgress(ctx context.Context, label string, total int64) *progress {
+	logf, _ := ctx.Value(progressKey{}).(func(format string, args ...interface{}))
+	now := time.Now()
+	return &progress{logf: logf, label: label, total: total, start: now, reported: now}
+}
+
+// add records n more rows and logs a line every progressEvery, plus a final one when a job
+// that reported along the way completes.
+func (p *progress) add(n int) {
+	if p.logf == nil {
+		return
+	}
+	p.done += int64(n)
+	now := time.Now()
+	finished := p.done >= p.total
+	if now.Sub(p.reported) < progressEvery && !(finished && p.reported != p.start) {
+		return
+	}
+	p.reported = now
+	elapsed := now.Sub(p.start)
+	rate := float64(p.done) / elapsed.Seconds()
+	if finished {
+		p.logf("%s: %d rows done in %s (%.0f rows/s)", p.label, p.done, elapsed.Round(time.Second), rate)
+		return
+	}
+	eta := time.Duration(float64(p.total-p.done) / rate * float64(time.Second))
+	p.logf("%s: %d/%d rows (%.1f%%), %.0f rows/s, ETA %s", p.label, p.done, p.total,
+		100*float64(p.done)/float64(p.total), rate, eta.Round(time.Second))
+}
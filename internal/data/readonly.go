@@ -0,0 +1,134 @@
+package data
+
+import "strings"
+
+// ReadOnlySQL reports whether query is a plain read: a SELECT or TABLE statement, possibly
+// after a WITH list, that neither locks rows (FOR UPDATE, FOR SHARE, LOCK IN SHARE MODE) nor
+// writes a file or variables (INTO). Trailing semicolons are ignored, further statements are
+// not. The first word alone is not enough: MySQL 8.0.19+ accepts WITH ... DELETE and
+// WITH ... UPDATE, and EXPLAIN ANALYZE executes them.
+func ReadOnlySQL(query string) bool {
+	words, ok := sqlWords(query)
+	for len(words) > 0 && words[len(words)-1] == ";" {
+		words = words[:len(words)-1]
+	}
+	if !ok || len(words) == 0 {
+		return false
+	}
+	i := skipOpenParens(words, 0)
+	if i < len(words) && words[i] == "WITH" {
+		if i = skipWithList(words, i+1); i < 0 {
+			return false
+		}
+		i = skipOpenParens(words, i)
+	}
+	if i >= len(words) || (words[i] != "SELECT" && words[i] != "TABLE") {
+		return false
+	}
+	for j, w := range words {
+		switch w {
+		case "INTO", ";":
+			return false
+		case "UPDATE", "SHARE":
+			if j > 0 && words[j-1] == "FOR" {
+				return false
+			}
+		case "LOCK":
+			if j+1 < len(words) && words[j+1] == "IN" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sqlWords splits query into upper-cased keywords and identifiers plus the punctuation "(",
+// ")", "," and ";". String literals and quoted identifiers become "?", comments are dropped.
+// It fails on executable comments (/*! ... */), whose content the server runs as SQL.
+func sqlWords(query string) ([]string, bool) {
+	var words []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipLiteral(query, i)
+			words = append(words, "?")
+		case strings.HasPrefix(query[i:], "/*!"):
+			return nil, false
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, false
+			}
+			i += end + 4
+		case c == '#' || strings.HasPrefix(query[i:], "-- "):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+		case c == '(' || c == ')' || c == ',' || c == ';':
+			words = append(words, string(c))
+			i++
+		case isIdentByte(c):
+			j := i
+			for j < len(query) && isIdentByte(query[j]) {
+				j++
+			}
+			words = append(words, strings.ToUpper(query[i:j]))
+			i = j
+		default:
+			i++
+		}
+	}
+	return words, len(words) > 0
+}
+
+func skipOpenParens(words []string, i int) int {
+	for i < len(words) && words[i] == "(" {
+		i++
+	}
+	return i
+}
+
+// skipWithList returns the index of the statement after the common table expressions that
+// follow WITH at words[i], or -1 when the list is malformed.
+func skipWithList(words []string, i int) int {
+	if i < len(words) && words[i] == "RECURSIVE" {
+		i++
+	}
+	for {
+		i++ // the CTE name
+		if i < len(words) && words[i] == "(" {
+			if i = skipParens(words, i); i < 0 {
+				return -1
+			}
+		}
+		if i >= len(words) || words[i] != "AS" || i+1 >= len(words) || words[i+1] != "(" {
+			return -1
+		}
+		if i = skipParens(words, i+1); i < 0 || i >= len(words) {
+			return -1
+		}
+		if words[i] != "," {
+			return i
+		}
+		i++
+	}
+}
+
+// skipParens returns the index after the parenthesis that closes words[i] == "(", or -1.
+func skipParens(words []string, i int) int {
+	depth := 0
+	for ; i < len(words); i++ {
+		switch words[i] {
+		case "(":
+			depth++
+		case ")":
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
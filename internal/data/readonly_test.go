@@ -0,0 +1,37 @@
+package data
+
+import "testing"
+
+func TestReadOnlySQL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT * FROM orders WHERE id = 1", true},
+		{"select 1;", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"WITH c AS (SELECT id FROM customers) SELECT * FROM orders JOIN c ON c.id = orders.customer_id", true},
+		{"WITH RECURSIVE n (i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 10), m AS (SELECT 2) SELECT * FROM n, m", true},
+		{"TABLE orders", true},
+		{"SELECT 'for update', `into` FROM t", true},
+		{"SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM t", true},
+		{"SELECT * FROM t -- FOR UPDATE", true},
+		{"WITH c AS (SELECT id FROM orders) DELETE FROM orders WHERE id IN (SELECT id FROM c)", false},
+		{"with c as (select 1) update orders set note = 'x'", false},
+		{"SELECT * FROM orders FOR UPDATE", false},
+		{"SELECT * FROM orders FOR SHARE", false},
+		{"SELECT * FROM orders LOCK IN SHARE MODE", false},
+		{"SELECT * FROM orders INTO OUTFILE '/tmp/o.csv'", false},
+		{"SELECT id INTO @id FROM orders LIMIT 1", false},
+		{"SELECT * FROM orders /*!50000 INTO OUTFILE '/tmp/o.csv' */", false},
+		{"DELETE FROM orders", false},
+		{"SELECT 1; DELETE FROM orders", false},
+		{"", false},
+		{"WITH c AS SELECT 1", false},
+	}
+	for _, tt := range tests {
+		if got := ReadOnlySQL(tt.query); got != tt.want {
+			t.Errorf("ReadOnlySQL(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package data
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RegisteredType is the default Type of scenarios added with Register.
+const RegisteredType = "自定义场景"
+
+var (
+	registeredMu sync.Mutex
+	registered   []Scenario
+)
+
+// Register adds a scenario that RunScenarios runs after the built-in ones, so Go code can
+// extend the lab without editing this package or building a pack. It is meant to be called
+// from init functions and panics on an invalid scenario or a name registered twice.
+func Register(sc Scenario) {
+	if sc.Name == "" || (sc.Query == "" && sc.Run == nil) {
+		panic(fmt.Sprintf("slowlab: Register(%q): scenario needs a name and a Query or Run", sc.Name))
+	}
+	if sc.Type == "" {
+		sc.Type = RegisteredType
+	}
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	for _, existing := range registered {
+		if existing.Name == sc.Name {
+			panic(fmt.Sprintf("slowlab: Register called twice for scenario %q", sc.Name))
+		}
+	}
+	registered = append(registered, sc)
+}
+
+// Registered returns the scenarios added with Register, in registration order.
+func Registered() []Scenario {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	return append([]Scenario(nil), registered...)
+}
@@ -0,0 +1,205 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/internal/slowlog"
+	"mysql-slow-query-lab/pkg/fingerprint"
+
+	"gorm.io/gorm"
+)
+
+// ReplayType is the scenario type of replayed slow log queries.
+const ReplayType = "慢日志回放"
+
+// ReplayConfig controls how slow log query classes are replayed against the lab dataset.
+type ReplayConfig struct {
+	// Tables renames tables of the logged statements to lab tables, keyed by lower-case name
+	// as written in the statement ("orders_v2" or "shop.orders_v2"); see ParseTableMapping.
+	Tables map[string]string
+	// Writes also replays statements that modify data; by default only SELECT, WITH and TABLE
+	// statements run and the others are reported as skipped.
+	Writes bool
+	Run    RunConfig
+}
+
+// ParseTableMapping parses "prod_table=lab_table,..." into a ReplayConfig.Tables map.
+func ParseTableMapping(spec string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return nil, fmt.Errorf("table mapping %q: want logged_table=lab_table", pair)
+		}
+		mapping[strings.ToLower(strings.ReplaceAll(strings.TrimSpace(from), "`", ""))] = strings.TrimSpace(to)
+	}
+	return mapping, nil
+}
+
+// ReplayScenario turns the slowest logged statement of a query class into a scenario; its
+// description carries what the slow log recorded, so the report compares it with the replay.
+func ReplayScenario(c slowlog.Class, tables map[string]string) Scenario {
+	query := strings.TrimRight(strings.TrimSpace(c.Example), ";")
+	return Scenario{
+		Type: ReplayType,
+		Name: "0x" + fingerprint.ID(c.Fingerprint),
+		Description: fmt.Sprintf("慢日志记录 %d 次，平均 %s，最慢 %s，平均扫描 %d 行：%s",
+			c.Count, roundDuration(c.AvgTime()), roundDuration(c.MaxTime), c.RowsExamined/c.Count, c.Fingerprint),
+		Query: MapTables(query, tables),
+	}
+}
+
+// ReplayClasses runs the example statement of each class against the lab dataset with the
+// usual timing and EXPLAIN collection, in the given order, stopping like RunScenarios when ctx
+// is cancelled.
+func ReplayClasses(ctx context.Context, db *gorm.DB, classes []slowlog.Class, cfg ReplayConfig) []ScenarioResult {
+	if cfg.Run.ServerVersion.Major == 0 {
+		if version, err := DetectServerVersion(ctx, db); err == nil {
+			cfg.Run.ServerVersion = version
+		}
+	}
+	results := make([]ScenarioResult, 0, len(classes))
+	for _, c := range classes {
+		if ctx.Err() != nil {
+			break
+		}
+		sc := ReplayScenario(c, cfg.Tables)
+		if !cfg.Writes && !ReadOnlySQL(sc.Query) {
+			res := ScenarioResult{Query: sc.Query}
+			res.Type, res.Name, res.Description = localize(sc, cfg.Run.Lang)
+			res.Err = &SkippedError{Reason: "modifies data; writes are not replayed"}
+			results = append(results, res)
+			continue
+		}
+		res := runScenarioRetrying(ctx, db, sc, cfg.Run)
+		if markInterrupted(ctx, &res) {
+			results = append(results, res)
+			break
+		}
+		if sc.Query != strings.TrimRight(strings.TrimSpace(c.Example), ";") {
+			res.Notes = append(res.Notes, "replayed as: "+sc.Query)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// MapTables renames the identifiers of query that appear in tables, outside string literals
+// and comments. Qualified names ("shop.orders") are looked up as a whole first; a name after
+// a dot is a column and is left alone, so "o.orders" keeps its column.
+func MapTables(query string, tables map[string]string) string {
+	if len(tables) == 0 {
+		return query
+	}
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			j := skipLiteral(query, i)
+			b.WriteString(query[i:j])
+			i = j
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#':
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				j = len(query) - i
+			}
+			b.WriteString(query[i : i+j])
+			i += j
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			j := strings.Index(query[i+2:], "*/")
+			if j < 0 {
+				j = len(query) - i - 4
+			}
+			b.WriteString(query[i : i+j+4])
+			i += j + 4
+		case c == '`' || isIdentByte(c):
+			name, end := readIdent(query, i)
+			if end == i {
+				// An unterminated backquote: copy the rest as is.
+				b.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			afterDot := i > 0 && query[i-1] == '.'
+			if !afterDot && end < len(query) && query[end] == '.' {
+				if second, end2 := readIdent(query, end+1); second != "" {
+					if to, ok := tables[name+"."+second]; ok {
+						b.WriteString(to)
+						i = end2
+						continue
+					}
+				}
+			}
+			if to, ok := tables[name]; ok && !afterDot {
+				b.WriteString(to)
+			} else {
+				b.WriteString(query[i:end])
+			}
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// readIdent reads a plain or backquoted identifier at i and returns it lower-cased, with the
+// offset after it; name is empty when there is none.
+func readIdent(s string, i int) (name string, end int) {
+	if i >= len(s) {
+		return "", i
+	}
+	if s[i] == '`' {
+		j := strings.IndexByte(s[i+1:], '`')
+		if j < 0 {
+			return "", i
+		}
+		return strings.ToLower(s[i+1 : i+1+j]), i + j + 2
+	}
+	j := i
+	for j < len(s) && isIdentByte(s[j]) {
+		j++
+	}
+	return strings.ToLower(s[i:j]), j
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func skipLiteral(s string, i int) int {
+	quote := s[i]
+	j := i + 1
+	for j < len(s) {
+		switch {
+		case s[j] == '\\':
+			j += 2
+			continue
+		case s[j] == quote && j+1 < len(s) && s[j+1] == quote:
+			j += 2
+			continue
+		case s[j] == quote:
+			return j + 1
+		}
+		j++
+	}
+	return len(s)
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(time.Microsecond)
+	}
+	return d
+}
@@ -7,6 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"mysql-slow-query-lab/internal/i18n"
+	"mysql-slow-query-lab/internal/retry"
+	"mysql-slow-query-lab/internal/slowlog"
+
 	"gorm.io/gorm"
 )
 
@@ -43,8 +47,37 @@ type Scenario struct {
 	Name        string
 	Description string
 	Query       string
-	Args        []interface{}
-	Setup       func(context.Context, *gorm.DB) error
+	// Hints is injected as an optimizer hint comment (/*+ ... */) after the leading keyword of Query.
+	Hints string
+	// MinVersion skips the scenario on servers older than the given version (e.g. "8.0.13").
+	MinVersion string
+	// MariaDB tags how the scenario behaves on MariaDB servers.
+	MariaDB MariaDBSupport
+	// TiDB tags how the scenario behaves on TiDB servers.
+	TiDB TiDBSupport
+	// Postgres adapts the scenario for the PostgreSQL comparison (RunConfig.Postgres).
+	Postgres PostgresSupport
+	// OptimizerSwitch is applied to the scenario's session (e.g. "mrr=on,mrr_cost_based=off") and reset afterwards.
+	OptimizerSwitch string
+	// ExpectPlan lists plan shapes the scenario is meant to demonstrate; mismatches are reported as warnings.
+	ExpectPlan []PlanExpectation
+	// ExpectErr marks errors containing this text as the intended outcome rather than a failure.
+	ExpectErr string
+	Args      []interface{}
+	// ArgsFunc computes Args at run time, after Setup, for queries whose arguments depend on current data.
+	ArgsFunc func(context.Context, *gorm.DB) ([]interface{}, error)
+	Setup    func(context.Context, *gorm.DB) error
+	// SetupSQL runs after Setup; with SetupInTx the statements share a single transaction.
+	SetupSQL  []string
+	SetupInTx bool
+	// Counters names session status variables whose change across the query is reported.
+	Counters []string
+	// Run replaces the default "execute Query and count rows" step for scenarios that orchestrate
+	// several statements or sessions; it fills Duration, RowCount and Notes on the result itself.
+	// Query, when set, is still used for EXPLAIN.
+	Run func(context.Context, *gorm.DB, *ScenarioResult) error
+	// Inspect runs after a successful query and returns extra notes, e.g. index statistics worth comparing across variants.
+	Inspect func(context.Context, *gorm.DB) ([]string, error)
 }
 
 // ScenarioResult captures timing and explain output for a scenario.
@@ -55,12 +88,208 @@ type ScenarioResult struct {
 	Duration    time.Duration
 	RowCount    int64
 	Explain     []string
-	Err         error
+	// Query and Plan are the SQL as executed and its traditional EXPLAIN rows, for tools that
+	// reason about the plan (such as the index advisor); Plan is empty when it could not be read.
+	Query string
+	Plan  []PlanRow
+	// SlowLog is what the server's slow log recorded for the query, when the run captured it
+	// (see AttachSlowLog).
+	SlowLog   *slowlog.Entry
+	Stages    []StageEvent
+	Counters  []CounterDelta
+	IOSamples []IOSample
+	Notes     []string
+	Warnings  []string
+	// Postgres is the same query on PostgreSQL, when the run compares against it.
+	Postgres *PostgresResult
+	// Err is nil on success, otherwise one of SetupError, ExecutionError, ExplainError,
+	// ExpectationError or SkippedError (see ErrorKind).
+	Err error
 }
 
-// RunScenarios executes the built-in slow-query demonstrations.
-func RunScenarios(ctx context.Context, db *gorm.DB) []ScenarioResult {
-	scenarios := []Scenario{
+// SQL returns the query as executed, with optimizer hints applied.
+func (sc Scenario) SQL() string {
+	return applyHints(sc.Query, sc.Hints)
+}
+
+// RunConfig toggles optional instrumentation collected while running scenarios.
+type RunConfig struct {
+	// CaptureStages records performance_schema stage and wait events for each scenario query.
+	CaptureStages bool
+	// Cache enables the cache-aside scenarios when non-nil.
+	Cache Cache
+	// ServerVersion gates scenarios by MinVersion; RunScenarios detects it when left zero.
+	ServerVersion ServerVersion
+	// Extra scenarios (e.g. from installed packs) run after the built-in and registered ones.
+	Extra []Scenario
+	// Partitioned adds the partition pruning scenarios; EnsurePartitionedOrders must have run.
+	Partitioned bool
+	// Destructive adds scenarios that delete orders; the next seeding run tops the table back up.
+	Destructive bool
+	// Realistic means the dataset was seeded with realistic names and notes; scenarios that
+	// depend on the synthetic "Customer 004242" names are left out.
+	Realistic bool
+	// SampleIO, when non-zero, samples the global InnoDB IO counters at this interval while each query runs.
+	SampleIO time.Duration
+	// Postgres, when non-nil, also runs each scenario's query there for comparison; SyncPostgres
+	// must have copied the dataset.
+	Postgres *gorm.DB
+	// ReadOnly skips every scenario that may write (see writeReason), leaving the plain SELECTs
+	// and their EXPLAIN output.
+	ReadOnly bool
+	// Only, when non-empty, keeps the scenarios whose type or name contains one of these substrings.
+	Only []string
+	// Retry reruns a read-only scenario that failed on a transient connection error (the server
+	// restarting or dropping connections); the zero Policy reports the failure as it is.
+	Retry retry.Policy
+	// Lang selects the language of each result's type, name and description; Only matches
+	// either language.
+	Lang i18n.Lang
+}
+
+// RunScenarios executes the built-in slow-query demonstrations, then the scenarios added with
+// Register and cfg.Extra. When ctx is cancelled, the scenario running at that moment is
+// reported with ErrInterrupted (its query killed on the server) and no further ones start.
+func RunScenarios(ctx context.Context, db *gorm.DB, cfg RunConfig) []ScenarioResult {
+	if cfg.ServerVersion.Major == 0 {
+		if version, err := DetectServerVersion(ctx, db); err == nil {
+			cfg.ServerVersion = version
+		}
+	}
+	scenarios := allScenarios(cfg)
+	results := make([]ScenarioResult, 0, len(scenarios))
+	for _, sc := range scenarios {
+		if !cfg.selected(sc) {
+			continue
+		}
+		res := runScenarioRetrying(ctx, db, sc, cfg)
+		if markInterrupted(ctx, &res) {
+			results = append(results, res)
+			break
+		}
+		if cfg.Postgres != nil && ErrorKind(res.Err) != "skipped" {
+			res.Postgres = comparePostgres(ctx, db, cfg.Postgres, sc)
+			res.Notes = append(res.Notes, res.Postgres.Summary())
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// ScenarioInfo describes a scenario without running it.
+type ScenarioInfo struct {
+	Type        string
+	Name        string
+	Description string
+	// Query is the SQL as executed and explained; empty for scenarios that only run Go code.
+	Query string
+}
+
+// ListScenarios returns the scenarios RunScenarios would consider with cfg, in run order and
+// in cfg.Lang. Version and read-only checks happen at run time, so some may still be skipped.
+func ListScenarios(cfg RunConfig) []ScenarioInfo {
+	var infos []ScenarioInfo
+	for _, sc := range allScenarios(cfg) {
+		if !cfg.selected(sc) {
+			continue
+		}
+		typ, name, description := localize(sc, cfg.Lang)
+		infos = append(infos, ScenarioInfo{Type: typ, Name: name, Description: description, Query: sc.SQL()})
+	}
+	return infos
+}
+
+// runScenarioRetrying runs sc, and again as cfg.Retry allows while it fails on a transient
+// connection error. Only plain reads are retried (see writeReason): a rerun of setup or a Run
+// hook could apply its writes twice. A retried result carries a warning, as its timing is that
+// of the last attempt.
+func runScenarioRetrying(ctx context.Context, db *gorm.DB, sc Scenario, cfg RunConfig) ScenarioResult {
+	if writeReason(sc) != "" {
+		return runScenario(ctx, db, sc, cfg)
+	}
+	var res ScenarioResult
+	attempts := 0
+	cfg.Retry.Do(ctx, "scenario "+sc.Name, func() error {
+		attempts++
+		res = runScenario(ctx, db, sc, cfg)
+		return res.Err
+	})
+	if attempts > 1 {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("retried %d times after transient connection errors; the timing is from the last attempt", attempts-1))
+	}
+	return res
+}
+
+// selected reports whether sc passes the cfg.Only filter.
+func (cfg RunConfig) selected(sc Scenario) bool {
+	if len(cfg.Only) == 0 {
+		return true
+	}
+	typ, name, _ := localize(sc, i18n.English)
+	for _, pattern := range cfg.Only {
+		if strings.Contains(sc.Type, pattern) || strings.Contains(sc.Name, pattern) ||
+			strings.Contains(typ, pattern) || strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// allScenarios returns every scenario of a run with cfg before the Only filter: the built-in
+// ones, then those added with Register, then cfg.Extra.
+func allScenarios(cfg RunConfig) []Scenario {
+	return append(append(builtinScenarios(cfg), Registered()...), cfg.Extra...)
+}
+
+func builtinScenarios(cfg RunConfig) []Scenario {
+	groups := [][]Scenario{
+		coveringIndexScenarios(),
+		functionIndexScenarios(),
+		typeMatchScenarios(),
+		unionScenarios(),
+		cacheScenarios(cfg.Cache),
+		randomScenarios(),
+		countScenarios(),
+		charsetScenarios(),
+		hintScenarios(),
+		icpScenarios(),
+		mrrScenarios(),
+		prefixScenarios(cfg.Realistic),
+		descIndexScenarios(),
+		skipScanScenarios(),
+		duplicateScenarios(),
+		histogramScenarios(),
+		latestPerGroupScenarios(),
+		staleStatsScenarios(),
+		jsonScenarios(),
+		fulltextScenarios(),
+		spatialScenarios(),
+		reverseSuffixScenarios(),
+		archiveScenarios(),
+		upsertScenarios(),
+		lockScenarios(),
+		deadlockScenarios(),
+		gapLockScenarios(),
+		connectionScenarios(),
+		poolScenarios(),
+		preparedScenarios(),
+	}
+	if cfg.Partitioned {
+		groups = append(groups, partitionScenarios())
+	}
+	// Last, so the deleted slices cannot affect the other scenarios of this run.
+	if cfg.Destructive {
+		groups = append(groups, deleteScenarios())
+	}
+	var scenarios []Scenario
+	for _, group := range groups {
+		scenarios = append(scenarios, group...)
+	}
+	return scenarios
+}
+
+func coveringIndexScenarios() []Scenario {
+	return []Scenario{
 		{
 			Type:        "回表对比",
 			Name:        "索引回表查询",
@@ -77,6 +306,11 @@ func RunScenarios(ctx context.Context, db *gorm.DB) []ScenarioResult {
 			Args:        []interface{}{coveringCustomerID},
 			Setup:       ensureHotCustomerOrders,
 		},
+	}
+}
+
+func functionIndexScenarios() []Scenario {
+	scenarios := []Scenario{
 		{
 			Type:        "索引字段做函数操作对比",
 			Name:        "函数包裹索引列",
@@ -93,12 +327,53 @@ func RunScenarios(ctx context.Context, db *gorm.DB) []ScenarioResult {
 			Args:        indexFuncRangeArgs,
 			Setup:       ensureDateRangeOrders,
 		},
+		{
+			Type:        "索引字段做函数操作对比",
+			Name:        "生成列索引",
+			Description: "新增 STORED 生成列 created_date = DATE(created_at) 并建索引，按日期等值查询直接命中。",
+			Query:       "SELECT * FROM orders WHERE created_date = ?",
+			Args:        []interface{}{indexFuncDate},
+			Setup:       ensureDateRangeOrders,
+			// The index is kept invisible so it cannot rescue the DATE(created_at) scenario above;
+			// servers without invisible indexes do not get it at all.
+			MinVersion:      invisibleIndexMinVersion,
+			OptimizerSwitch: "use_invisible_indexes=on",
+			ExpectPlan:      []PlanExpectation{{Table: "orders", Type: "ref", Key: "idx_orders_created_date"}},
+		},
+		{
+			Type:        "索引字段做函数操作对比",
+			Name:        "函数索引",
+			Description: "MySQL 8.0.13+ 可直接对表达式 (DATE(created_at)) 建函数索引，原样的函数写法也能走索引。",
+			Query:       "SELECT * FROM orders WHERE DATE(created_at) = ?",
+			Args:        []interface{}{indexFuncDate},
+			MinVersion:  functionalIndexMinVersion,
+			Setup: func(ctx context.Context, db *gorm.DB) error {
+				if err := ensureDateRangeOrders(ctx, db); err != nil {
+					return err
+				}
+				return ensureFunctionalDateIndex(ctx, db)
+			},
+			OptimizerSwitch: "use_invisible_indexes=on",
+			ExpectPlan:      []PlanExpectation{{Table: "orders", Type: "ref"}},
+		},
+	}
+	scenarios = append(scenarios, functionJoinScenarios()...)
+	return append(scenarios, functionUpdateScenarios()...)
+}
+
+func typeMatchScenarios() []Scenario {
+	return []Scenario{
 		{
 			Type:        "类型匹配对比",
 			Name:        "类型不匹配隐式转换",
 			Description: "phone 列为字符串但使用数字常量比较，触发隐式转换并导致索引失效。",
 			Query:       "SELECT * FROM orders WHERE phone = 13812345678",
 			Setup:       ensurePhoneHotOrders,
+			// The setup only adds orders, which SyncPostgres copies on the next run.
+			Postgres: PostgresSupport{
+				Query: "SELECT * FROM orders WHERE phone = 13812345678",
+				Note:  "PostgreSQL has no implicit varchar = bigint comparison and rejects the query instead of converting every row",
+			},
 		},
 		{
 			Type:        "类型匹配对比",
@@ -107,60 +382,287 @@ func RunScenarios(ctx context.Context, db *gorm.DB) []ScenarioResult {
 			Query:       "SELECT * FROM orders WHERE phone = ?",
 			Args:        []interface{}{PhoneHotValue},
 			Setup:       ensurePhoneHotOrders,
+			Postgres:    PostgresSupport{Query: "SELECT * FROM orders WHERE phone = ?"},
 		},
 	}
+}
 
-	results := make([]ScenarioResult, 0, len(scenarios))
-	for _, sc := range scenarios {
-		res := ScenarioResult{Name: sc.Name, Description: sc.Description, Type: sc.Type}
+// writeReason explains why sc is left out of a read-only run, or is empty when it only reads:
+// Setup, SetupSQL and Run may create or change objects, so their scenarios count as writes.
+func writeReason(sc Scenario) string {
+	switch {
+	case sc.Setup != nil || len(sc.SetupSQL) > 0:
+		return "read-only mode: scenario setup writes"
+	case sc.Run != nil:
+		return "read-only mode: scenario runs its own statements"
+	case !ReadOnlySQL(sc.Query):
+		return "read-only mode: query is not a SELECT"
+	}
+	return ""
+}
+
+func runScenario(ctx context.Context, db *gorm.DB, sc Scenario, cfg RunConfig) ScenarioResult {
+	res := ScenarioResult{Query: sc.SQL()}
+	res.Type, res.Name, res.Description = localize(sc, cfg.Lang)
 
-		if sc.Setup != nil {
-			if err := sc.Setup(ctx, db); err != nil {
-				res.Err = fmt.Errorf("setup: %w", err)
-				results = append(results, res)
-				continue
+	if reason := skipReason(sc, cfg.ServerVersion); reason != "" {
+		res.Err = &SkippedError{Reason: reason}
+		return res
+	}
+	if cfg.ReadOnly {
+		if reason := writeReason(sc); reason != "" {
+			res.Err = &SkippedError{Reason: reason}
+			return res
+		}
+	}
+	if cfg.ServerVersion.MariaDB() && sc.MariaDB.Note != "" {
+		res.Notes = append(res.Notes, "MariaDB: "+sc.MariaDB.Note)
+	}
+	if cfg.ServerVersion.TiDB() {
+		if sc.TiDB.Note != "" {
+			res.Notes = append(res.Notes, "TiDB: "+sc.TiDB.Note)
+		}
+		if len(sc.Counters) > 0 {
+			// Handler_* and Innodb_* are storage engine counters TiDB does not maintain.
+			res.Notes = append(res.Notes, "TiDB: session counters not collected ("+strings.Join(sc.Counters, ", ")+")")
+			sc.Counters = nil
+		}
+	}
+
+	warnings, err := RunSetup(ctx, db, sc)
+	res.Warnings = append(res.Warnings, warnings...)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	if sc.ArgsFunc != nil {
+		args, err := sc.ArgsFunc(ctx, db)
+		if err != nil {
+			res.Err = &SetupError{Stage: "args", Err: err}
+			return res
+		}
+		sc.Args = args
+	}
+
+	if sc.Run != nil {
+		var stopIO func() []IOSample
+		if cfg.SampleIO > 0 {
+			stopIO = sampleIO(ctx, db, cfg.SampleIO)
+		}
+		err := sc.Run(ctx, db, &res)
+		if stopIO != nil {
+			res.IOSamples = stopIO()
+		}
+		if err != nil {
+			if sc.ExpectErr == "" || !strings.Contains(err.Error(), sc.ExpectErr) {
+				res.Err = &ExecutionError{Err: err}
+				return res
+			}
+			res.Notes = append(res.Notes, fmt.Sprintf("expected error: %v", err))
+		}
+		if sc.Query != "" {
+			explain, err := explainQuery(ctx, db, cfg.ServerVersion, sc.SQL(), sc.Args...)
+			if err == nil {
+				res.Explain = append(res.Explain, explain...)
+				recordPlan(ctx, db, sc, &res)
+			} else {
+				res.Err = &ExplainError{Err: err}
+			}
+		}
+		inspectScenario(ctx, db, sc, &res)
+		return res
+	}
+
+	// Pin a single connection so session-scoped instrumentation sees the scenario query.
+	err = db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if sc.OptimizerSwitch != "" {
+			if err := conn.Exec("SET SESSION optimizer_switch = ?", optimizerSwitch(sc, cfg.ServerVersion)).Error; err != nil {
+				return &SetupError{Stage: "optimizer_switch", Err: err}
+			}
+			// The connection goes back to the pool afterwards, so never leak the switch to other scenarios.
+			defer conn.Exec("SET SESSION optimizer_switch = DEFAULT")
+		}
+
+		var threadID, lastEventID uint64
+		if cfg.CaptureStages {
+			var err error
+			if threadID, err = currentThreadID(conn); err == nil {
+				lastEventID, err = lastStatementEventID(conn, threadID)
+			}
+			if err != nil {
+				res.Explain = append(res.Explain, fmt.Sprintf("failed to prepare stage capture: %v", err))
+				threadID = 0
+			}
+		}
+
+		// Only a cancellable run needs the connection id, to kill the query on cancellation.
+		var connID uint64
+		if ctx.Done() != nil {
+			if err := conn.Raw("SELECT CONNECTION_ID()").Scan(&connID).Error; err != nil {
+				connID = 0
 			}
 		}
 
+		var countersBefore map[string]int64
+		if len(sc.Counters) > 0 {
+			var err error
+			if countersBefore, err = sessionCounters(conn, sc.Counters); err != nil {
+				res.Warnings = append(res.Warnings, fmt.Sprintf("failed to read session counters: %v", err))
+			}
+		}
+
+		var stopIO func() []IOSample
+		if cfg.SampleIO > 0 {
+			stopIO = sampleIO(ctx, db, cfg.SampleIO)
+		}
+		stopKill := func() {}
+		if connID != 0 {
+			stopKill = killOnCancel(ctx, db, connID)
+		}
 		start := time.Now()
-		rows, err := db.WithContext(ctx).Raw(sc.Query, sc.Args...).Rows()
+		rows, err := conn.Raw(sc.SQL(), sc.Args...).Rows()
 		if err != nil {
-			res.Err = err
-			results = append(results, res)
-			continue
+			stopKill()
+			if stopIO != nil {
+				stopIO()
+			}
+			return &ExecutionError{Err: err}
 		}
 
 		var count int64
 		for rows.Next() {
 			count++
 		}
+		err = rows.Err()
 		rows.Close()
-
 		res.Duration = time.Since(start)
+		stopKill()
+		if stopIO != nil {
+			res.IOSamples = stopIO()
+		}
+		if err != nil {
+			return &ExecutionError{Err: err}
+		}
 		res.RowCount = count
 
-		explain, err := explainQuery(ctx, db, sc.Query, sc.Args...)
-		if err == nil {
-			res.Explain = explain
-		} else {
-			res.Explain = []string{fmt.Sprintf("failed to collect EXPLAIN: %v", err)}
+		if countersBefore != nil {
+			countersAfter, err := sessionCounters(conn, sc.Counters)
+			if err == nil {
+				res.Counters = counterDeltas(sc.Counters, countersBefore, countersAfter)
+			} else {
+				res.Warnings = append(res.Warnings, fmt.Sprintf("failed to read session counters: %v", err))
+			}
 		}
 
-		results = append(results, res)
+		if threadID != 0 {
+			stages, err := collectStages(conn, threadID, lastEventID)
+			if err == nil {
+				res.Stages = stages
+			} else {
+				res.Explain = append(res.Explain, fmt.Sprintf("failed to collect stages: %v", err))
+			}
+		}
+
+		explain, err := explainQuery(ctx, conn, cfg.ServerVersion, sc.SQL(), sc.Args...)
+		if err != nil {
+			return &ExplainError{Err: err}
+		}
+		res.Explain = append(res.Explain, explain...)
+		recordPlan(ctx, conn, sc, &res)
+
+		if len(sc.ExpectPlan) > 0 {
+			return checkPlanExpectations(ctx, conn, sc)
+		}
+		return nil
+	})
+	switch kind := ErrorKind(err); {
+	case kind == "execution" && sc.ExpectErr != "" && strings.Contains(err.Error(), sc.ExpectErr):
+		res.Notes = append(res.Notes, fmt.Sprintf("expected error: %v", err))
+		return res
+	case kind == "setup" || kind == "execution":
+		res.Err = err
+		return res
 	}
+	// Explain and expectation errors still leave a measured query worth inspecting.
+	res.Err = err
+	inspectScenario(ctx, db, sc, &res)
+	return res
+}
 
-	return results
+func inspectScenario(ctx context.Context, db *gorm.DB, sc Scenario, res *ScenarioResult) {
+	if sc.Inspect == nil {
+		return
+	}
+	notes, err := sc.Inspect(ctx, db)
+	if err != nil {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("inspect: %v", err))
+	}
+	res.Notes = append(res.Notes, notes...)
+}
+
+// recordPlan keeps the traditional EXPLAIN rows next to the formatted plan lines.
+func recordPlan(ctx context.Context, db *gorm.DB, sc Scenario, res *ScenarioResult) {
+	plan, err := ExplainPlan(ctx, db, sc.SQL(), sc.Args...)
+	if err != nil {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("failed to read plan rows: %v", err))
+		return
+	}
+	res.Plan = plan
+}
+
+// checkPlanExpectations returns an ExpectationError listing every mismatch, or an ExplainError when the plan cannot be read.
+func checkPlanExpectations(ctx context.Context, conn *gorm.DB, sc Scenario) error {
+	plan, err := ExplainPlan(ctx, conn, sc.SQL(), sc.Args...)
+	if err != nil {
+		return &ExplainError{Err: err}
+	}
+	var mismatches []string
+	for _, expect := range sc.ExpectPlan {
+		mismatches = append(mismatches, expect.Check(plan)...)
+	}
+	if len(mismatches) > 0 {
+		return &ExpectationError{Mismatches: mismatches}
+	}
+	return nil
 }
 
-func explainQuery(ctx context.Context, db *gorm.DB, query string, args ...interface{}) ([]string, error) {
-	explainSQL := "EXPLAIN ANALYZE " + query
-	lines, err := fetchExplain(ctx, db, explainSQL, args...)
-	if err == nil {
+// explainQuery returns the executed plan (EXPLAIN ANALYZE, or ANALYZE FORMAT=JSON on MariaDB),
+// falling back to the estimated plan when the server cannot analyze the statement. Every
+// server's analyzing form executes the statement — MySQL's EXPLAIN ANALYZE included, for
+// multi-table UPDATE and DELETE — so writes only get the estimated plan.
+func explainQuery(ctx context.Context, db *gorm.DB, v ServerVersion, query string, args ...interface{}) ([]string, error) {
+	if v.TiDB() {
+		if ReadOnlySQL(query) {
+			if lines, err := tidbExplain(ctx, db, "EXPLAIN ANALYZE "+query, args...); err == nil {
+				return lines, nil
+			}
+		}
+		return tidbExplain(ctx, db, "EXPLAIN "+query, args...)
+	}
+	if !ReadOnlySQL(query) {
+		return fetchExplain(ctx, db, "EXPLAIN "+query, args...)
+	}
+	if v.MariaDB() {
+		if lines, err := analyzeJSON(ctx, db, query, args...); err == nil {
+			return lines, nil
+		}
+	} else if lines, err := fetchExplain(ctx, db, "EXPLAIN ANALYZE "+query, args...); err == nil {
 		return lines, nil
 	}
 	return fetchExplain(ctx, db, "EXPLAIN "+query, args...)
 }
 
+// optimizerSwitch returns the scenario's optimizer_switch in the server's flag names.
+func optimizerSwitch(sc Scenario, v ServerVersion) string {
+	if !v.MariaDB() {
+		return sc.OptimizerSwitch
+	}
+	// skipReason has already ruled out flags MariaDB lacks.
+	value, _ := mariaDBOptimizerSwitch(sc.OptimizerSwitch)
+	return value
+}
+
 func fetchExplain(ctx context.Context, db *gorm.DB, sql string, args ...interface{}) ([]string, error) {
 	var rows []map[string]interface{}
 	if err := db.WithContext(ctx).Raw(sql, args...).Scan(&rows).Error; err != nil {
@@ -201,6 +703,7 @@ func ensureHotCustomerOrders(ctx context.Context, db *gorm.DB) error {
 
 	batch := make([]Order, 0, 1000)
 	toInsert := CoveringCustomerTarget - existing
+	prog := newProgress(ctx, "hot customer orders", toInsert)
 	for i := int64(0); i < toInsert; i++ {
 		newOrder := template
 		newOrder.ID = 0
@@ -219,6 +722,7 @@ func ensureHotCustomerOrders(ctx context.Context, db *gorm.DB) error {
 			if err := db.WithContext(ctx).Create(&batch).Error; err != nil {
 				return err
 			}
+			prog.add(len(batch))
 			batch = batch[:0]
 		}
 	}
@@ -239,9 +743,11 @@ func ensurePhoneHotOrders(ctx context.Context, db *gorm.DB) error {
 	}
 
 	batch := make([]Order, 0, 1000)
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rnd := datasetRand(ctx, streamPhoneHot)
+	now := datasetNow(ctx)
+	prog := newProgress(ctx, "phone hot orders", target-existing)
 	for i := existing; i < target; i++ {
-		created := time.Now().Add(-time.Duration(rnd.Intn(365*24)) * time.Hour)
+		created := now.Add(-time.Duration(rnd.Intn(365*24)) * time.Hour)
 		order := Order{
 			CustomerID:      coveringCustomerID + 2000 + uint(i),
 			CustomerName:    fmt.Sprintf("PhoneHot %06d", i),
@@ -260,6 +766,7 @@ func ensurePhoneHotOrders(ctx context.Context, db *gorm.DB) error {
 			if err := db.WithContext(ctx).Create(&batch).Error; err != nil {
 				return err
 			}
+			prog.add(len(batch))
 			batch = batch[:0]
 		}
 	}
@@ -286,7 +793,8 @@ func ensureDateRangeOrders(ctx context.Context, db *gorm.DB) error {
 
 	toInsert := target - existing
 	batch := make([]Order, 0, 2000)
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rnd := datasetRand(ctx, streamDateRange)
+	prog := newProgress(ctx, "date range orders", toInsert)
 
 	for i := int64(0); i < toInsert; i++ {
 		created := indexFuncRangeStart.Add(time.Duration(rnd.Intn(24*60*60)) * time.Second)
@@ -315,12 +823,25 @@ func ensureDateRangeOrders(ctx context.Context, db *gorm.DB) error {
 			if err := db.WithContext(ctx).Create(&batch).Error; err != nil {
 				return err
 			}
+			prog.add(len(batch))
 			batch = batch[:0]
 		}
 	}
 	return nil
 }
 
+const functionalIndexMinVersion = "8.0.13"
+
+// ensureFunctionalDateIndex creates an invisible functional index on DATE(created_at); the
+// functional-index scenario makes it usable only for its own session via use_invisible_indexes.
+func ensureFunctionalDateIndex(ctx context.Context, db *gorm.DB) error {
+	if db.WithContext(ctx).Migrator().HasIndex(&Order{}, "idx_orders_created_at_date") {
+		return nil
+	}
+	return db.WithContext(ctx).
+		Exec("CREATE INDEX idx_orders_created_at_date ON orders ((DATE(created_at))) INVISIBLE").Error
+}
+
 func mustParseDateTime(value string) time.Time {
 	t, err := time.Parse(dateTimeLayout, value)
 	if err != nil {
@@ -0,0 +1,211 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Cache is the minimal key/value store the cache-aside scenarios need; internal/cache provides a Redis implementation.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+const (
+	cacheReadIterations     = 10
+	cacheTTL                = 5 * time.Minute
+	hotCustomerSummaryQuery = "SELECT COUNT(*), COALESCE(SUM(total_amount), 0) FROM orders WHERE customer_id = ?"
+)
+
+func cacheScenarios(cache Cache) []Scenario {
+	if cache == nil {
+		return nil
+	}
+	return []Scenario{
+		{
+			Type:        "缓存旁路对比",
+			Name:        "直接读库",
+			Description: fmt.Sprintf("热点客户汇总每次都聚合 100 万行订单，连续读取 %d 次。", cacheReadIterations),
+			Query:       hotCustomerSummaryQuery,
+			Args:        []interface{}{coveringCustomerID},
+			Setup:       ensureHotCustomerOrders,
+			Run:         runDirectSummaryReads,
+		},
+		{
+			Type:        "缓存旁路对比",
+			Name:        "Cache-Aside 读取",
+			Description: "先查 Redis，未命中才回源 MySQL 并回填，后续读取直接命中缓存。",
+			Query:       hotCustomerSummaryQuery,
+			Args:        []interface{}{coveringCustomerID},
+			Setup:       ensureHotCustomerOrders,
+			Run: func(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+				return runCacheAsideReads(ctx, db, cache, res)
+			},
+		},
+		{
+			Type:        "缓存旁路对比",
+			Name:        "并发更新导致脏缓存",
+			Description: "读请求回源后、回填前，写请求更新 DB 并删除缓存，旧值被写回缓存直到过期。",
+			Setup:       ensureHotCustomerOrders,
+			Run: func(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+				return runStaleCacheRead(ctx, db, cache, res)
+			},
+		},
+	}
+}
+
+type customerSummary struct {
+	Orders int64
+	Amount float64
+}
+
+func (s customerSummary) encode() string {
+	return fmt.Sprintf("%d|%.2f", s.Orders, s.Amount)
+}
+
+func loadHotCustomerSummary(ctx context.Context, db *gorm.DB) (customerSummary, error) {
+	var s customerSummary
+	err := db.WithContext(ctx).Raw(hotCustomerSummaryQuery, coveringCustomerID).Row().Scan(&s.Orders, &s.Amount)
+	return s, err
+}
+
+func runDirectSummaryReads(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+	start := time.Now()
+	for i := 0; i < cacheReadIterations; i++ {
+		if _, err := loadHotCustomerSummary(ctx, db); err != nil {
+			return err
+		}
+	}
+	res.Duration = time.Since(start)
+	res.RowCount = cacheReadIterations
+	res.Notes = append(res.Notes, fmt.Sprintf("reads=%d avg=%s", cacheReadIterations, res.Duration/cacheReadIterations))
+	return nil
+}
+
+func runCacheAsideReads(ctx context.Context, db *gorm.DB, cache Cache, res *ScenarioResult) error {
+	key := fmt.Sprintf("slowlab:customer:%d:summary", coveringCustomerID)
+	if err := cache.Del(ctx, key); err != nil {
+		return fmt.Errorf("reset cache: %w", err)
+	}
+
+	var hits, misses int
+	var hitTime, missTime time.Duration
+	start := time.Now()
+	for i := 0; i < cacheReadIterations; i++ {
+		readStart := time.Now()
+		_, ok, err := cache.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			summary, err := loadHotCustomerSummary(ctx, db)
+			if err != nil {
+				return err
+			}
+			if err := cache.Set(ctx, key, summary.encode(), cacheTTL); err != nil {
+				return err
+			}
+			misses++
+			missTime += time.Since(readStart)
+			continue
+		}
+		hits++
+		hitTime += time.Since(readStart)
+	}
+	res.Duration = time.Since(start)
+	res.RowCount = cacheReadIterations
+	res.Notes = append(res.Notes, fmt.Sprintf("hits=%d misses=%d avg_hit=%s avg_miss=%s",
+		hits, misses, averageDuration(hitTime, hits), averageDuration(missTime, misses)))
+	return nil
+}
+
+// runStaleCacheRead replays the classic cache-aside race deterministically:
+// reader misses and loads the old value, writer updates the row and deletes the key,
+// then the reader backfills the old value, leaving the cache stale until its TTL expires.
+func runStaleCacheRead(ctx context.Context, db *gorm.DB, cache Cache, res *ScenarioResult) error {
+	var order Order
+	if err := db.WithContext(ctx).Where("customer_id = ?", coveringCustomerID).Order("id ASC").Take(&order).Error; err != nil {
+		return fmt.Errorf("pick hot order: %w", err)
+	}
+	key := fmt.Sprintf("slowlab:order:%d:amount", order.ID)
+	if err := cache.Del(ctx, key); err != nil {
+		return fmt.Errorf("reset cache: %w", err)
+	}
+
+	if _, ok, err := cache.Get(ctx, key); err != nil || ok {
+		return fmt.Errorf("expected cold cache (hit=%v): %v", ok, err)
+	}
+
+	start := time.Now()
+	loaded := make(chan struct{})
+	writeDone := make(chan error, 1)
+	go func() {
+		<-loaded
+		err := db.WithContext(ctx).Model(&Order{}).Where("id = ?", order.ID).
+			Update("total_amount", gorm.Expr("total_amount + 1")).Error
+		if err == nil {
+			err = cache.Del(ctx, key)
+		}
+		writeDone <- err
+	}()
+
+	oldAmount, err := loadOrderAmount(ctx, db, order.ID)
+	close(loaded)
+	if werr := <-writeDone; werr != nil {
+		return fmt.Errorf("writer: %w", werr)
+	}
+	defer db.WithContext(ctx).Model(&Order{}).Where("id = ?", order.ID).
+		Update("total_amount", gorm.Expr("total_amount - 1"))
+	if err != nil {
+		return err
+	}
+	if err := cache.Set(ctx, key, formatAmount(oldAmount), cacheTTL); err != nil {
+		return err
+	}
+
+	cached, _, err := cache.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	current, err := loadOrderAmount(ctx, db, order.ID)
+	if err != nil {
+		return err
+	}
+	res.Duration = time.Since(start)
+	res.RowCount = 1
+	res.Notes = append(res.Notes, fmt.Sprintf("order=%d cache=%s db=%s stale=%v (persists up to TTL %s)",
+		order.ID, cached, formatAmount(current), cached != formatAmount(current), cacheTTL))
+
+	// Delayed double delete: the writer removes the key again after the reader's backfill window.
+	if err := cache.Del(ctx, key); err != nil {
+		return err
+	}
+	_, ok, err := cache.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	res.Notes = append(res.Notes, fmt.Sprintf("after delayed second delete: cache_hit=%v, next read reloads %s", ok, formatAmount(current)))
+	return nil
+}
+
+func loadOrderAmount(ctx context.Context, db *gorm.DB, id uint) (float64, error) {
+	var amount float64
+	err := db.WithContext(ctx).Raw("SELECT total_amount FROM orders WHERE id = ?", id).Row().Scan(&amount)
+	return amount, err
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func averageDuration(total time.Duration, n int) time.Duration {
+	if n == 0 {
+		return 0
+	}
+	return total / time.Duration(n)
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	contactSampleRows   = 200000
+	charsetJoinCustomer = 4242
+)
+
+// contactTables maps each contact table to the character set of its phone column;
+// orders.phone uses the utf8mb4 database default.
+var contactTables = []struct {
+	name    string
+	charset string
+}{
+	{"customer_contacts_legacy", "CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci"},
+	{"customer_contacts", "CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci"},
+}
+
+// charsetMariaDB gates the charset comparison on MariaDB: utf8mb4_0900_ai_ci exists there as
+// an alias from 11.4.5, and the default utf8mb4 collation of orders.phone differs from it.
+var charsetMariaDB = MariaDBSupport{
+	MinVersion: "11.4.5",
+	Note:       "orders.phone uses MariaDB's default utf8mb4 collation, not utf8mb4_0900_ai_ci, so the matching variant may still convert",
+}
+
+func charsetScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "字符集隐式转换对比",
+			Name:        "字符集不一致的关联",
+			Description: "联系人表 phone 为 utf8mb3，与 orders.phone(utf8mb4) 关联时被 CONVERT，被驱动表索引失效。",
+			Query: "SELECT o.id, c.id FROM orders o JOIN customer_contacts_legacy c ON c.phone = o.phone " +
+				"WHERE o.customer_id = ?",
+			Args:    []interface{}{charsetJoinCustomer},
+			Setup:   ensureContactTables,
+			MariaDB: charsetMariaDB,
+		},
+		{
+			Type:        "字符集隐式转换对比",
+			Name:        "字符集一致的关联",
+			Description: "联系人表改为与 orders 相同的 utf8mb4_0900_ai_ci，关联条件可直接走 phone 索引。",
+			Query: "SELECT o.id, c.id FROM orders o JOIN customer_contacts c ON c.phone = o.phone " +
+				"WHERE o.customer_id = ?",
+			Args:    []interface{}{charsetJoinCustomer},
+			Setup:   ensureContactTables,
+			MariaDB: charsetMariaDB,
+		},
+	}
+}
+
+// ensureContactTables creates both contact tables and copies the same phone sample into each.
+func ensureContactTables(ctx context.Context, db *gorm.DB) error {
+	for _, t := range contactTables {
+		ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			customer_name VARCHAR(64) NOT NULL,
+			phone VARCHAR(32) %s NOT NULL,
+			INDEX idx_%s_phone (phone)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`, t.name, t.charset, t.name)
+		if err := db.WithContext(ctx).Exec(ddl).Error; err != nil {
+			return fmt.Errorf("create %s: %w", t.name, err)
+		}
+
+		var existing int64
+		if err := db.WithContext(ctx).Table(t.name).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			continue
+		}
+		fill := fmt.Sprintf("INSERT INTO %s (customer_name, phone) SELECT customer_name, phone FROM orders ORDER BY id LIMIT ?", t.name)
+		if err := db.WithContext(ctx).Exec(fill, contactSampleRows).Error; err != nil {
+			return fmt.Errorf("fill %s: %w", t.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	connChurnQuery      = "SELECT status FROM orders WHERE id = ?"
+	connChurnIterations = 500
+)
+
+// connMode is how each query of the connection churn scenarios gets its connection.
+type connMode int
+
+const (
+	connPooled connMode = iota
+	connFreshPlain
+	connFreshTLS
+)
+
+// connectionScenarios run the same primary-key lookup many times, reusing pooled connections
+// or opening a new one per query, to show how much of a "slow query" can be connection setup.
+func connectionScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "连接开销",
+			Name:        "连接池复用",
+			Description: fmt.Sprintf("同一条主键查询执行 %d 次，复用连接池里的长连接：每次只有一个网络往返，服务端执行时间可以忽略。", connChurnIterations),
+			Query:       connChurnQuery,
+			Args:        []interface{}{1},
+			Run:         runConnectionChurn(connPooled),
+		},
+		{
+			Type:        "连接开销",
+			Name:        "每次查询新建连接（明文）",
+			Description: "每次查询前新建连接、查完即关：TCP 握手、MySQL 握手与认证（caching_sha2_password）都算在这条查询的延迟里，慢查询日志却只记录执行时间，看不到这部分开销。",
+			Query:       connChurnQuery,
+			Args:        []interface{}{1},
+			Run:         runConnectionChurn(connFreshPlain),
+		},
+		{
+			Type:        "连接开销",
+			Name:        "每次查询新建连接（TLS）",
+			Description: "同上但连接走 TLS：额外的 TLS 握手（证书交换与密钥协商）让建连更贵，短连接 + TLS 是“查询很快但接口很慢”的常见原因。",
+			Query:       connChurnQuery,
+			Args:        []interface{}{1},
+			Run:         runConnectionChurn(connFreshTLS),
+		},
+	}
+}
+
+func runConnectionChurn(mode connMode) func(context.Context, *gorm.DB, *ScenarioResult) error {
+	return func(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+		minID, maxID, err := orderIDRange(ctx, db)
+		if err != nil {
+			return err
+		}
+		target, err := db.DB()
+		if err != nil {
+			return err
+		}
+		if mode != connPooled {
+			target, err = openVariant(db, func(cfg *mysql.Config) {
+				if mode == connFreshTLS {
+					cfg.TLSConfig, cfg.TLS = "skip-verify", &tls.Config{InsecureSkipVerify: true}
+				} else {
+					cfg.TLSConfig, cfg.TLS = "false", nil
+				}
+			})
+			if err != nil {
+				return err
+			}
+			defer target.Close()
+			// No idle connections: every query dials, authenticates and hangs up again.
+			target.SetMaxIdleConns(-1)
+		}
+
+		rnd := rand.New(rand.NewSource(1327))
+		connectionsBefore, _ := globalStatusInt(ctx, db, "Connections")
+		latencies := make([]time.Duration, 0, connChurnIterations)
+		start := time.Now()
+		for i := 0; i < connChurnIterations; i++ {
+			id := minID + uint64(rnd.Int63n(int64(maxID-minID+1)))
+			queryStart := time.Now()
+			var status sql.NullString
+			err := target.QueryRowContext(ctx, connChurnQuery, id).Scan(&status)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			latencies = append(latencies, time.Since(queryStart))
+		}
+		res.Duration = time.Since(start)
+		res.RowCount = connChurnIterations
+		connectionsAfter, _ := globalStatusInt(ctx, db, "Connections")
+
+		stats := summarizeLatencies(latencies)
+		res.Notes = append(res.Notes,
+			fmt.Sprintf("%d queries: avg %s, p50 %s, p95 %s, max %s", connChurnIterations,
+				stats.Avg.Round(time.Microsecond), stats.P50.Round(time.Microsecond), stats.P95.Round(time.Microsecond), stats.Max.Round(time.Microsecond)),
+			fmt.Sprintf("new server connections: %d (Connections status delta)", connectionsAfter-connectionsBefore))
+		if mode == connFreshTLS {
+			var cipher string
+			if err := target.QueryRowContext(ctx, "SELECT VARIABLE_VALUE FROM performance_schema.session_status WHERE VARIABLE_NAME = 'Ssl_cipher'").Scan(&cipher); err == nil {
+				res.Notes = append(res.Notes, "TLS cipher: "+cipher)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func countScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "COUNT(*) 策略对比",
+			Name:        "强制聚簇索引计数",
+			Description: "InnoDB 不保存精确行数，FORCE INDEX(PRIMARY) 要遍历包含整行数据的聚簇索引。",
+			Query:       "SELECT COUNT(*) FROM orders FORCE INDEX (PRIMARY)",
+		},
+		{
+			Type:        "COUNT(*) 策略对比",
+			Name:        "窄二级索引计数",
+			Description: "指定最窄的 customer_id 二级索引，每页容纳更多记录，扫描的页数远少于聚簇索引。",
+			Query:       "SELECT COUNT(*) FROM orders FORCE INDEX (idx_orders_customer_id)",
+		},
+		{
+			Type:        "COUNT(*) 策略对比",
+			Name:        "优化器自选计数",
+			Description: "不加提示时优化器自己挑选代价最低的索引，可在 EXPLAIN 的 key 列确认选择。",
+			Query:       "SELECT COUNT(*) FROM orders",
+		},
+		{
+			Type:        "COUNT(*) 策略对比",
+			Name:        "information_schema 估算",
+			Description: "读取 TABLE_ROWS 统计值，毫秒级返回但只是采样估算，误差可达 40% 以上。",
+			Query:       "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'orders'",
+			Run:         runApproximateCount,
+		},
+		{
+			Type:        "COUNT(*) 策略对比",
+			Name:        "EXPLAIN rows 估算",
+			Description: "借助 EXPLAIN SELECT * 的 rows 列获得优化器估算值，同样不需要扫描数据。",
+			Run:         runExplainCount,
+		},
+	}
+}
+
+func runApproximateCount(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+	start := time.Now()
+	var estimate int64
+	if err := db.WithContext(ctx).
+		Raw("SELECT COALESCE(TABLE_ROWS, 0) FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'orders'").
+		Row().Scan(&estimate); err != nil {
+		return err
+	}
+	res.Duration = time.Since(start)
+	res.RowCount = 1
+	res.Notes = append(res.Notes, fmt.Sprintf("estimated rows=%d", estimate))
+	return nil
+}
+
+func runExplainCount(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+	start := time.Now()
+	var plan []struct {
+		Rows int64
+	}
+	if err := db.WithContext(ctx).Raw("EXPLAIN SELECT * FROM orders").Scan(&plan).Error; err != nil {
+		return err
+	}
+	res.Duration = time.Since(start)
+	res.RowCount = 1
+	if len(plan) > 0 {
+		res.Notes = append(res.Notes, fmt.Sprintf("estimated rows=%d", plan[0].Rows))
+	}
+	return nil
+}
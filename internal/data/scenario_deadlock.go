@@ -0,0 +1,144 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	deadlockQuery = "UPDATE orders SET note = 'deadlock' WHERE id = ?"
+	// errLockDeadlock is ER_LOCK_DEADLOCK.
+	errLockDeadlock = 1213
+)
+
+// deadlockScenarios provoke a deadlock on purpose: two transactions update the same two
+// orders in opposite order. Both roll back, so no data changes.
+func deadlockScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "死锁",
+			Name:        "两个事务以相反顺序更新同两行",
+			Description: "事务 A 先更新最小 id 的订单、事务 B 先更新最大 id 的订单，两者都拿到第一把锁后再去更新对方已锁住的那一行，形成环形等待；InnoDB 死锁检测立即回滚其中一个（ERROR 1213），然后从 SHOW ENGINE INNODB STATUS 的 LATEST DETECTED DEADLOCK 段还原现场。",
+			Query:       deadlockQuery,
+			Args:        []interface{}{1},
+			Run:         runDeadlock,
+			TiDB:        TiDBSupport{Note: "TiDB has no SHOW ENGINE INNODB STATUS; the deadlock is recorded in information_schema.DEADLOCKS"},
+		},
+	}
+}
+
+func runDeadlock(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+	var detect string
+	if err := db.WithContext(ctx).Raw("SELECT @@innodb_deadlock_detect").Row().Scan(&detect); err == nil && detect == "0" {
+		res.Warnings = append(res.Warnings, "innodb_deadlock_detect is OFF: the deadlock is only broken by innodb_lock_wait_timeout")
+	}
+	minID, maxID, err := orderIDRange(ctx, db)
+	if err != nil {
+		return err
+	}
+	if minID == maxID {
+		return errors.New("need at least two orders")
+	}
+
+	// Each side takes its first lock, waits until the other side holds its own, then goes for
+	// the other row. The second of the two waits closes the cycle.
+	var (
+		firstLocks sync.WaitGroup
+		sides      sync.WaitGroup
+		errs       = make([]error, 2)
+		order      = [2][2]uint64{{minID, maxID}, {maxID, minID}}
+	)
+	firstLocks.Add(2)
+	for i := range order {
+		sides.Add(1)
+		go func() {
+			defer sides.Done()
+			tx := db.WithContext(ctx).Begin()
+			if tx.Error != nil {
+				errs[i] = tx.Error
+				firstLocks.Done()
+				return
+			}
+			defer tx.Rollback()
+			errs[i] = tx.Exec(deadlockQuery, order[i][0]).Error
+			firstLocks.Done()
+			if errs[i] != nil {
+				return
+			}
+			firstLocks.Wait()
+			errs[i] = tx.Exec(deadlockQuery, order[i][1]).Error
+		}()
+	}
+	sides.Wait()
+
+	victims := 0
+	for i, err := range errs {
+		var myErr *mysql.MySQLError
+		switch {
+		case err == nil:
+			res.Notes = append(res.Notes, fmt.Sprintf("transaction %c completed once the other was rolled back", 'A'+i))
+		case errors.As(err, &myErr) && myErr.Number == errLockDeadlock:
+			victims++
+			res.Notes = append(res.Notes, fmt.Sprintf("transaction %c was chosen as the victim: %v", 'A'+i, err))
+		default:
+			return fmt.Errorf("transaction %c: %w", 'A'+i, err)
+		}
+	}
+	if victims == 0 {
+		return errors.New("no deadlock was reported")
+	}
+
+	section, err := latestDeadlock(ctx, db)
+	if err != nil {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("SHOW ENGINE INNODB STATUS (needs PROCESS): %v", err))
+		return nil
+	}
+	res.Notes = append(res.Notes, section...)
+	return nil
+}
+
+// latestDeadlock returns the LATEST DETECTED DEADLOCK section of SHOW ENGINE INNODB STATUS,
+// without the physical record dumps, one line per note.
+func latestDeadlock(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var typ, name, status string
+	if err := db.WithContext(ctx).Raw("SHOW ENGINE INNODB STATUS").Row().Scan(&typ, &name, &status); err != nil {
+		return nil, err
+	}
+	_, section, found := strings.Cut(status, "LATEST DETECTED DEADLOCK\n------------------------\n")
+	if !found {
+		return nil, errors.New("no deadlock recorded")
+	}
+	section, _, _ = strings.Cut(section, "\n------------\nTRANSACTIONS")
+	return formatDeadlock(section), nil
+}
+
+// recordField matches the per-field lines of a physical record dump.
+var recordField = regexp.MustCompile(`^\d+: (len \d+|SQL NULL)`)
+
+// formatDeadlock keeps the lines that tell the story: who ran what, which locks each held and
+// waited for, and who was rolled back. Record dumps ("Record lock, heap no", " 0: len 8; hex ...")
+// are dropped.
+func formatDeadlock(section string) []string {
+	var lines []string
+	for _, line := range strings.Split(section, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "",
+			strings.HasPrefix(line, "Record lock,"),
+			recordField.MatchString(line):
+			continue
+		case strings.HasPrefix(line, "*** "):
+			lines = append(lines, "deadlock "+strings.TrimPrefix(line, "*** "))
+		default:
+			lines = append(lines, "    "+line)
+		}
+	}
+	return lines
+}
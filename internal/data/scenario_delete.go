@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The two variants delete disjoint total_amount bands of equal width (~5% of orders each), so
+// both remove comparable slices; each rewinds the seed checkpoint so seeding tops orders back
+// up on the next run.
+const (
+	bigDeleteQuery   = "DELETE FROM orders WHERE total_amount >= 10 AND total_amount < 60"
+	chunkDeleteQuery = "DELETE FROM orders WHERE id BETWEEN ? AND ? AND total_amount >= 60 AND total_amount < 110"
+	deleteChunkSize  = 10000
+)
+
+// deleteScenarios are destructive and only run with RunConfig.Destructive.
+func deleteScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "大批量删除对比",
+			Name:        "单条 DELETE 无索引条件",
+			Description: "total_amount 没有可用索引，一条 DELETE 在一个事务里扫描整张表，扫描过的每一行都加 next-key 锁直到提交，undo 一次性堆积。",
+			Query:       bigDeleteQuery,
+			Run:         runBigDelete,
+		},
+		{
+			Type:        "大批量删除对比",
+			Name:        "按主键区间分批 DELETE",
+			Description: fmt.Sprintf("按主键每 %d 个 id 一批、每批单独提交，每个事务只锁住当前区间，锁持有时间短，purge 可以边删边回收 undo。", deleteChunkSize),
+			Query:       chunkDeleteQuery,
+			Args:        []interface{}{1, deleteChunkSize},
+			Run:         runChunkedDelete,
+		},
+	}
+}
+
+func runBigDelete(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+	historyBefore, _ := innodbMetric(ctx, db, "trx_rseg_history_len")
+	start := time.Now()
+	stats, err := execInTx(ctx, db, true, bigDeleteQuery)
+	if err != nil {
+		return err
+	}
+	res.Duration = time.Since(start)
+	res.RowCount = stats.affected
+	if err := rewindSeedCheckpoint(ctx, db, res.RowCount); err != nil {
+		return err
+	}
+	historyAfter, _ := innodbMetric(ctx, db, "trx_rseg_history_len")
+	res.Notes = append(res.Notes,
+		fmt.Sprintf("1 transaction: rows_locked=%d lock_structs=%d, locks held %s", stats.rowsLocked, stats.lockStructs, stats.held.Round(time.Millisecond)),
+		fmt.Sprintf("undo history length %d -> %d after commit", historyBefore, historyAfter))
+	return nil
+}
+
+func runChunkedDelete(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+	minID, maxID, err := orderIDRange(ctx, db)
+	if err != nil {
+		return err
+	}
+	historyBefore, _ := innodbMetric(ctx, db, "trx_rseg_history_len")
+	historyPeak := historyBefore
+	var (
+		worst txStats
+		txns  int
+	)
+	start := time.Now()
+	for lo := minID; lo <= maxID; lo += deleteChunkSize {
+		stats, err := execInTx(ctx, db, true, chunkDeleteQuery, lo, lo+deleteChunkSize-1)
+		if err != nil {
+			// Earlier chunks are committed; they still have to be seeded again.
+			rewindSeedCheckpoint(ctx, db, res.RowCount)
+			return fmt.Errorf("chunk starting at id %d: %w", lo, err)
+		}
+		txns++
+		res.RowCount += stats.affected
+		worst.rowsLocked = max(worst.rowsLocked, stats.rowsLocked)
+		worst.lockStructs = max(worst.lockStructs, stats.lockStructs)
+		worst.held = max(worst.held, stats.held)
+		if history, err := innodbMetric(ctx, db, "trx_rseg_history_len"); err == nil {
+			historyPeak = max(historyPeak, history)
+		}
+	}
+	res.Duration = time.Since(start)
+	if err := rewindSeedCheckpoint(ctx, db, res.RowCount); err != nil {
+		return err
+	}
+	historyAfter, _ := innodbMetric(ctx, db, "trx_rseg_history_len")
+	res.Notes = append(res.Notes,
+		fmt.Sprintf("%d transactions, worst: rows_locked=%d lock_structs=%d, locks held %s", txns, worst.rowsLocked, worst.lockStructs, worst.held.Round(time.Millisecond)),
+		fmt.Sprintf("undo history length %d -> %d after the last commit (peak %d)", historyBefore, historyAfter, historyPeak))
+	return nil
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// descIndexMinVersion is the first release that stores DESC index keys in descending order; older servers parse and ignore DESC.
+	descIndexMinVersion = "8.0.1"
+	descOrderQuery      = "SELECT id, created_at FROM orders ORDER BY created_at DESC LIMIT 10000"
+)
+
+var descCounters = []string{"Handler_read_first", "Handler_read_last", "Handler_read_next", "Handler_read_prev"}
+
+func descIndexScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "降序索引对比",
+			Name:        "升序索引反向扫描",
+			Description: "只有升序的 created_at 索引，ORDER BY created_at DESC 需从索引尾部反向遍历（Backward index scan），InnoDB 反向翻页比正向略慢。",
+			Query:       descOrderQuery,
+			Counters:    descCounters,
+			ExpectPlan:  []PlanExpectation{{Table: "orders", Key: "idx_orders_created_at", Extra: "Backward index scan"}},
+		},
+		{
+			Type:        "降序索引对比",
+			Name:        "降序索引正向扫描",
+			Description: "created_at DESC 索引的物理顺序与 ORDER BY 一致，正向读取前 n 项即可（Handler_read_next 取代 Handler_read_prev）。",
+			Query:       descOrderQuery,
+			MinVersion:  descIndexMinVersion,
+			Setup:       ensureDescCreatedAtIndex,
+			// Invisible so the ascending variant above keeps its backward scan.
+			OptimizerSwitch: "use_invisible_indexes=on",
+			Counters:        descCounters,
+			ExpectPlan:      []PlanExpectation{{Table: "orders", Key: "idx_orders_created_at_desc"}},
+		},
+	}
+}
+
+func ensureDescCreatedAtIndex(ctx context.Context, db *gorm.DB) error {
+	if db.WithContext(ctx).Migrator().HasIndex(&Order{}, "idx_orders_created_at_desc") {
+		return nil
+	}
+	return db.WithContext(ctx).
+		Exec("CREATE INDEX idx_orders_created_at_desc ON orders (created_at DESC) INVISIBLE").Error
+}
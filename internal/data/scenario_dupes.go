@@ -0,0 +1,81 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	dupesTable      = "order_phone_amounts"
+	dupesSampleRows = 100000
+	// dupesEvery copies every Nth sampled row once more, planting ~2% exact duplicates.
+	dupesEvery           = 50
+	windowFuncMinVersion = "8.0.2"
+)
+
+// windowFuncMariaDB gates the window function scenarios on MariaDB, which added them in 10.2.
+var windowFuncMariaDB = MariaDBSupport{MinVersion: "10.2.0"}
+
+func duplicateScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "重复数据排查对比",
+			Name:        "自关联找重复",
+			Description: "表与自身按 phone + total_amount 关联、排除同一行，每行都要按 phone 索引查一次再比较金额，结果还需 DISTINCT 去重。",
+			Query: "SELECT DISTINCT a.id, a.phone, a.total_amount FROM " + dupesTable + " a JOIN " + dupesTable + " b " +
+				"ON b.phone = a.phone AND b.total_amount = a.total_amount AND b.id <> a.id",
+			Setup: ensureDuplicateRows,
+		},
+		{
+			Type:        "重复数据排查对比",
+			Name:        "GROUP BY + HAVING",
+			Description: "一次扫描按 (phone, total_amount) 分组计数，借助内部临时表聚合；只返回重复的组，拿不到每一行的 id。",
+			Query: "SELECT phone, total_amount, COUNT(*) AS copies FROM " + dupesTable +
+				" GROUP BY phone, total_amount HAVING COUNT(*) > 1",
+			Setup: ensureDuplicateRows,
+		},
+		{
+			Type:        "重复数据排查对比",
+			Name:        "窗口函数",
+			Description: "COUNT(*) OVER (PARTITION BY phone, total_amount) 一次扫描加一次排序，既能筛出重复又保留每行 id，便于后续按 id 清理。",
+			Query: "SELECT id, phone, total_amount FROM (SELECT id, phone, total_amount, " +
+				"COUNT(*) OVER (PARTITION BY phone, total_amount) AS copies FROM " + dupesTable + ") t WHERE copies > 1",
+			MinVersion: windowFuncMinVersion,
+			MariaDB:    windowFuncMariaDB,
+			Setup:      ensureDuplicateRows,
+		},
+	}
+}
+
+// ensureDuplicateRows copies a sample of orders into dupesTable and plants exact phone+amount duplicates.
+func ensureDuplicateRows(ctx context.Context, db *gorm.DB) error {
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+		phone VARCHAR(32) NOT NULL,
+		total_amount DOUBLE NOT NULL,
+		created_at DATETIME NOT NULL,
+		INDEX idx_%s_phone (phone)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`, dupesTable, dupesTable)
+	if err := db.WithContext(ctx).Exec(ddl).Error; err != nil {
+		return fmt.Errorf("create %s: %w", dupesTable, err)
+	}
+
+	var existing int64
+	if err := db.WithContext(ctx).Table(dupesTable).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+	fill := fmt.Sprintf("INSERT INTO %s (phone, total_amount, created_at) SELECT phone, total_amount, created_at FROM orders WHERE customer_id <> ? ORDER BY id LIMIT ?", dupesTable)
+	if err := db.WithContext(ctx).Exec(fill, coveringCustomerID, dupesSampleRows).Error; err != nil {
+		return fmt.Errorf("fill %s: %w", dupesTable, err)
+	}
+	plant := fmt.Sprintf("INSERT INTO %s (phone, total_amount, created_at) SELECT phone, total_amount, ? FROM %s WHERE id %% ? = 0", dupesTable, dupesTable)
+	if err := db.WithContext(ctx).Exec(plant, datasetNow(ctx), dupesEvery).Error; err != nil {
+		return fmt.Errorf("plant duplicates: %w", err)
+	}
+	return nil
+}
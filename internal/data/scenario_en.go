@@ -0,0 +1,296 @@
+package data
+
+import (
+	"fmt"
+
+	"mysql-slow-query-lab/internal/i18n"
+)
+
+// scenarioText is the English name and description of a built-in scenario.
+type scenarioText struct {
+	Name        string
+	Description string
+}
+
+// typeEnglish translates the built-in scenario types.
+var typeEnglish = i18n.Catalog{
+	"回表对比":                   "Back to table",
+	"索引字段做函数操作对比":            "Functions on indexed columns",
+	"类型匹配对比":                 "Type mismatch",
+	"INSERT ... SELECT 归档对比": "INSERT ... SELECT archiving",
+	"缓存旁路对比":                 "Cache-aside",
+	"字符集隐式转换对比":              "Charset conversion",
+	"连接开销":                   "Connection overhead",
+	"COUNT(*) 策略对比":          "COUNT(*) strategies",
+	"死锁":                     "Deadlock",
+	"大批量删除对比":                "Bulk delete",
+	"降序索引对比":                 "Descending index",
+	"重复数据排查对比":               "Finding duplicates",
+	"全文索引 vs LIKE 对比":        "Full-text index vs LIKE",
+	"间隙锁与隔离级别":               "Gap locks and isolation",
+	"优化器提示对比":                "Optimizer hints",
+	"直方图统计对比":                "Histograms",
+	"索引条件下推对比":               "Index condition pushdown",
+	"JSON 字段查询对比":            "JSON queries",
+	"每组最新记录对比":               "Latest row per group",
+	"行锁等待":                   "Row lock waits",
+	"MRR / BKA 对比":           "MRR / BKA",
+	"分区裁剪对比":                 "Partition pruning",
+	"连接池耗尽":                  "Pool exhaustion",
+	"前缀索引选择性对比":              "Prefix index selectivity",
+	"预处理语句":                  "Prepared statements",
+	"随机抽样对比":                 "Random sampling",
+	"后缀模糊查询对比":               "Suffix LIKE",
+	"索引跳跃扫描对比":               "Index skip scan",
+	"空间索引对比":                 "Spatial index",
+	"统计信息过期对比":               "Stale statistics",
+	"UNION 去重对比":             "UNION deduplication",
+	"热点键 upsert 争用":          "Hot-key upsert contention",
+	RegisteredType:           "Custom scenarios",
+	ReplayType:               "Slow log replay",
+}
+
+// scenarioEnglish translates the built-in scenarios, keyed by their Chinese type and name;
+// names are only unique within a type.
+var scenarioEnglish = map[[2]string]scenarioText{
+	{"回表对比", "索引回表查询"}: {"Index lookup with table access",
+		"Locates rows through the customer_id secondary index, then fetches each full row from the clustered index."},
+	{"回表对比", "覆盖索引查询"}: {"Covering index",
+		"Same condition selecting only customer_id: answered from the secondary index alone, no table access."},
+
+	{"索引字段做函数操作对比", "函数包裹索引列"}: {"Function on indexed column",
+		"DATE(created_at) wraps the column in a function, so its index cannot be used."},
+	{"索引字段做函数操作对比", "范围查询命中索引"}: {"Range condition uses index",
+		"The same date condition written as a range lets the optimizer seek on the created_at index."},
+	{"索引字段做函数操作对比", "生成列索引"}: {"Generated column index",
+		"A STORED generated column created_date = DATE(created_at) with its own index; equality on the date hits it directly."},
+	{"索引字段做函数操作对比", "函数索引"}: {"Functional index",
+		"MySQL 8.0.13+ can index the expression (DATE(created_at)) itself, so the original function form uses an index too."},
+	{"索引字段做函数操作对比", "关联条件包裹函数"}: {"Function in join condition",
+		"ON DATE(o.created_at) = c.signup_date wraps the inner table's column in a function; every customer scans all of orders."},
+	{"索引字段做函数操作对比", "关联条件改写为范围"}: {"Join condition as range",
+		"Rewritten as o.created_at >= c.signup_date AND < c.signup_date + 1 day, each customer does a range lookup on the created_at index."},
+	{"索引字段做函数操作对比", "UPDATE 函数包裹索引列"}: {"UPDATE with function on indexed column",
+		"UPDATE ... WHERE DATE(created_at) = ? cannot seek on the index either and scans the table; under REPEATABLE READ every scanned row is locked, so nothing can write to the table until commit."},
+	{"索引字段做函数操作对比", "UPDATE 范围条件"}: {"UPDATE with range condition",
+		"Rewritten as a half-open created_at range, the UPDATE seeks on the index and locks only that day's index records and their gaps."},
+
+	{"类型匹配对比", "类型不匹配隐式转换"}: {"Implicit type conversion",
+		"phone is a string column compared with a numeric literal, which forces an implicit conversion and disables the index."},
+	{"类型匹配对比", "类型匹配命中索引"}: {"Matching type uses index",
+		"The same phone condition with a string literal hits the index directly."},
+
+	{"INSERT ... SELECT 归档对比", "无索引条件归档"}: {"Archive on unindexed condition",
+		fmt.Sprintf("Copies the last %d days of orders into an archive table with a condition that cannot use an index. Under REPEATABLE READ, INSERT ... SELECT takes a shared lock on every source row it scans (so binlog replay gives the same result); a full scan means no order can be updated or deleted until commit.", archiveWindowDays)},
+	{"INSERT ... SELECT 归档对比", "索引范围条件归档"}: {"Archive on indexed range",
+		"The same rows read through a created_at index range lock only the index records in that range; other orders keep accepting writes."},
+
+	{"缓存旁路对比", "直接读库"}: {"Read from database",
+		fmt.Sprintf("The hot customer's summary aggregates 1M order rows on every read, %d reads in a row.", cacheReadIterations)},
+	{"缓存旁路对比", "Cache-Aside 读取"}: {"Cache-aside read",
+		"Reads Redis first, falls back to MySQL on a miss and fills the cache; later reads are cache hits."},
+	{"缓存旁路对比", "并发更新导致脏缓存"}: {"Stale cache from concurrent update",
+		"A writer updates the database and deletes the key after a reader's fallback but before its fill; the old value is written back and served until it expires."},
+
+	{"字符集隐式转换对比", "字符集不一致的关联"}: {"Join across charsets",
+		"The contacts table's phone is utf8mb3; joined to orders.phone (utf8mb4) it gets CONVERTed and the inner table's index is lost."},
+	{"字符集隐式转换对比", "字符集一致的关联"}: {"Join with matching charset",
+		"With contacts switched to the same utf8mb4_0900_ai_ci as orders, the join condition uses the phone index directly."},
+
+	{"连接开销", "连接池复用"}: {"Pooled connections",
+		fmt.Sprintf("The same primary key lookup %d times over the pool's long-lived connections: one network round trip each, negligible server time.", connChurnIterations)},
+	{"连接开销", "每次查询新建连接（明文）"}: {"New connection per query (plaintext)",
+		"Connects before and disconnects after every query: TCP handshake, MySQL handshake and authentication (caching_sha2_password) all count toward the query's latency, yet the slow log records only execution time."},
+	{"连接开销", "每次查询新建连接（TLS）"}: {"New connection per query (TLS)",
+		"Same, over TLS: the extra TLS handshake (certificate exchange and key agreement) makes connecting costlier; short-lived connections plus TLS are a common cause of fast queries behind slow endpoints."},
+
+	{"COUNT(*) 策略对比", "强制聚簇索引计数"}: {"Count on clustered index",
+		"InnoDB keeps no exact row count; FORCE INDEX(PRIMARY) walks the clustered index holding the full rows."},
+	{"COUNT(*) 策略对比", "窄二级索引计数"}: {"Count on narrow secondary index",
+		"Forces the narrow customer_id secondary index: more entries per page, far fewer pages than the clustered index."},
+	{"COUNT(*) 策略对比", "优化器自选计数"}: {"Count, optimizer's choice",
+		"Without hints the optimizer picks the cheapest index itself; the key column of EXPLAIN shows which."},
+	{"COUNT(*) 策略对比", "information_schema 估算"}: {"information_schema estimate",
+		"Reads the TABLE_ROWS statistic: returns in milliseconds but is a sampled estimate that can be off by 40% or more."},
+	{"COUNT(*) 策略对比", "EXPLAIN rows 估算"}: {"EXPLAIN rows estimate",
+		"Takes the optimizer's estimate from the rows column of EXPLAIN SELECT *, again without scanning data."},
+
+	{"死锁", "两个事务以相反顺序更新同两行"}: {"Two transactions update the same rows in opposite order",
+		"Transaction A updates the lowest-id order first, transaction B the highest; each then updates the row the other holds, forming a cycle. InnoDB's deadlock detection rolls one back immediately (ERROR 1213), and the scene is reconstructed from the LATEST DETECTED DEADLOCK section of SHOW ENGINE INNODB STATUS."},
+
+	{"大批量删除对比", "单条 DELETE 无索引条件"}: {"Single DELETE on unindexed condition",
+		"total_amount has no usable index: one DELETE scans the whole table in one transaction, holds a next-key lock on every scanned row until commit and piles up undo at once."},
+	{"大批量删除对比", "按主键区间分批 DELETE"}: {"DELETE in primary key chunks",
+		fmt.Sprintf("Deletes %d ids at a time by primary key, committing each chunk: every transaction locks only its range briefly, and purge reclaims undo as the delete goes.", deleteChunkSize)},
+
+	{"降序索引对比", "升序索引反向扫描"}: {"Backward scan of ascending index",
+		"Only an ascending created_at index exists, so ORDER BY created_at DESC walks it from the end (Backward index scan); InnoDB pages backward slightly slower than forward."},
+	{"降序索引对比", "降序索引正向扫描"}: {"Forward scan of descending index",
+		"A created_at DESC index stores keys in ORDER BY order, so reading the first n entries forward suffices (Handler_read_next instead of Handler_read_prev)."},
+
+	{"重复数据排查对比", "自关联找重复"}: {"Self-join",
+		"Joins the table to itself on phone + total_amount excluding the same row: every row does a phone index lookup and compares amounts, and the result still needs DISTINCT."},
+	{"重复数据排查对比", "GROUP BY + HAVING"}: {"GROUP BY + HAVING",
+		"One scan grouping by (phone, total_amount) through an internal temporary table; returns only the duplicate groups, not each row's id."},
+	{"重复数据排查对比", "窗口函数"}: {"Window function",
+		"COUNT(*) OVER (PARTITION BY phone, total_amount) takes one scan and one sort, finds duplicates and keeps every row's id for cleanup by id."},
+
+	{"全文索引 vs LIKE 对比", "LIKE '%关键词%'"}: {"LIKE '%keyword%'",
+		"A leading wildcard rules out every B+Tree index: 200k reviews are substring-matched row by row, and substrings also match words like refunds and prerefund."},
+	{"全文索引 vs LIKE 对比", "MATCH ... AGAINST"}: {"MATCH ... AGAINST",
+		"The FULLTEXT inverted index finds documents by word and reads only the list containing refund; the scenario rebuilds the index on every run and the note line logs how long that took."},
+	{"全文索引 vs LIKE 对比", "多个 LIKE 组合"}: {"Several LIKEs combined",
+		"Requiring two words takes two LIKE conditions, scanning every row's text twice."},
+	{"全文索引 vs LIKE 对比", "布尔模式 +词 +词"}: {"Boolean mode +word +word",
+		"'+damaged +refund' IN BOOLEAN MODE intersects the two words' document lists in the inverted index."},
+
+	{"间隙锁与隔离级别", "REPEATABLE READ 范围 UPDATE"}: {"Range UPDATE under REPEATABLE READ",
+		"Under REPEATABLE READ a range UPDATE takes next-key locks (record + preceding gap) on the index records it scans and locks the gap before the first record past the range, preventing phantoms: every insert into (10, 40) blocks, including 15 and 35 that lie outside the range itself."},
+	{"间隙锁与隔离级别", "READ COMMITTED 范围 UPDATE"}: {"Range UPDATE under READ COMMITTED",
+		"READ COMMITTED takes no gap locks and keeps only the matching records locked (non-matching rows are released after evaluation), so all concurrent inserts complete at once; the price is that rereading the range in the same transaction can see phantom rows."},
+
+	{"优化器提示对比", "热点客户默认计划"}: {"Hot customer, default plan",
+		"The hot customer owns half of the data; shows how the optimizer chooses between secondary index lookups and a full scan on its own."},
+	{"优化器提示对比", "NO_INDEX 禁用二级索引"}: {"NO_INDEX disables secondary index",
+		"NO_INDEX gives up the customer_id index and scans the clustered index sequentially, avoiding a million random table lookups."},
+	{"优化器提示对比", "默认关联顺序"}: {"Default join order",
+		"customers joined to orders with filters on both; the optimizer picks the driving table."},
+	{"优化器提示对比", "JOIN_ORDER 强制大表驱动"}: {"JOIN_ORDER forces large table first",
+		"JOIN_ORDER(o, c) scans orders first and looks up customers row by row, showing the cost of a bad join order."},
+	{"优化器提示对比", "IN 子查询默认半连接"}: {"IN subquery as semijoin",
+		"An IN subquery is rewritten to a semijoin by default; EXPLAIN shows the strategy chosen."},
+	{"优化器提示对比", "NO_SEMIJOIN 关闭半连接"}: {"NO_SEMIJOIN disables semijoin",
+		"NO_SEMIJOIN(@sub) falls back to subquery materialization or a per-row EXISTS check; compare plans and timings."},
+	{"优化器提示对比", "MAX_EXECUTION_TIME 熔断"}: {"MAX_EXECUTION_TIME cutoff",
+		"Adds MAX_EXECUTION_TIME(100) to a full scan with a function; the server aborts it after 100ms, expecting error 3024."},
+
+	{"直方图统计对比", "无直方图的行数估算"}: {"Row estimate without histogram",
+		"status/region have neither index nor histogram, so the optimizer guesses equality selectivity with a fixed ratio; the estimate is off by more than an order of magnitude from the actual ~0.2%."},
+	{"直方图统计对比", "建立直方图后的行数估算"}: {"Row estimate with histogram",
+		"ANALYZE TABLE ... UPDATE HISTOGRAM ON status, region records each value's real frequency; filtered moves close to the actual ratio, which joins use to pick a better driving table."},
+
+	{"索引条件下推对比", "ICP 开启"}: {"ICP on",
+		"phone LIKE '138%' is a range scan and '%8888' is pushed down to the storage engine to filter on the index, fetching only matching rows (Using index condition)."},
+	{"索引条件下推对比", "ICP 关闭"}: {"ICP off",
+		"With index_condition_pushdown off for the session, every index entry in the range fetches its full row first and the server layer filters afterwards."},
+
+	{"JSON 字段查询对比", "JSON_EXTRACT 无索引"}: {"JSON_EXTRACT without index",
+		"Filtering on metadata's campaign parses every row's JSON document before comparing: a full scan."},
+	{"JSON 字段查询对比", "生成列索引"}: {"Generated column index",
+		"An index on the virtual generated column campaign = metadata->>'$.campaign' turns equality into a direct ref lookup; JSON is parsed once, on write."},
+	{"JSON 字段查询对比", "JSON 数组无索引"}: {"JSON array without index",
+		"'vip' MEMBER OF (metadata->'$.tags') expands every row's tags array and compares each element."},
+	{"JSON 字段查询对比", "多值索引"}: {"Multi-valued index",
+		"A CAST(metadata->'$.tags' AS CHAR(16) ARRAY) multi-valued index has one entry per array element; MEMBER OF / JSON_CONTAINS / JSON_OVERLAPS use it directly."},
+
+	{"每组最新记录对比", "相关子查询"}: {"Correlated subquery",
+		"About 50k customers: every order row runs a dependent subquery for its customer's MAX(created_at), and the customer_id lookup still reads created_at from the table."},
+	{"每组最新记录对比", "分组最大值再关联"}: {"Group max, then join",
+		"GROUP BY customer_id computes MAX(created_at) into a derived table that is joined back to orders on (customer_id, created_at); ties on created_at return several rows."},
+	{"每组最新记录对比", "窗口函数 ROW_NUMBER"}: {"ROW_NUMBER window function",
+		"ROW_NUMBER() OVER (PARTITION BY customer_id ORDER BY created_at DESC, id DESC) with rn = 1 yields exactly one row per group but sorts every order."},
+	{"每组最新记录对比", "复合覆盖索引 + 分组最大值"}: {"Composite covering index + group max",
+		"With a (customer_id, created_at DESC, id) index the group max reads each group's first entry (loose index scan), and the join back becomes a ref lookup on a covering index."},
+
+	{"行锁等待", "SELECT ... FOR UPDATE 阻塞另一个会话"}: {"SELECT ... FOR UPDATE blocks another session",
+		fmt.Sprintf("Session A locks all of one customer's orders with SELECT ... FOR UPDATE and holds them for %s without committing; session B's update of the same orders has to wait. performance_schema.data_lock_waits shows who waits for whom and on which lock.", lockHoldFor)},
+	{"行锁等待", "innodb_lock_wait_timeout 超时"}: {"innodb_lock_wait_timeout expires",
+		fmt.Sprintf("Blocked by FOR UPDATE again, but session B sets innodb_lock_wait_timeout to %d s (default 50 s): once it runs out the statement fails with ERROR 1205 Lock wait timeout exceeded. The timeout rolls back only the statement; the transaction stays open and the application must decide to roll back or retry.", lockWaitTimeout)},
+
+	{"MRR / BKA 对比", "二级索引范围回表（MRR 关闭）"}: {"Secondary index range lookups (MRR off)",
+		"A customer_id range matches about 40k rows, fetched from the table one by one in index order: random primary key access."},
+	{"MRR / BKA 对比", "二级索引范围回表（MRR 开启）"}: {"Secondary index range lookups (MRR on)",
+		"MRR collects a batch of primary keys and sorts them before fetching, turning random IO into nearly sequential IO (Using MRR)."},
+	{"MRR / BKA 对比", "关联逐行查找（BKA 关闭）"}: {"Join with per-row lookups (BKA off)",
+		"customers drives orders: one customer_id index lookup plus table access per customer."},
+	{"MRR / BKA 对比", "关联批量查找（BKA 开启）"}: {"Join with batched lookups (BKA on)",
+		"The driving table's join keys go into the join buffer first and are handed to MRR in batches to look up the inner table (Batched Key Access)."},
+
+	{"分区裁剪对比", "分区键范围条件"}: {"Range on partition key",
+		"Partitioned monthly by RANGE COLUMNS(created_at); a half-open created_at range falls in the 2024-01 partition only, and the rest are pruned at optimization time."},
+	{"分区裁剪对比", "函数包裹分区键"}: {"Function on partition key",
+		"DATE_FORMAT(created_at, '%Y-%m') differs from the partitioning expression, so the optimizer cannot tell which partition matches and scans them all."},
+	{"分区裁剪对比", "非分区键条件"}: {"Condition on non-partition key",
+		"Filtering on customer_id is unrelated to the partition key: each partition does its own index lookup, costing more the more partitions there are."},
+
+	{"连接池耗尽", "连接池足够"}: {"Pool large enough",
+		fmt.Sprintf("%d concurrent requests each get a connection (limit %d): request latency ≈ query time + %s of work while holding the connection, no queueing.", poolWorkers, poolWorkers, poolHoldFor)},
+	{"连接池耗尽", "当前连接池设置"}: {"Current pool settings",
+		fmt.Sprintf("The same load through the lab's own pool (-max-open-conns / MYSQL_MAX_OPEN_CONNS, default 25): with a limit below the %d concurrent requests, the surplus queues in the client for a connection.", poolWorkers)},
+	{"连接池耗尽", "连接池过小"}: {"Pool too small",
+		fmt.Sprintf("Only %d connections: requests spend most of their time waiting for one and fail after %s; every query in the server's slow log is fast, and the problem shows only in application latency and pool wait statistics.", poolTooSmall, poolAcquireTimeout)},
+
+	{"前缀索引选择性对比", "前缀过短(9字符)"}: {"Prefix too short (9 chars)",
+		"customer_name(9) leaves the single value 'Customer ', so equality degrades to scanning the whole index and checking every full value in the table."},
+	{"前缀索引选择性对比", "前缀 12 字符"}: {"12-char prefix",
+		"customer_name(12) distinguishes down to the thousands, matching about 0.1% of entries, but each still needs a table lookup to confirm the full value."},
+	{"前缀索引选择性对比", "完整列索引"}: {"Full column index",
+		"A full column index reads only truly matching entries, with as many table lookups as result rows; the price is a larger index."},
+	{"前缀索引选择性对比", "低基数列的前缀索引"}: {"Prefix index on low-cardinality column",
+		"note holds only a few template texts, so no prefix length improves selectivity; a prefix index can never be a covering index either."},
+
+	{"预处理语句", "每次查询都预处理（驱动默认）"}: {"Prepare per query (driver default)",
+		fmt.Sprintf("With the driver's defaults a parameterized query is prepared server-side: each execution sends COM_STMT_PREPARE, COM_STMT_EXECUTE and COM_STMT_CLOSE, so %d queries cost twice the round trips.", preparedIterations)},
+	{"预处理语句", "预处理一次反复执行（PrepareStmt）"}: {"Prepare once, execute many (PrepareStmt)",
+		"The statement is prepared once and each query sends only COM_STMT_EXECUTE (gorm's PrepareStmt option caches statements to the same effect): one round trip per query and no reparsing, at the cost of a server-side prepared statement per connection (max_prepared_stmt_count)."},
+	{"预处理语句", "客户端插值（interpolateParams）"}: {"Client-side interpolation (interpolateParams)",
+		"With interpolateParams=true in the DSN the driver escapes and inlines parameters and sends plain text queries (COM_QUERY): one round trip and no cached statements, but the server parses the full SQL every time."},
+
+	{"随机抽样对比", "ORDER BY RAND()"}: {"ORDER BY RAND()",
+		"Generates a random number per row and sorts the whole table to take 10: a full scan of a million rows plus filesort."},
+	{"随机抽样对比", "随机主键抽样"}: {"Random primary keys",
+		"Reads the id range, generates 10 random primary keys in the application and fetches them with IN as point lookups."},
+	{"随机抽样对比", "随机主键区间"}: {"Random primary key range",
+		"Picks a random starting id and takes the next 10 rows in primary key order in one range seek; the samples are adjacent rather than independent."},
+	{"随机抽样对比", "蓄水池抽样"}: {"Reservoir sampling",
+		"Streams every id to the application (through the narrowest secondary index, no sort), keeps 10 with reservoir sampling and fetches those rows by primary key; immune to id gaps but still reads every index entry."},
+
+	{"后缀模糊查询对比", "LIKE '%后缀'"}: {"LIKE '%suffix'",
+		"Searching by the last digits of a phone number: the leading % leaves idx_orders_phone nothing to seek on, so a million rows are scanned and matched one by one."},
+	{"后缀模糊查询对比", "反转列前缀匹配"}: {"Prefix match on reversed column",
+		"An index on the generated column phone_reversed = REVERSE(phone) turns the suffix condition into the prefix match phone_reversed LIKE CONCAT(REVERSE(?), '%'), an index range scan."},
+
+	{"索引跳跃扫描对比", "跳跃扫描关闭（8.0 之前的行为）"}: {"Skip scan off (pre-8.0 behaviour)",
+		"The leading column of the (status, total_amount) index is not in the condition, so the whole index is scanned and filtered (type=index), as before MySQL 8.0.13."},
+	{"索引跳跃扫描对比", "跳跃扫描开启"}: {"Skip scan on",
+		"status has only 4 values, so the optimizer range-scans total_amount once per status (Using index for skip scan) and reads only a small share of the index."},
+
+	{"空间索引对比", "矩形范围无索引"}: {"Rectangle without index",
+		"MBRContains filters delivery coordinates by bounding rectangle; without a usable SPATIAL index each of 200k points is checked."},
+	{"空间索引对比", "矩形范围走 R-Tree"}: {"Rectangle with R-Tree",
+		"With a SPATIAL index the R-Tree descends only into nodes intersecting the query rectangle."},
+	{"空间索引对比", "ST_Distance 半径过滤"}: {"ST_Distance radius filter",
+		"ST_Distance(location, center) <= r is a function comparison that cannot use even a SPATIAL index; the distance is computed for every point."},
+	{"空间索引对比", "外包矩形预筛 + ST_Distance"}: {"Bounding box prefilter + ST_Distance",
+		"MBRContains lets the R-Tree return candidates inside the circle's bounding box, and ST_Distance runs on those alone; same result as the previous scenario."},
+
+	{"统计信息过期对比", "突增写入后统计过期"}: {"Stale statistics after a write burst",
+		"With STATS_AUTO_RECALC off the table is ANALYZEd at one row per customer, then one customer gains 200k rows; the optimizer still expects each ref to match 1 row from the old rec_per_key."},
+	{"统计信息过期对比", "ANALYZE TABLE 之后"}: {"After ANALYZE TABLE",
+		"Resampling updates cardinality and row count; EXPLAIN rows reflects the burst, which joins need to pick the right order and join method."},
+
+	{"UNION 去重对比", "UNION 去重"}: {"UNION with dedup",
+		"Merging two large result sets with UNION writes every row into a temporary table and deduplicates on the whole row."},
+	{"UNION 去重对比", "UNION ALL 直接拼接"}: {"UNION ALL concatenation",
+		"When the result sets cannot overlap, UNION ALL lets MySQL 8 stream them directly without a temporary table."},
+
+	{"热点键 upsert 争用", "多 worker 逐条 upsert 不同键"}: {"Workers upsert distinct keys",
+		fmt.Sprintf("Baseline: %d workers each autocommit upserts to their own counter key without waiting for each other; throughput is bounded only by commits (redo fsync).", upsertWorkers)},
+	{"热点键 upsert 争用", "多 worker 逐条 upsert 同一热点键"}: {"Workers upsert one hot key",
+		"The same writes all hit one unique key: each upsert holds an exclusive row lock until commit, workers queue up serially, throughput drops as concurrency rises and row lock waits pile up."},
+	{"热点键 upsert 争用", "多 worker 批量合并后 upsert 热点键"}: {"Workers batch before upserting the hot key",
+		fmt.Sprintf("Still one hot key, but each worker sums %d increments locally and commits them as one hits = hits + %d upsert: 1/%d of the lock acquisitions, and the queue all but disappears.", upsertBatch, upsertBatch, upsertBatch)},
+}
+
+// localize returns sc's type, name and description in lang. Scenarios without a translation,
+// such as those from scenario packs, keep their own text.
+func localize(sc Scenario, lang i18n.Lang) (typ, name, description string) {
+	if lang != i18n.English {
+		return sc.Type, sc.Name, sc.Description
+	}
+	typ, name, description = typeEnglish.T(lang, sc.Type), sc.Name, sc.Description
+	if text, ok := scenarioEnglish[[2]string{sc.Type, sc.Name}]; ok {
+		name, description = text.Name, text.Description
+	}
+	return typ, name, description
+}
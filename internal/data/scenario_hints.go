@@ -0,0 +1,91 @@
+package data
+
+import "strings"
+
+const hintJoinRegion = "north"
+
+func hintScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "优化器提示对比",
+			Name:        "热点客户默认计划",
+			Description: "热点客户占一半数据，观察优化器自己在二级索引回表和全表扫描之间的选择。",
+			Query:       "SELECT * FROM orders WHERE customer_id = ?",
+			Args:        []interface{}{coveringCustomerID},
+			Setup:       ensureHotCustomerOrders,
+		},
+		{
+			Type:        "优化器提示对比",
+			Name:        "NO_INDEX 禁用二级索引",
+			Description: "NO_INDEX 强制放弃 customer_id 索引，直接顺序扫描聚簇索引，避免百万次随机回表。",
+			Query:       "SELECT * FROM orders WHERE customer_id = ?",
+			Args:        []interface{}{coveringCustomerID},
+			Hints:       "NO_INDEX(orders idx_orders_customer_id)",
+			MinVersion:  "8.0.20",
+			Setup:       ensureHotCustomerOrders,
+		},
+		{
+			Type:        "优化器提示对比",
+			Name:        "默认关联顺序",
+			Description: "customers 与 orders 关联并分别过滤，由优化器决定驱动表。",
+			Query: "SELECT c.id, o.id FROM customers c JOIN orders o ON o.customer_id = c.id " +
+				"WHERE c.region = ? AND o.status = 'cancelled' AND o.total_amount > 900",
+			Args:  []interface{}{hintJoinRegion},
+			Setup: ensureCustomers,
+		},
+		{
+			Type:        "优化器提示对比",
+			Name:        "JOIN_ORDER 强制大表驱动",
+			Description: "JOIN_ORDER(o, c) 强制先扫描 orders 再逐行查 customers，演示错误的关联顺序代价。",
+			Query: "SELECT c.id, o.id FROM customers c JOIN orders o ON o.customer_id = c.id " +
+				"WHERE c.region = ? AND o.status = 'cancelled' AND o.total_amount > 900",
+			Args:       []interface{}{hintJoinRegion},
+			Hints:      "JOIN_ORDER(o, c)",
+			MinVersion: "8.0.1",
+			Setup:      ensureCustomers,
+		},
+		{
+			Type:        "优化器提示对比",
+			Name:        "IN 子查询默认半连接",
+			Description: "IN 子查询默认被改写为半连接（semijoin），可在 EXPLAIN 中看到具体策略。",
+			Query: "SELECT * FROM customers c WHERE c.id IN " +
+				"(SELECT /*+ QB_NAME(sub) */ customer_id FROM orders WHERE total_amount > 999)",
+			Setup: ensureCustomers,
+		},
+		{
+			Type:        "优化器提示对比",
+			Name:        "NO_SEMIJOIN 关闭半连接",
+			Description: "NO_SEMIJOIN(@sub) 退回到子查询物化/逐行 EXISTS 检查，对比执行计划与耗时。",
+			Query: "SELECT * FROM customers c WHERE c.id IN " +
+				"(SELECT /*+ QB_NAME(sub) */ customer_id FROM orders WHERE total_amount > 999)",
+			Hints:      "NO_SEMIJOIN(@sub)",
+			MinVersion: "8.0.0",
+			Setup:      ensureCustomers,
+		},
+		{
+			Type:        "优化器提示对比",
+			Name:        "MAX_EXECUTION_TIME 熔断",
+			Description: "给全表函数扫描加上 MAX_EXECUTION_TIME(100)，超过 100ms 由服务端中断，预期报错 3024。",
+			Query:       "SELECT * FROM orders WHERE DATE(created_at) = ?",
+			Args:        []interface{}{indexFuncDate},
+			Hints:       "MAX_EXECUTION_TIME(100)",
+			MinVersion:  "5.7.8",
+			ExpectErr:   "maximum statement execution time exceeded",
+			Setup:       ensureDateRangeOrders,
+		},
+	}
+}
+
+// applyHints places an optimizer hint comment right after the statement's leading keyword,
+// which is the only position MySQL accepts for /*+ ... */ hints.
+func applyHints(query, hints string) string {
+	if hints == "" {
+		return query
+	}
+	trimmed := strings.TrimLeft(query, " \t\n")
+	end := strings.IndexAny(trimmed, " \t\n")
+	if end < 0 {
+		return query
+	}
+	return trimmed[:end] + " /*+ " + hints + " */" + trimmed[end:]
+}
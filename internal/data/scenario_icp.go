@@ -0,0 +1,26 @@
+package data
+
+// handlerCounters show how many index entries and rows the storage engine handed to the server.
+var handlerCounters = []string{"Handler_read_key", "Handler_read_next", "Handler_read_rnd_next"}
+
+const icpQuery = "SELECT * FROM orders WHERE phone LIKE '138%' AND phone LIKE '%8888'"
+
+func icpScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "索引条件下推对比",
+			Name:        "ICP 开启",
+			Description: "phone LIKE '138%' 走范围扫描，'%8888' 下推到存储引擎在索引上过滤，只对匹配项回表（Using index condition）。",
+			Query:       icpQuery,
+			Counters:    handlerCounters,
+		},
+		{
+			Type:            "索引条件下推对比",
+			Name:            "ICP 关闭",
+			Description:     "会话内关闭 index_condition_pushdown，范围内每个索引项都先回表取整行，再由 Server 层过滤。",
+			Query:           icpQuery,
+			OptimizerSwitch: "index_condition_pushdown=off",
+			Counters:        handlerCounters,
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	customerTarget      = 50000
+	functionJoinMaxCust = 20
+)
+
+func functionJoinScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "索引字段做函数操作对比",
+			Name:        "关联条件包裹函数",
+			Description: "ON DATE(o.created_at) = c.signup_date，被驱动表的时间列被函数包裹，每个客户都要全表扫描 orders。",
+			Query: "SELECT c.id, o.id FROM customers c JOIN orders o ON DATE(o.created_at) = c.signup_date " +
+				"WHERE c.id <= ?",
+			Args:  []interface{}{functionJoinMaxCust},
+			Setup: ensureCustomers,
+		},
+		{
+			Type:        "索引字段做函数操作对比",
+			Name:        "关联条件改写为范围",
+			Description: "改写成 o.created_at >= c.signup_date AND < c.signup_date + 1 天，每个客户按 created_at 索引做范围查找。",
+			Query: "SELECT c.id, o.id FROM customers c JOIN orders o " +
+				"ON o.created_at >= c.signup_date AND o.created_at < c.signup_date + INTERVAL 1 DAY " +
+				"WHERE c.id <= ?",
+			Args:  []interface{}{functionJoinMaxCust},
+			Setup: ensureCustomers,
+		},
+	}
+}
+
+// ensureCustomers fills the customers dimension table with signup dates inside the orders' 365-day window.
+func ensureCustomers(ctx context.Context, db *gorm.DB) error {
+	var existing int64
+	if err := db.WithContext(ctx).Model(&Customer{}).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing >= customerTarget {
+		return nil
+	}
+
+	rnd := datasetRand(ctx, streamCustomers)
+	today := datasetNow(ctx).Truncate(24 * time.Hour)
+	style := datasetStyle(ctx)
+	batch := make([]Customer, 0, 1000)
+	prog := newProgress(ctx, "customers", customerTarget-existing)
+	for id := existing + 1; id <= customerTarget; id++ {
+		batch = append(batch, Customer{
+			ID:         uint(id),
+			Name:       style.customerName(uint(id)),
+			Region:     randomChoice(regions, rnd),
+			SignupDate: today.AddDate(0, 0, -rnd.Intn(365)),
+		})
+		if len(batch) == cap(batch) || id == customerTarget {
+			if err := db.WithContext(ctx).Create(&batch).Error; err != nil {
+				return fmt.Errorf("insert customers: %w", err)
+			}
+			prog.add(len(batch))
+			batch = batch[:0]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	jsonTable             = "order_metadata"
+	jsonSampleRows        = 200000
+	multiValuedMinVersion = "8.0.17"
+	jsonCampaign          = "cmp-4242"
+	jsonScalarQuery       = "SELECT order_id FROM " + jsonTable + " WHERE JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.campaign')) = ?"
+	jsonArrayQuery        = "SELECT order_id FROM " + jsonTable + " WHERE 'vip' MEMBER OF (metadata->'$.tags')"
+)
+
+// jsonMariaDB skips the JSON comparison on MariaDB: the table uses the ->> operator, and
+// MEMBER OF and multi-valued indexes do not exist there.
+var jsonMariaDB = MariaDBSupport{Skip: "no -> / ->> operators, MEMBER OF or multi-valued indexes"}
+
+func jsonScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "JSON 字段查询对比",
+			Name:        "JSON_EXTRACT 无索引",
+			Description: "按 metadata 中的 campaign 过滤，每行都要解析 JSON 文档再比较，全表扫描。",
+			Query:       jsonScalarQuery,
+			Args:        []interface{}{jsonCampaign},
+			Setup:       ensureJSONTable,
+			MariaDB:     jsonMariaDB,
+		},
+		{
+			Type:        "JSON 字段查询对比",
+			Name:        "生成列索引",
+			Description: "虚拟生成列 campaign = metadata->>'$.campaign' 上建索引，按生成列等值查询直接 ref 命中，JSON 只在写入时解析一次。",
+			Query:       "SELECT order_id FROM " + jsonTable + " WHERE campaign = ?",
+			Args:        []interface{}{jsonCampaign},
+			Setup:       ensureJSONTable,
+			MariaDB:     jsonMariaDB,
+			// Invisible so the optimizer cannot substitute it into the JSON_EXTRACT scenario above.
+			OptimizerSwitch: "use_invisible_indexes=on",
+			ExpectPlan:      []PlanExpectation{{Table: jsonTable, Type: "ref", Key: "idx_" + jsonTable + "_campaign"}},
+		},
+		{
+			Type:        "JSON 字段查询对比",
+			Name:        "JSON 数组无索引",
+			Description: "'vip' MEMBER OF (metadata->'$.tags') 要展开每一行的 tags 数组逐个比较。",
+			Query:       jsonArrayQuery,
+			MinVersion:  multiValuedMinVersion,
+			Setup:       ensureJSONTable,
+			MariaDB:     jsonMariaDB,
+		},
+		{
+			Type:            "JSON 字段查询对比",
+			Name:            "多值索引",
+			Description:     "CAST(metadata->'$.tags' AS CHAR(16) ARRAY) 多值索引为数组中每个元素建一条索引项，MEMBER OF / JSON_CONTAINS / JSON_OVERLAPS 可直接走索引。",
+			Query:           jsonArrayQuery,
+			MinVersion:      multiValuedMinVersion,
+			Setup:           ensureJSONTable,
+			MariaDB:         jsonMariaDB,
+			OptimizerSwitch: "use_invisible_indexes=on",
+			ExpectPlan:      []PlanExpectation{{Table: jsonTable, Key: "idx_" + jsonTable + "_tags"}},
+		},
+	}
+}
+
+// ensureJSONTable builds a metadata table with one JSON document per sampled order:
+// a high-cardinality campaign string and a small tags array in which "vip" is rare (~1%).
+func ensureJSONTable(ctx context.Context, db *gorm.DB) error {
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		order_id BIGINT UNSIGNED PRIMARY KEY,
+		metadata JSON NOT NULL,
+		campaign VARCHAR(32) GENERATED ALWAYS AS (metadata->>'$.campaign') VIRTUAL
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`, jsonTable)
+	if err := db.WithContext(ctx).Exec(ddl).Error; err != nil {
+		return fmt.Errorf("create %s: %w", jsonTable, err)
+	}
+
+	var existing int64
+	if err := db.WithContext(ctx).Table(jsonTable).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing == 0 {
+		fill := fmt.Sprintf(`INSERT INTO %s (order_id, metadata)
+			SELECT id, JSON_OBJECT(
+				'channel', ELT(1 + id %% 4, 'web', 'app', 'store', 'partner'),
+				'campaign', CONCAT('cmp-', id %% 5000),
+				'device', JSON_OBJECT('os', ELT(1 + id %% 3, 'ios', 'android', 'windows'), 'app_version', CONCAT('3.', id %% 20)),
+				'tags', JSON_ARRAY(ELT(1 + id %% 5, 'gift', 'rush', 'bulk', 'repeat', 'fragile'), IF(id %% 97 = 0, 'vip', 'std')))
+			FROM orders ORDER BY id LIMIT ?`, jsonTable)
+		if err := db.WithContext(ctx).Exec(fill, jsonSampleRows).Error; err != nil {
+			return fmt.Errorf("fill %s: %w", jsonTable, err)
+		}
+	}
+
+	indexes := []struct {
+		name, ddl string
+	}{
+		{"idx_" + jsonTable + "_campaign", "CREATE INDEX idx_%[1]s_campaign ON %[1]s (campaign) INVISIBLE"},
+	}
+	// The array scenarios are skipped on older servers, which cannot build multi-valued indexes.
+	if version, err := DetectServerVersion(ctx, db); err == nil && version.AtLeast(multiValuedMinVersion) {
+		indexes = append(indexes, struct{ name, ddl string }{
+			"idx_" + jsonTable + "_tags", "CREATE INDEX idx_%[1]s_tags ON %[1]s ((CAST(metadata->'$.tags' AS CHAR(16) ARRAY))) INVISIBLE",
+		})
+	}
+	for _, idx := range indexes {
+		var count int64
+		if err := db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM information_schema.STATISTICS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?`, jsonTable, idx.name).
+			Row().Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := db.WithContext(ctx).Exec(fmt.Sprintf(idx.ddl, jsonTable)).Error; err != nil {
+			return fmt.Errorf("create %s: %w", idx.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package data
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// The hot customer is excluded: its million rows would turn the correlated subquery into a 10^12 row scan.
+const (
+	latestCorrelatedQuery = "SELECT o.customer_id, o.id, o.created_at FROM orders o " +
+		"WHERE o.customer_id <> ? AND o.created_at = (SELECT MAX(o2.created_at) FROM orders o2 WHERE o2.customer_id = o.customer_id)"
+	latestJoinMaxQuery = "SELECT o.customer_id, o.id, o.created_at FROM orders o " +
+		"JOIN (SELECT customer_id, MAX(created_at) AS latest FROM orders WHERE customer_id <> ? GROUP BY customer_id) m " +
+		"ON m.customer_id = o.customer_id AND m.latest = o.created_at"
+	latestWindowQuery = "SELECT customer_id, id, created_at FROM (SELECT customer_id, id, created_at, " +
+		"ROW_NUMBER() OVER (PARTITION BY customer_id ORDER BY created_at DESC, id DESC) AS rn " +
+		"FROM orders WHERE customer_id <> ?) t WHERE rn = 1"
+)
+
+func latestPerGroupScenarios() []Scenario {
+	args := []interface{}{coveringCustomerID}
+	return []Scenario{
+		{
+			Type:        "每组最新记录对比",
+			Name:        "相关子查询",
+			Description: "约 5 万个客户，每一行订单都执行一次依赖子查询求该客户 MAX(created_at)，customer_id 索引定位后还要回表读 created_at。",
+			Query:       latestCorrelatedQuery,
+			Args:        args,
+		},
+		{
+			Type:        "每组最新记录对比",
+			Name:        "分组最大值再关联",
+			Description: "先 GROUP BY customer_id 求 MAX(created_at) 物化为派生表，再按 (customer_id, created_at) 关联回 orders；created_at 相同时会返回多行。",
+			Query:       latestJoinMaxQuery,
+			Args:        args,
+		},
+		{
+			Type:        "每组最新记录对比",
+			Name:        "窗口函数 ROW_NUMBER",
+			Description: "ROW_NUMBER() OVER (PARTITION BY customer_id ORDER BY created_at DESC, id DESC) 取 rn = 1，每组恰好一行，但要对全部订单排序。",
+			Query:       latestWindowQuery,
+			Args:        args,
+			MinVersion:  windowFuncMinVersion,
+			MariaDB:     windowFuncMariaDB,
+		},
+		{
+			Type:        "每组最新记录对比",
+			Name:        "复合覆盖索引 + 分组最大值",
+			Description: "建 (customer_id, created_at DESC, id) 复合索引后，分组最大值直接读每组第一项（松散索引扫描），关联回表也变成覆盖索引上的 ref 查找。",
+			Query:       latestJoinMaxQuery,
+			Args:        args,
+			MinVersion:  descIndexMinVersion,
+			Setup:       ensureCustomerLatestIndex,
+			// Invisible so the variants above show the plans without it.
+			OptimizerSwitch: "use_invisible_indexes=on",
+			ExpectPlan:      []PlanExpectation{{Table: "o", Key: "idx_orders_customer_created"}},
+		},
+	}
+}
+
+func ensureCustomerLatestIndex(ctx context.Context, db *gorm.DB) error {
+	if db.WithContext(ctx).Migrator().HasIndex(&Order{}, "idx_orders_customer_created") {
+		return nil
+	}
+	return db.WithContext(ctx).
+		Exec("CREATE INDEX idx_orders_customer_created ON orders (customer_id, created_at DESC, id) INVISIBLE").Error
+}
@@ -0,0 +1,48 @@
+package data
+
+const (
+	mrrRangeQuery = "SELECT * FROM orders WHERE customer_id BETWEEN 1000 AND 3000"
+	bkaJoinQuery  = "SELECT c.id, o.total_amount FROM customers c JOIN orders o ON o.customer_id = c.id " +
+		"WHERE c.id BETWEEN 1000 AND 3000"
+)
+
+var mrrCounters = append([]string{"Handler_mrr_init"}, handlerCounters...)
+
+func mrrScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:            "MRR / BKA 对比",
+			Name:            "二级索引范围回表（MRR 关闭）",
+			Description:     "customer_id 范围命中约 4 万行，按索引顺序逐条回表，主键访问是随机的。",
+			Query:           mrrRangeQuery,
+			OptimizerSwitch: "mrr=off",
+			Counters:        mrrCounters,
+		},
+		{
+			Type:            "MRR / BKA 对比",
+			Name:            "二级索引范围回表（MRR 开启）",
+			Description:     "MRR 先收集一批主键并排序再回表，随机 IO 变为近似顺序 IO（Using MRR）。",
+			Query:           mrrRangeQuery,
+			OptimizerSwitch: "mrr=on,mrr_cost_based=off",
+			Counters:        mrrCounters,
+		},
+		{
+			Type:            "MRR / BKA 对比",
+			Name:            "关联逐行查找（BKA 关闭）",
+			Description:     "customers 驱动 orders，每个客户单独一次 customer_id 索引查找并回表。",
+			Query:           bkaJoinQuery,
+			OptimizerSwitch: "batched_key_access=off",
+			Counters:        mrrCounters,
+			Setup:           ensureCustomers,
+		},
+		{
+			Type:            "MRR / BKA 对比",
+			Name:            "关联批量查找（BKA 开启）",
+			Description:     "驱动表的关联键先放入 join buffer，再批量交给 MRR 查找被驱动表（Batched Key Access）。",
+			Query:           bkaJoinQuery,
+			OptimizerSwitch: "mrr=on,mrr_cost_based=off,batched_key_access=on",
+			Counters:        mrrCounters,
+			Setup:           ensureCustomers,
+		},
+	}
+}
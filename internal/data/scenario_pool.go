@@ -0,0 +1,157 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	// Every pool scenario sends the same load: poolWorkers concurrent clients, each issuing
+	// poolRequests lookups and keeping the connection for poolHoldFor afterwards, as application
+	// code inside a transaction would.
+	poolWorkers  = 32
+	poolRequests = 10
+	poolHoldFor  = 20 * time.Millisecond
+	// poolAcquireTimeout is how long a request waits for a free connection before giving up.
+	poolAcquireTimeout = 250 * time.Millisecond
+	poolTooSmall       = 2
+)
+
+// poolScenarios put a fixed concurrent load through connection pools of different sizes: when
+// the pool has fewer connections than concurrent requests, the surplus queues inside the client
+// and eventually times out, while the server sees nothing but fast queries.
+func poolScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "连接池耗尽",
+			Name:        "连接池足够",
+			Description: fmt.Sprintf("%d 个并发请求各自拿到一条连接（上限 %d），请求延迟 ≈ 查询耗时 + 持有连接的 %s 业务处理，没有排队。", poolWorkers, poolWorkers, poolHoldFor),
+			Query:       connChurnQuery,
+			Args:        []interface{}{1},
+			Run:         runPoolLoad(poolWorkers),
+		},
+		{
+			Type:        "连接池耗尽",
+			Name:        "当前连接池设置",
+			Description: fmt.Sprintf("同样的负载经过实验室自己的连接池（-max-open-conns / MYSQL_MAX_OPEN_CONNS，默认 25）：上限低于并发数 %d 时，多出来的请求在客户端排队等连接。", poolWorkers),
+			Query:       connChurnQuery,
+			Args:        []interface{}{1},
+			Run:         runPoolLoad(0),
+		},
+		{
+			Type:        "连接池耗尽",
+			Name:        "连接池过小",
+			Description: fmt.Sprintf("上限只有 %d 条连接：请求大部分时间花在等连接上，等待超过 %s 的直接失败；服务端慢查询日志里每条查询都很快，问题只体现在应用侧延迟与连接池等待统计上。", poolTooSmall, poolAcquireTimeout),
+			Query:       connChurnQuery,
+			Args:        []interface{}{1},
+			Run:         runPoolLoad(poolTooSmall),
+		},
+	}
+}
+
+// runPoolLoad sends the pool load through a separate pool of size connections, or through the
+// lab's own pool when size is 0.
+func runPoolLoad(size int) func(context.Context, *gorm.DB, *ScenarioResult) error {
+	return func(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+		minID, maxID, err := orderIDRange(ctx, db)
+		if err != nil {
+			return err
+		}
+		pool, err := db.DB()
+		if err != nil {
+			return err
+		}
+		if size > 0 {
+			if pool, err = openVariant(db, func(*mysql.Config) {}); err != nil {
+				return err
+			}
+			defer pool.Close()
+			pool.SetMaxOpenConns(size)
+			pool.SetMaxIdleConns(size)
+		}
+		before := pool.Stats()
+
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			latencies []time.Duration
+			timeouts  int
+			firstErr  error
+		)
+		start := time.Now()
+		for w := 0; w < poolWorkers; w++ {
+			rnd := rand.New(rand.NewSource(int64(1367 + w)))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < poolRequests; i++ {
+					id := minID + uint64(rnd.Int63n(int64(maxID-minID+1)))
+					requestStart := time.Now()
+					err := poolRequest(ctx, pool, id)
+					elapsed := time.Since(requestStart)
+					mu.Lock()
+					switch {
+					case errors.Is(err, context.DeadlineExceeded):
+						timeouts++
+					case err != nil:
+						if firstErr == nil {
+							firstErr = err
+						}
+					default:
+						latencies = append(latencies, elapsed)
+					}
+					mu.Unlock()
+					if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		res.Duration = time.Since(start)
+		if firstErr != nil {
+			return firstErr
+		}
+		after := pool.Stats()
+
+		res.RowCount = int64(len(latencies))
+		stats := summarizeLatencies(latencies)
+		res.Notes = append(res.Notes,
+			fmt.Sprintf("pool max open %d, %d workers: %d requests served, %d timed out waiting %s for a connection, %s requests/s",
+				after.MaxOpenConnections, poolWorkers, len(latencies), timeouts, poolAcquireTimeout, perSecond(len(latencies), res.Duration)),
+			fmt.Sprintf("request latency incl. wait: p50 %s, p95 %s, p99 %s, max %s",
+				stats.P50.Round(time.Millisecond), stats.P95.Round(time.Millisecond), stats.P99.Round(time.Millisecond), stats.Max.Round(time.Millisecond)),
+			fmt.Sprintf("pool waits %d, total wait %s (sql.DBStats WaitCount / WaitDuration)",
+				after.WaitCount-before.WaitCount, (after.WaitDuration-before.WaitDuration).Round(time.Millisecond)))
+		if after.MaxOpenConnections == 0 || after.MaxOpenConnections >= poolWorkers {
+			res.Notes = append(res.Notes, fmt.Sprintf("pool limit covers all %d workers; lower -max-open-conns to see requests queue", poolWorkers))
+		}
+		return nil
+	}
+}
+
+// poolRequest waits at most poolAcquireTimeout for a connection, runs one lookup on it and keeps
+// it for poolHoldFor before handing it back.
+func poolRequest(ctx context.Context, pool *sql.DB, id uint64) error {
+	acquireCtx, cancel := context.WithTimeout(ctx, poolAcquireTimeout)
+	conn, err := pool.Conn(acquireCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var status string
+	if err := conn.QueryRowContext(ctx, connChurnQuery, id).Scan(&status); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	time.Sleep(poolHoldFor)
+	return nil
+}
@@ -0,0 +1,165 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	prefixTable      = "order_name_prefix"
+	prefixSampleRows = 200000
+	prefixCustomer   = 4242
+)
+
+// prefixIndexes are created on prefixTable. Every customer name looks like "Customer 004242",
+// so a 9-character prefix keeps a single distinct value and 12 characters narrow it to ~1000 names.
+var prefixIndexes = []struct {
+	name   string
+	column string
+}{
+	{"idx_prefix_name_9", "customer_name(9)"},
+	{"idx_prefix_name_12", "customer_name(12)"},
+	{"idx_prefix_name_full", "customer_name"},
+	{"idx_prefix_note_20", "note(20)"},
+}
+
+const prefixNameQuery = "SELECT id, customer_name, note FROM " + prefixTable + " FORCE INDEX (%s) WHERE customer_name = ?"
+
+// prefixScenarios are left out for realistic datasets, whose names do not share a prefix.
+func prefixScenarios(realistic bool) []Scenario {
+	if realistic {
+		return nil
+	}
+	nameArgs := []interface{}{customerName(prefixCustomer)}
+	return []Scenario{
+		{
+			Type:        "前缀索引选择性对比",
+			Name:        "前缀过短(9字符)",
+			Description: "customer_name(9) 只剩 'Customer ' 一个取值，等值查询退化为扫描整个索引并逐行回表比对完整值。",
+			Query:       fmt.Sprintf(prefixNameQuery, "idx_prefix_name_9"),
+			Args:        nameArgs,
+			Setup:       ensurePrefixTable,
+			Counters:    handlerCounters,
+			Inspect:     prefixIndexStats,
+		},
+		{
+			Type:        "前缀索引选择性对比",
+			Name:        "前缀 12 字符",
+			Description: "customer_name(12) 区分到千位，命中约千分之一的索引项，但每一项仍要回表才能确认完整值。",
+			Query:       fmt.Sprintf(prefixNameQuery, "idx_prefix_name_12"),
+			Args:        nameArgs,
+			Setup:       ensurePrefixTable,
+			Counters:    handlerCounters,
+			Inspect:     prefixIndexStats,
+		},
+		{
+			Type:        "前缀索引选择性对比",
+			Name:        "完整列索引",
+			Description: "完整列索引只读取真正匹配的索引项，回表次数等于结果行数，代价是索引体积更大。",
+			Query:       fmt.Sprintf(prefixNameQuery, "idx_prefix_name_full"),
+			Args:        nameArgs,
+			Setup:       ensurePrefixTable,
+			Counters:    handlerCounters,
+			Inspect:     prefixIndexStats,
+		},
+		{
+			Type:        "前缀索引选择性对比",
+			Name:        "低基数列的前缀索引",
+			Description: "note 只有少量模板文案，即使前缀足够长也无法提高选择性；前缀索引也永远不能作为覆盖索引。",
+			Query:       "SELECT id FROM " + prefixTable + " FORCE INDEX (idx_prefix_note_20) WHERE note = ?",
+			Args:        []interface{}{loremSamples[0]},
+			Setup:       ensurePrefixTable,
+			Counters:    handlerCounters,
+			Inspect:     prefixIndexStats,
+		},
+	}
+}
+
+// ensurePrefixTable copies a sample of orders (excluding the hot customer) into a table carrying the prefix indexes.
+func ensurePrefixTable(ctx context.Context, db *gorm.DB) error {
+	indexDDL := make([]string, 0, len(prefixIndexes))
+	for _, idx := range prefixIndexes {
+		indexDDL = append(indexDDL, fmt.Sprintf("INDEX %s (%s)", idx.name, idx.column))
+	}
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id BIGINT UNSIGNED PRIMARY KEY,
+		customer_name VARCHAR(64) NOT NULL,
+		note VARCHAR(255) NOT NULL,
+		%s
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`, prefixTable, strings.Join(indexDDL, ",\n\t\t"))
+	if err := db.WithContext(ctx).Exec(ddl).Error; err != nil {
+		return fmt.Errorf("create %s: %w", prefixTable, err)
+	}
+
+	var existing int64
+	if err := db.WithContext(ctx).Table(prefixTable).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+	fill := fmt.Sprintf("INSERT INTO %s (id, customer_name, note) SELECT id, customer_name, note FROM orders WHERE customer_id <> ? ORDER BY id LIMIT ?", prefixTable)
+	if err := db.WithContext(ctx).Exec(fill, coveringCustomerID, prefixSampleRows).Error; err != nil {
+		return fmt.Errorf("fill %s: %w", prefixTable, err)
+	}
+	// Refresh cardinality so information_schema.STATISTICS reflects the freshly loaded rows.
+	return db.WithContext(ctx).Exec("ANALYZE TABLE " + prefixTable).Error
+}
+
+// prefixIndexStats reports selectivity (CARDINALITY / rows) from information_schema.STATISTICS and,
+// when mysql.innodb_index_stats is readable, the on-disk size of every index on prefixTable.
+func prefixIndexStats(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var total int64
+	if err := db.WithContext(ctx).Table(prefixTable).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, nil
+	}
+
+	var stats []struct {
+		IndexName   string
+		SubPart     *int64
+		Cardinality int64
+	}
+	if err := db.WithContext(ctx).Raw(`SELECT INDEX_NAME AS index_name, SUB_PART AS sub_part, COALESCE(CARDINALITY, 0) AS cardinality
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME LIKE 'idx_prefix_%'
+		ORDER BY INDEX_NAME`, prefixTable).
+		Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64)
+	var sizeRows []struct {
+		IndexName string
+		Bytes     int64
+	}
+	sizeErr := db.WithContext(ctx).Raw(`SELECT index_name, stat_value * @@innodb_page_size AS bytes
+		FROM mysql.innodb_index_stats
+		WHERE database_name = DATABASE() AND table_name = ? AND stat_name = 'size'`, prefixTable).
+		Scan(&sizeRows).Error
+	for _, row := range sizeRows {
+		sizes[row.IndexName] = row.Bytes
+	}
+
+	notes := make([]string, 0, len(stats))
+	for _, st := range stats {
+		prefix := "full"
+		if st.SubPart != nil {
+			prefix = fmt.Sprintf("%d chars", *st.SubPart)
+		}
+		note := fmt.Sprintf("%s (%s): cardinality=%d selectivity=%.4f", st.IndexName, prefix, st.Cardinality, float64(st.Cardinality)/float64(total))
+		if size, ok := sizes[st.IndexName]; ok {
+			note += fmt.Sprintf(" size=%.1fMiB", float64(size)/(1<<20))
+		}
+		notes = append(notes, note)
+	}
+	if sizeErr != nil {
+		notes = append(notes, fmt.Sprintf("index sizes unavailable (needs SELECT on mysql.innodb_index_stats): %v", sizeErr))
+	}
+	return notes, nil
+}
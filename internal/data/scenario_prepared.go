@@ -0,0 +1,154 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	preparedQuery      = "SELECT status FROM orders WHERE id = ?"
+	preparedIterations = 5000
+)
+
+// preparedMode is how the prepared statement scenarios send each execution of the hot query.
+type preparedMode int
+
+const (
+	// preparedPerQuery is the driver default: every call with arguments prepares, executes and closes a statement.
+	preparedPerQuery preparedMode = iota
+	// preparedOnce prepares the statement once and reuses it, as gorm's PrepareStmt option does.
+	preparedOnce
+	// preparedInterpolated sets interpolateParams so the driver inlines the arguments and sends plain text queries.
+	preparedInterpolated
+)
+
+// preparedScenarios run the same primary-key lookup many times over one connection, varying only
+// how the driver passes its argument, and count the protocol commands each way costs.
+func preparedScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "预处理语句",
+			Name:        "每次查询都预处理（驱动默认）",
+			Description: fmt.Sprintf("带参数的查询在驱动默认配置下走服务端预处理：每次执行都发 COM_STMT_PREPARE、COM_STMT_EXECUTE、COM_STMT_CLOSE，%d 次查询就是两倍的网络往返。", preparedIterations),
+			Query:       preparedQuery,
+			Args:        []interface{}{1},
+			Run:         runPreparedComparison(preparedPerQuery),
+		},
+		{
+			Type:        "预处理语句",
+			Name:        "预处理一次反复执行（PrepareStmt）",
+			Description: "语句只预处理一次，之后每次只发 COM_STMT_EXECUTE（gorm 的 PrepareStmt 选项会缓存语句达到同样效果）：每次查询一个往返，服务端也省去重复解析；代价是每个连接都占着服务端的预处理语句（max_prepared_stmt_count）。",
+			Query:       preparedQuery,
+			Args:        []interface{}{1},
+			Run:         runPreparedComparison(preparedOnce),
+		},
+		{
+			Type:        "预处理语句",
+			Name:        "客户端插值（interpolateParams）",
+			Description: "DSN 设置 interpolateParams=true 后驱动在客户端转义并拼接参数，发送普通文本查询（COM_QUERY）：每次一个往返、无需缓存语句，但服务端每次都要完整解析 SQL。",
+			Query:       preparedQuery,
+			Args:        []interface{}{1},
+			Run:         runPreparedComparison(preparedInterpolated),
+		},
+	}
+}
+
+func runPreparedComparison(mode preparedMode) func(context.Context, *gorm.DB, *ScenarioResult) error {
+	return func(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+		minID, maxID, err := orderIDRange(ctx, db)
+		if err != nil {
+			return err
+		}
+		target, err := openVariant(db, func(cfg *mysql.Config) {
+			cfg.InterpolateParams = mode == preparedInterpolated
+		})
+		if err != nil {
+			return err
+		}
+		defer target.Close()
+		// One connection, so the session counters below cover exactly the queries of this scenario.
+		target.SetMaxOpenConns(1)
+
+		query := func(id uint64) *sql.Row { return target.QueryRowContext(ctx, preparedQuery, id) }
+		if mode == preparedOnce {
+			stmt, err := target.PrepareContext(ctx, preparedQuery)
+			if err != nil {
+				return err
+			}
+			defer stmt.Close()
+			query = func(id uint64) *sql.Row { return stmt.QueryRowContext(ctx, id) }
+		}
+
+		before, err := sessionCommandCounters(ctx, target)
+		if err != nil {
+			return err
+		}
+		rnd := rand.New(rand.NewSource(1329))
+		latencies := make([]time.Duration, 0, preparedIterations)
+		start := time.Now()
+		for i := 0; i < preparedIterations; i++ {
+			id := minID + uint64(rnd.Int63n(int64(maxID-minID+1)))
+			queryStart := time.Now()
+			var status sql.NullString
+			if err := query(id).Scan(&status); err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			latencies = append(latencies, time.Since(queryStart))
+		}
+		res.Duration = time.Since(start)
+		res.RowCount = preparedIterations
+		after, err := sessionCommandCounters(ctx, target)
+		if err != nil {
+			return err
+		}
+
+		delta := make(map[string]int64, len(after))
+		for name, value := range after {
+			delta[name] = value - before[name]
+		}
+		// The counter read itself is a text SELECT.
+		delta["Com_select"]--
+		roundTrips := delta["Com_stmt_prepare"] + delta["Com_stmt_execute"] + delta["Com_select"]
+
+		stats := summarizeLatencies(latencies)
+		res.Notes = append(res.Notes,
+			fmt.Sprintf("%d queries: avg %s, p50 %s, p95 %s, max %s", preparedIterations,
+				stats.Avg.Round(time.Microsecond), stats.P50.Round(time.Microsecond), stats.P95.Round(time.Microsecond), stats.Max.Round(time.Microsecond)),
+			fmt.Sprintf("session counters: Com_stmt_prepare=%d, Com_stmt_execute=%d, Com_stmt_close=%d, Com_select=%d",
+				delta["Com_stmt_prepare"], delta["Com_stmt_execute"], delta["Com_stmt_close"], delta["Com_select"]),
+			fmt.Sprintf("round-trips: %d (%.1f per query; COM_STMT_CLOSE gets no reply)", roundTrips, float64(roundTrips)/preparedIterations))
+		return nil
+	}
+}
+
+var sessionCommandNames = []string{"Com_stmt_prepare", "Com_stmt_execute", "Com_stmt_close", "Com_select"}
+
+// sessionCommandCounters reads the protocol command counters of the session behind a
+// single-connection pool.
+func sessionCommandCounters(ctx context.Context, conn *sql.DB) (map[string]int64, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM performance_schema.session_status WHERE VARIABLE_NAME IN ('"+
+		strings.Join(sessionCommandNames, "', '")+"')")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counters := make(map[string]int64, len(sessionCommandNames))
+	for rows.Next() {
+		var (
+			name  string
+			value int64
+		)
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		counters[name] = value
+	}
+	return counters, rows.Err()
+}
@@ -0,0 +1,121 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const randomSampleSize = 10
+
+func randomScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "随机抽样对比",
+			Name:        "ORDER BY RAND()",
+			Description: "为每一行生成随机数后整表排序再取 10 条，百万行全表扫描加 filesort。",
+			Query:       "SELECT * FROM orders ORDER BY RAND() LIMIT 10",
+		},
+		{
+			Type:        "随机抽样对比",
+			Name:        "随机主键抽样",
+			Description: "先取 id 范围，在应用层生成 10 个随机主键后用 IN 走主键点查。",
+			Query:       "SELECT * FROM orders WHERE id IN ?",
+			ArgsFunc:    randomPrimaryKeyArgs,
+		},
+		{
+			Type:        "随机抽样对比",
+			Name:        "随机主键区间",
+			Description: "随机选一个起点 id 后按主键顺序取连续 10 行，一次范围定位即可；代价是样本彼此相邻、不独立。",
+			Query:       "SELECT * FROM orders WHERE id >= ? ORDER BY id LIMIT 10",
+			ArgsFunc:    randomRangeStartArgs,
+		},
+		{
+			Type:        "随机抽样对比",
+			Name:        "蓄水池抽样",
+			Description: "应用层流式读取全部 id（走最窄的二级索引，无排序），用蓄水池算法保留 10 个，再按主键取整行；对 id 空洞免疫，仍需读完全部索引项。",
+			Query:       reservoirIDQuery,
+			Run:         runReservoirSample,
+		},
+	}
+}
+
+const reservoirIDQuery = "SELECT id FROM orders"
+
+// randomPrimaryKeyArgs draws sample ids uniformly from [MIN(id), MAX(id)]; gaps in the id
+// sequence may return slightly fewer rows, which is the usual trade-off of this rewrite.
+func randomPrimaryKeyArgs(ctx context.Context, db *gorm.DB) ([]interface{}, error) {
+	minID, maxID, err := orderIDRange(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ids := make([]uint64, 0, randomSampleSize)
+	for i := 0; i < randomSampleSize; i++ {
+		ids = append(ids, minID+uint64(rnd.Int63n(int64(maxID-minID+1))))
+	}
+	return []interface{}{ids}, nil
+}
+
+// randomRangeStartArgs picks a start id leaving room for a full sample before MAX(id).
+func randomRangeStartArgs(ctx context.Context, db *gorm.DB) ([]interface{}, error) {
+	minID, maxID, err := orderIDRange(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	span := int64(maxID-minID) - randomSampleSize
+	if span < 1 {
+		span = 1
+	}
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return []interface{}{minID + uint64(rnd.Int63n(span))}, nil
+}
+
+// runReservoirSample streams every id once and keeps a uniform sample with Algorithm R.
+func runReservoirSample(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	start := time.Now()
+	rows, err := db.WithContext(ctx).Raw(reservoirIDQuery).Rows()
+	if err != nil {
+		return err
+	}
+	reservoir := make([]uint64, 0, randomSampleSize)
+	var seen int64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		seen++
+		if len(reservoir) < randomSampleSize {
+			reservoir = append(reservoir, id)
+		} else if j := rnd.Int63n(seen); j < randomSampleSize {
+			reservoir[j] = id
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	var sample []Order
+	if err := db.WithContext(ctx).Where("id IN ?", reservoir).Find(&sample).Error; err != nil {
+		return err
+	}
+	res.Duration = time.Since(start)
+	res.RowCount = int64(len(sample))
+	res.Notes = append(res.Notes, fmt.Sprintf("streamed %d ids to draw %d", seen, len(reservoir)))
+	return nil
+}
+
+func orderIDRange(ctx context.Context, db *gorm.DB) (uint64, uint64, error) {
+	var minID, maxID uint64
+	err := db.WithContext(ctx).Raw("SELECT COALESCE(MIN(id), 0), COALESCE(MAX(id), 0) FROM orders").
+		Row().Scan(&minID, &maxID)
+	return minID, maxID, err
+}
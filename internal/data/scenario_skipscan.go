@@ -0,0 +1,50 @@
+package data
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+const (
+	skipScanMinVersion = "8.0.13"
+	skipScanQuery      = "SELECT status, total_amount FROM orders WHERE total_amount > 995"
+)
+
+var skipScanCounters = []string{"Handler_read_key", "Handler_read_next"}
+
+func skipScanScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "索引跳跃扫描对比",
+			Name:        "跳跃扫描关闭（8.0 之前的行为）",
+			Description: "联合索引 (status, total_amount) 的前导列不在条件中，只能整棵索引扫描后过滤（type=index），等同 MySQL 8.0.13 之前的做法。",
+			Query:       skipScanQuery,
+			MinVersion:  skipScanMinVersion,
+			Setup:       ensureStatusAmountIndex,
+			// The composite index is invisible so the other scenarios keep their plans.
+			OptimizerSwitch: "use_invisible_indexes=on,skip_scan=off",
+			Counters:        skipScanCounters,
+			ExpectPlan:      []PlanExpectation{{Table: "orders", Type: "index", Key: "idx_orders_status_total"}},
+		},
+		{
+			Type:            "索引跳跃扫描对比",
+			Name:            "跳跃扫描开启",
+			Description:     "status 只有 4 个取值，优化器按每个 status 分段对 total_amount 做范围扫描（Using index for skip scan），读取的索引项只占一小部分。",
+			Query:           skipScanQuery,
+			MinVersion:      skipScanMinVersion,
+			Setup:           ensureStatusAmountIndex,
+			OptimizerSwitch: "use_invisible_indexes=on,skip_scan=on",
+			Counters:        skipScanCounters,
+			ExpectPlan:      []PlanExpectation{{Table: "orders", Type: "range", Key: "idx_orders_status_total", Extra: "Using index for skip scan"}},
+		},
+	}
+}
+
+func ensureStatusAmountIndex(ctx context.Context, db *gorm.DB) error {
+	if db.WithContext(ctx).Migrator().HasIndex(&Order{}, "idx_orders_status_total") {
+		return nil
+	}
+	return db.WithContext(ctx).
+		Exec("CREATE INDEX idx_orders_status_total ON orders (status, total_amount) INVISIBLE").Error
+}
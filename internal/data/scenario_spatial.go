@@ -0,0 +1,117 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	spatialTable      = "order_locations"
+	spatialSampleRows = 200000
+	spatialIndex      = "sp_" + spatialTable + "_location"
+	// Delivery points are spread over roughly 121.0–122.0 E, 30.7–31.5 N; the search is a ~1 km
+	// radius (0.01 degree, planar SRID 0 coordinates) around People's Square in Shanghai.
+	spatialCenterX = 121.475
+	spatialCenterY = 31.233
+	spatialRadius  = 0.01
+
+	spatialBoxQuery      = "SELECT order_id FROM " + spatialTable + " WHERE MBRContains(ST_MakeEnvelope(POINT(?, ?), POINT(?, ?)), location)"
+	spatialDistanceQuery = "SELECT order_id FROM " + spatialTable + " WHERE ST_Distance(location, POINT(?, ?)) <= ?"
+)
+
+var (
+	spatialBoxArgs = []interface{}{
+		spatialCenterX - spatialRadius, spatialCenterY - spatialRadius,
+		spatialCenterX + spatialRadius, spatialCenterY + spatialRadius,
+	}
+	spatialDistanceArgs = []interface{}{spatialCenterX, spatialCenterY, spatialRadius}
+)
+
+func spatialScenarios() []Scenario {
+	indexPlan := []PlanExpectation{{Table: spatialTable, Type: "range", Key: spatialIndex}}
+	return []Scenario{
+		{
+			Type:        "空间索引对比",
+			Name:        "矩形范围无索引",
+			Description: "MBRContains 按外包矩形过滤配送坐标，没有可用的 SPATIAL 索引时对 20 万个点逐一计算。",
+			Query:       spatialBoxQuery,
+			Args:        spatialBoxArgs,
+			Setup:       ensureSpatialTable,
+			// The setup creates the SPATIAL index invisible, which the other variants switch on per session.
+			MariaDB: MariaDBSupport{Skip: "the comparison toggles an invisible SPATIAL index per session (use_invisible_indexes)"},
+			TiDB:    TiDBSupport{Skip: "no spatial data types or SPATIAL indexes"},
+		},
+		{
+			Type:        "空间索引对比",
+			Name:        "矩形范围走 R-Tree",
+			Description: "启用 SPATIAL 索引后，R-Tree 只下探与查询矩形相交的节点。",
+			Query:       spatialBoxQuery,
+			Args:        spatialBoxArgs,
+			Setup:       ensureSpatialTable,
+			// Invisible so the optimizer cannot use it in the unindexed variants.
+			OptimizerSwitch: "use_invisible_indexes=on",
+			ExpectPlan:      indexPlan,
+		},
+		{
+			Type:            "空间索引对比",
+			Name:            "ST_Distance 半径过滤",
+			Description:     "ST_Distance(location, 圆心) <= r 是函数比较，即使启用 SPATIAL 索引也无法走索引，每个点都要算一次距离。",
+			Query:           spatialDistanceQuery,
+			Args:            spatialDistanceArgs,
+			Setup:           ensureSpatialTable,
+			OptimizerSwitch: "use_invisible_indexes=on",
+		},
+		{
+			Type:            "空间索引对比",
+			Name:            "外包矩形预筛 + ST_Distance",
+			Description:     "先用 MBRContains 让 R-Tree 取出圆的外包矩形内的候选点，再只对候选点计算 ST_Distance，结果与上一场景相同。",
+			Query:           spatialBoxQuery + " AND ST_Distance(location, POINT(?, ?)) <= ?",
+			Args:            append(append([]interface{}{}, spatialBoxArgs...), spatialDistanceArgs...),
+			Setup:           ensureSpatialTable,
+			OptimizerSwitch: "use_invisible_indexes=on",
+			ExpectPlan:      indexPlan,
+		},
+	}
+}
+
+// ensureSpatialTable samples orders into a delivery-location table with deterministic, evenly
+// scattered points and an invisible SPATIAL index. The column needs NOT NULL and an SRID
+// attribute, otherwise MySQL 8.0 builds the index but the optimizer never uses it.
+func ensureSpatialTable(ctx context.Context, db *gorm.DB) error {
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		order_id BIGINT UNSIGNED PRIMARY KEY,
+		location POINT NOT NULL SRID 0
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`, spatialTable)
+	if err := db.WithContext(ctx).Exec(ddl).Error; err != nil {
+		return fmt.Errorf("create %s: %w", spatialTable, err)
+	}
+
+	var existing int64
+	if err := db.WithContext(ctx).Table(spatialTable).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing == 0 {
+		fill := fmt.Sprintf(`INSERT INTO %s (order_id, location)
+			SELECT id, POINT(121.0 + (id * 7919 %% 100000) / 100000, 30.7 + (id * 104729 %% 80000) / 100000)
+			FROM orders ORDER BY id LIMIT ?`, spatialTable)
+		if err := db.WithContext(ctx).Exec(fill, spatialSampleRows).Error; err != nil {
+			return fmt.Errorf("fill %s: %w", spatialTable, err)
+		}
+	}
+
+	var count int64
+	if err := db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?`, spatialTable, spatialIndex).
+		Row().Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := db.WithContext(ctx).Exec(fmt.Sprintf("CREATE SPATIAL INDEX %s ON %s (location) INVISIBLE", spatialIndex, spatialTable)).Error; err != nil {
+		return fmt.Errorf("create %s: %w", spatialIndex, err)
+	}
+	return nil
+}
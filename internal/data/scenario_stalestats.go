@@ -0,0 +1,75 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	staleStatsTable    = "order_stats_burst"
+	staleStatsBaseRows = 20000
+	staleStatsBurst    = 200000
+	staleStatsHotKey   = 7
+	staleStatsQuery    = "SELECT c.id, s.amount FROM customers c JOIN " + staleStatsTable + " s ON s.customer_id = c.id WHERE c.id BETWEEN 1 AND 100"
+)
+
+// staleStatsTiDB skips the comparison on TiDB: STATS_AUTO_RECALC is an InnoDB table option it
+// ignores, and auto analyze may refresh the statistics between the two variants.
+var staleStatsTiDB = TiDBSupport{Skip: "STATS_AUTO_RECALC=0 has no effect; TiDB's auto analyze owns statistics refreshes"}
+
+func staleStatsScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "统计信息过期对比",
+			Name:        "突增写入后统计过期",
+			Description: "表关闭 STATS_AUTO_RECALC，先以每个客户 1 行的分布 ANALYZE，再给同一个客户突增 20 万行；优化器仍按旧的 rec_per_key 认为每次 ref 只命中 1 行。",
+			Query:       staleStatsQuery,
+			Setup:       resetStaleStatsTable,
+			Inspect:     estimateInspector(staleStatsQuery),
+			TiDB:        staleStatsTiDB,
+		},
+		{
+			Type:        "统计信息过期对比",
+			Name:        "ANALYZE TABLE 之后",
+			Description: "重新采样后基数与总行数更新，EXPLAIN 的 rows 反映突增的数据，多表关联时才能据此选出正确的驱动顺序与连接方式。",
+			Query:       staleStatsQuery,
+			Setup:       ensureCustomers,
+			SetupSQL:    []string{"ANALYZE TABLE " + staleStatsTable},
+			Inspect:     estimateInspector(staleStatsQuery),
+			TiDB:        staleStatsTiDB,
+		},
+	}
+}
+
+// resetStaleStatsTable rebuilds the demo table on every run: an even distribution is analyzed,
+// then a burst for one customer is written while automatic recalculation is disabled.
+func resetStaleStatsTable(ctx context.Context, db *gorm.DB) error {
+	if err := ensureCustomers(ctx, db); err != nil {
+		return err
+	}
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+		customer_id BIGINT UNSIGNED NOT NULL,
+		amount DOUBLE NOT NULL,
+		INDEX idx_%s_customer_id (customer_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 STATS_PERSISTENT=1 STATS_AUTO_RECALC=0`, staleStatsTable, staleStatsTable)
+	steps := []struct {
+		name string
+		sql  string
+		args []interface{}
+	}{
+		{"create", ddl, nil},
+		{"truncate", "TRUNCATE TABLE " + staleStatsTable, nil},
+		{"fill", fmt.Sprintf("INSERT INTO %s (customer_id, amount) SELECT id, 100 FROM customers ORDER BY id LIMIT ?", staleStatsTable), []interface{}{staleStatsBaseRows}},
+		{"analyze", "ANALYZE TABLE " + staleStatsTable, nil},
+		{"burst", fmt.Sprintf("INSERT INTO %s (customer_id, amount) SELECT ?, total_amount FROM orders ORDER BY id LIMIT ?", staleStatsTable), []interface{}{staleStatsHotKey, staleStatsBurst}},
+	}
+	for _, step := range steps {
+		if err := db.WithContext(ctx).Exec(step.sql, step.args...).Error; err != nil {
+			return fmt.Errorf("%s %s: %w", step.name, staleStatsTable, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package data
+
+// unionCounters exposes the implicit temporary table that UNION needs for deduplication.
+var unionCounters = []string{"Created_tmp_tables", "Created_tmp_disk_tables", "Handler_write"}
+
+func unionScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "UNION 去重对比",
+			Name:        "UNION 去重",
+			Description: "两个大结果集用 UNION 合并，需要临时表逐行写入并按整行去重。",
+			Query: "SELECT customer_id, phone FROM orders WHERE region = 'north' " +
+				"UNION SELECT customer_id, phone FROM orders WHERE region = 'south'",
+			Counters: unionCounters,
+		},
+		{
+			Type:        "UNION 去重对比",
+			Name:        "UNION ALL 直接拼接",
+			Description: "结果集互不重叠时改用 UNION ALL，MySQL 8 直接流式返回，无需临时表。",
+			Query: "SELECT customer_id, phone FROM orders WHERE region = 'north' " +
+				"UNION ALL SELECT customer_id, phone FROM orders WHERE region = 'south'",
+			Counters: unionCounters,
+		},
+	}
+}
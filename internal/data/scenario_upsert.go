@@ -0,0 +1,120 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	upsertQuery = "INSERT INTO upsert_counters (counter_key, hits) VALUES (?, ?) ON DUPLICATE KEY UPDATE hits = hits + VALUES(hits)"
+	// Every variant applies upsertWorkers*upsertPerWorker increments; only how they reach the row differs.
+	upsertWorkers   = 16
+	upsertPerWorker = 1000
+	upsertBatch     = 100
+	upsertHotKey    = "hot"
+)
+
+// upsertScenarios demonstrate hot-key contention on INSERT ... ON DUPLICATE KEY UPDATE: every
+// upsert on an existing key takes an exclusive lock on its unique index record and row, so
+// concurrent upserts on one key queue behind each other's commits.
+func upsertScenarios() []Scenario {
+	return []Scenario{
+		{
+			Type:        "热点键 upsert 争用",
+			Name:        "多 worker 逐条 upsert 不同键",
+			Description: fmt.Sprintf("基线：%d 个 worker 各自对自己的计数键逐条自动提交 upsert，互不等待，吞吐只受提交（redo fsync）限制。", upsertWorkers),
+			Query:       upsertQuery,
+			Args:        []interface{}{upsertHotKey, 1},
+			Setup:       ensureUpsertTable,
+			Run:         runConcurrentUpserts(false, 1),
+		},
+		{
+			Type:        "热点键 upsert 争用",
+			Name:        "多 worker 逐条 upsert 同一热点键",
+			Description: "同样的写入量全部落在同一个唯一键上：每条 upsert 都要对该行加排他锁直到提交，worker 只能排队串行执行，吞吐随并发不升反降，行锁等待大量出现。",
+			Query:       upsertQuery,
+			Args:        []interface{}{upsertHotKey, 1},
+			Setup:       ensureUpsertTable,
+			Run:         runConcurrentUpserts(true, 1),
+		},
+		{
+			Type:        "热点键 upsert 争用",
+			Name:        "多 worker 批量合并后 upsert 热点键",
+			Description: fmt.Sprintf("仍是同一热点键，但每个 worker 先在本地累加 %d 次增量，再以一条 hits = hits + %d 的 upsert 提交，加锁次数降为 1/%d，排队几乎消失。", upsertBatch, upsertBatch, upsertBatch),
+			Query:       upsertQuery,
+			Args:        []interface{}{upsertHotKey, upsertBatch},
+			Setup:       ensureUpsertTable,
+			Run:         runConcurrentUpserts(true, upsertBatch),
+		},
+	}
+}
+
+// ensureUpsertTable creates an empty counter table keyed by a unique counter_key.
+func ensureUpsertTable(ctx context.Context, db *gorm.DB) error {
+	if err := db.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS upsert_counters (
+		id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+		counter_key VARCHAR(32) NOT NULL,
+		hits BIGINT NOT NULL,
+		UNIQUE KEY uk_upsert_counters_key (counter_key)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`).Error; err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Exec("TRUNCATE TABLE upsert_counters").Error
+}
+
+// runConcurrentUpserts applies the same total increments from upsertWorkers goroutines, each
+// statement adding batch, either to one shared key or to a key per worker.
+func runConcurrentUpserts(hot bool, batch int) func(context.Context, *gorm.DB, *ScenarioResult) error {
+	return func(ctx context.Context, db *gorm.DB, res *ScenarioResult) error {
+		waitsBefore, _ := globalStatusInt(ctx, db, "Innodb_row_lock_waits")
+		waitTimeBefore, _ := globalStatusInt(ctx, db, "Innodb_row_lock_time")
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			firstErr error
+		)
+		statements := upsertPerWorker / batch
+		start := time.Now()
+		for w := 0; w < upsertWorkers; w++ {
+			key := upsertHotKey
+			if !hot {
+				key = fmt.Sprintf("worker-%d", w)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < statements; i++ {
+					if err := db.WithContext(ctx).Exec(upsertQuery, key, batch).Error; err != nil {
+						mu.Lock()
+						if firstErr == nil {
+							firstErr = err
+						}
+						mu.Unlock()
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		res.Duration = time.Since(start)
+		if firstErr != nil {
+			return firstErr
+		}
+		waitsAfter, _ := globalStatusInt(ctx, db, "Innodb_row_lock_waits")
+		waitTimeAfter, _ := globalStatusInt(ctx, db, "Innodb_row_lock_time")
+
+		var total int64
+		if err := db.WithContext(ctx).Raw("SELECT COALESCE(SUM(hits), 0) FROM upsert_counters").Row().Scan(&total); err != nil {
+			return err
+		}
+		res.RowCount = int64(upsertWorkers * statements)
+		res.Notes = append(res.Notes,
+			fmt.Sprintf("%d statements, %.0f increments/s (total hits %d)", res.RowCount, float64(total)/res.Duration.Seconds(), total),
+			fmt.Sprintf("row lock waits %d, lock wait time %dms (Innodb_row_lock_waits / Innodb_row_lock_time)", waitsAfter-waitsBefore, waitTimeAfter-waitTimeBefore))
+		return nil
+	}
+}
@@ -2,26 +2,66 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"time"
 
+	"mysql-slow-query-lab/internal/retry"
+
 	"gorm.io/gorm"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
+// Seed methods accepted by SeedConfig.Method.
+const (
+	SeedMethodInsert   = "insert"
+	SeedMethodLoadData = "loaddata"
+)
 
 // SeedConfig controls how many orders are inserted for experiments.
 type SeedConfig struct {
 	Orders    int
 	BatchSize int
+	// Method is SeedMethodInsert (batched INSERT, the default) or SeedMethodLoadData, which
+	// streams CSV batches through LOAD DATA LOCAL INFILE and falls back to INSERT when the
+	// server disallows it.
+	Method string
+	// Distribution is how customer ids spread over orders: DistributionUniform (the default)
+	// or DistributionZipf, where a few customers own most orders; ZipfS is its exponent.
+	Distribution string
+	ZipfS        float64
+	// TimeDistribution shapes created_at over the last TimeSpanDays days (DefaultTimeSpanDays
+	// when zero): TimeUniform (the default), TimeBusinessHours or TimeRecent.
+	TimeDistribution string
+	TimeSpanDays     int
+	// Dataset sets the random seed, time anchor and realism of the generated data. When left
+	// zero, SeedDataset uses the options installed with WithDataset, which the scenario setup
+	// hooks see as well.
+	Dataset DatasetOptions
+	// Retry reruns a batch whose transaction failed on a transient connection error, so a
+	// long seeding run survives a brief server hiccup; the zero Policy gives up at once.
+	Retry retry.Policy
+	// Logf, when set, receives progress (rows written, rows/s, ETA) and messages such as a
+	// fallback to INSERT; it defaults to the logger installed with WithProgress.
+	Logf func(format string, args ...interface{})
 }
 
 // EnsureSchema applies the required database schema.
 func EnsureSchema(db *gorm.DB) error {
-	return db.AutoMigrate(&Order{})
+	v, err := DetectServerVersion(context.Background(), db)
+	if err != nil {
+		return err
+	}
+	if v.MariaDB() && !v.AtLeast(mariaDBMinVersion) {
+		return fmt.Errorf("MariaDB %s is not supported; the lab needs %s or later", v, mariaDBMinVersion)
+	}
+	if v.TiDB() && !v.AtLeast(tidbMinVersion) {
+		return fmt.Errorf("TiDB %s is not supported; the lab needs %s or later", v, tidbMinVersion)
+	}
+	if err := db.AutoMigrate(&Order{}, &Customer{}, &SeedState{}); err != nil {
+		return err
+	}
+	return applyMigrations(db, v)
 }
 
 // SeedDataset populates the database with deterministic synthetic data.
@@ -32,47 +72,170 @@ func SeedDataset(ctx context.Context, db *gorm.DB, cfg SeedConfig) error {
 	if cfg.Orders < CoveringCustomerTarget {
 		cfg.Orders = CoveringCustomerTarget
 	}
+	switch cfg.Method {
+	case "":
+		cfg.Method = SeedMethodInsert
+	case SeedMethodInsert:
+	case SeedMethodLoadData:
+	default:
+		return fmt.Errorf("unknown seed method %q (want %s or %s)", cfg.Method, SeedMethodInsert, SeedMethodLoadData)
+	}
+	if cfg.ZipfS == 0 {
+		cfg.ZipfS = DefaultZipfS
+	}
+	if cfg.TimeSpanDays == 0 {
+		cfg.TimeSpanDays = DefaultTimeSpanDays
+	}
+	// Validated up front so a typo fails even when the dataset is already seeded.
+	if _, err := newCustomerPicker(cfg.Distribution, cfg.ZipfS, nil); err != nil {
+		return err
+	}
+	if _, err := newCreatedPicker(cfg.TimeDistribution, cfg.TimeSpanDays, nil); err != nil {
+		return err
+	}
+	if cfg.Logf == nil {
+		cfg.Logf, _ = ctx.Value(progressKey{}).(func(format string, args ...interface{}))
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(string, ...interface{}) {}
+	}
+	ctx = withStage(WithProgress(ctx, cfg.Logf), "seed")
+	if cfg.Dataset != (DatasetOptions{}) {
+		ctx = WithDataset(ctx, cfg.Dataset)
+	}
 	return seedOrders(ctx, db, cfg)
 }
 
+// seedOrdersState is the SeedState row of the orders dataset.
+const seedOrdersState = "orders"
+
 func seedOrders(ctx context.Context, db *gorm.DB, cfg SeedConfig) error {
-	var existing int64
-	if err := db.WithContext(ctx).Model(&Order{}).Count(&existing).Error; err != nil {
+	written, err := seedCheckpoint(ctx, db)
+	if err != nil {
 		return err
 	}
-	if int(existing) >= cfg.Orders {
+	if int(written) >= cfg.Orders {
 		return nil
 	}
+	if written > 0 {
+		cfg.Logf("resuming orders seed at row %d of %d", written, cfg.Orders)
+	}
 
-	toCreate := cfg.Orders - int(existing)
-	batch := make([]Order, 0, cfg.BatchSize)
-	now := time.Now()
-	rnd := rand.New(rand.NewSource(42))
-	start := int(existing)
+	toCreate := cfg.Orders - int(written)
+	batchSize := cfg.BatchSize
+	if cfg.Method == SeedMethodLoadData {
+		batchSize = max(batchSize, loadDataBatchRows)
+	}
+	batch := make([]Order, 0, batchSize)
+	now := datasetNow(ctx)
+	rnd := datasetRand(ctx, streamOrders)
+	pickCustomer, err := newCustomerPicker(cfg.Distribution, cfg.ZipfS, rnd)
+	if err != nil {
+		return err
+	}
+	pickCreated, err := newCreatedPicker(cfg.TimeDistribution, cfg.TimeSpanDays, rnd)
+	if err != nil {
+		return err
+	}
+	style := datasetStyle(ctx)
+	prog := newProgress(ctx, "seed orders", int64(toCreate))
 
-	for i := 0; i < toCreate; i++ {
-		order := buildSyntheticOrder(start+i, rnd, now)
+	for i := 0; i < cfg.Orders; i++ {
+		// Rows before the checkpoint are generated again only to replay the random sequence.
+		order := buildSyntheticOrder(i, rnd, now, pickCustomer, pickCreated, style)
+		if i < int(written) {
+			continue
+		}
 		batch = append(batch, order)
 
-		if len(batch) == cfg.BatchSize || i == toCreate-1 {
-			if err := db.WithContext(ctx).Create(&batch).Error; err != nil {
+		if len(batch) == batchSize || i == cfg.Orders-1 {
+			retried := false
+			err := cfg.Retry.Do(ctx, "seed orders batch", func() error {
+				if retried {
+					// The failed attempt may have committed before the connection dropped.
+					done, err := seedCheckpoint(ctx, db)
+					if err != nil || done >= int64(i+1) {
+						return err
+					}
+					// Ids assigned by the rolled-back INSERTs are not taken; let the server pick again.
+					for j := range batch {
+						batch[j].ID = 0
+					}
+				}
+				retried = true
+				return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+					if err := insertOrders(ctx, tx, &cfg, batch); err != nil {
+						return err
+					}
+					return tx.Save(&SeedState{Name: seedOrdersState, RowsWritten: int64(i + 1)}).Error
+				})
+			})
+			if err != nil {
 				return err
 			}
+			prog.add(len(batch))
 			batch = batch[:0]
 		}
 	}
 	return nil
 }
 
-func buildSyntheticOrder(globalIdx int, rnd *rand.Rand, now time.Time) Order {
+// SeedStatus returns the orders seeding checkpoint, the zero SeedState when nothing has been
+// seeded with checkpoints yet. Unlike seeding itself it never writes.
+func SeedStatus(ctx context.Context, db *gorm.DB) (SeedState, error) {
+	var state SeedState
+	err := db.WithContext(ctx).Where("name = ?", seedOrdersState).Limit(1).Find(&state).Error
+	return state, err
+}
+
+// seedCheckpoint returns how many seeded orders have been committed. Databases seeded before
+// checkpoints existed get one from the current row count, the best guess available.
+func seedCheckpoint(ctx context.Context, db *gorm.DB) (int64, error) {
+	var state SeedState
+	err := db.WithContext(ctx).Where("name = ?", seedOrdersState).Limit(1).Find(&state).Error
+	if err != nil || state.Name != "" {
+		return state.RowsWritten, err
+	}
+	var existing int64
+	if err := db.WithContext(ctx).Model(&Order{}).Count(&existing).Error; err != nil {
+		return 0, err
+	}
+	if existing == 0 {
+		return 0, nil
+	}
+	return existing, db.WithContext(ctx).Create(&SeedState{Name: seedOrdersState, RowsWritten: existing}).Error
+}
+
+// rewindSeedCheckpoint makes the next seeding run write n more orders, for scenarios that delete seeded rows.
+func rewindSeedCheckpoint(ctx context.Context, db *gorm.DB, n int64) error {
+	return db.WithContext(ctx).Model(&SeedState{}).Where("name = ?", seedOrdersState).
+		Update("rows_written", gorm.Expr("GREATEST(rows_written - ?, 0)", n)).Error
+}
+
+// insertOrders writes one batch with the configured method, switching cfg to INSERT for the
+// rest of the run if the server refuses LOAD DATA LOCAL INFILE.
+func insertOrders(ctx context.Context, db *gorm.DB, cfg *SeedConfig, batch []Order) error {
+	if cfg.Method == SeedMethodLoadData {
+		err := loadOrders(ctx, db, batch)
+		if !errors.Is(err, errLoadDataUnavailable) {
+			return err
+		}
+		cfg.Logf("%v; falling back to INSERT (enable local_infile on the server to use -seed-method=loaddata)", err)
+		cfg.Method = SeedMethodInsert
+	}
+	// A LOAD DATA sized batch is split back into INSERTs of the configured batch size.
+	return db.WithContext(ctx).CreateInBatches(&batch, cfg.BatchSize).Error
+}
+
+func buildSyntheticOrder(globalIdx int, rnd *rand.Rand, now time.Time, pickCustomer customerPicker, pickCreated createdPicker, style orderStyle) Order {
 	var customerID uint
 	if globalIdx < 1000 {
 		customerID = coveringCustomerID
 	} else {
-		customerID = uint(rnd.Intn(50000) + 1)
+		customerID = pickCustomer()
 	}
 
-	created := now.Add(-time.Duration(rnd.Intn(365*24)) * time.Hour)
+	created := pickCreated(now)
 	var shipped *time.Time
 	if rnd.Float64() > 0.3 {
 		s := created.Add(time.Duration(rnd.Intn(72)) * time.Hour)
@@ -81,14 +244,14 @@ func buildSyntheticOrder(globalIdx int, rnd *rand.Rand, now time.Time) Order {
 
 	order := Order{
 		CustomerID:      customerID,
-		CustomerName:    customerName(customerID),
+		CustomerName:    style.customerName(customerID),
 		Phone:           randomPhone(rnd),
 		Status:          randomChoiceWeighted(statuses, rnd),
 		ProductCategory: randomChoice(categories, rnd),
 		Region:          randomChoice(regions, rnd),
 		TotalAmount:     10 + rnd.Float64()*990,
-		DiscountCode:    discountCode(rnd),
-		Note:            randomChoice(loremSamples, rnd),
+		DiscountCode:    style.discountCode(rnd),
+		Note:            style.note(rnd),
 		CreatedAt:       created,
 		UpdatedAt:       created,
 		ShippedAt:       shipped,
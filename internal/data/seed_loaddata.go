@@ -0,0 +1,90 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	// loadDataBatchRows is how many orders go into one CSV batch; LOAD DATA pays off with far
+	// larger batches than multi-row INSERT.
+	loadDataBatchRows = 50000
+	loadDataReader    = "slowlab_seed_orders"
+	loadDataTime      = "2006-01-02 15:04:05.000"
+
+	// errLocalInfileDisabled is ER_CLIENT_LOCAL_FILES_DISABLED (local_infile=OFF on the server).
+	errLocalInfileDisabled = 3948
+	// errNotAllowedCommand is ER_NOT_ALLOWED_COMMAND, returned by older servers for the same reason.
+	errNotAllowedCommand = 1148
+)
+
+var loadDataColumns = []string{
+	"customer_id", "customer_name", "phone", "status", "product_category", "region",
+	"total_amount", "discount_code", "note", "created_at", "updated_at", "shipped_at",
+}
+
+// errLoadDataUnavailable means the server refuses LOAD DATA LOCAL INFILE and seeding should use INSERT.
+var errLoadDataUnavailable = errors.New("LOAD DATA LOCAL INFILE is disabled on the server")
+
+// loadOrders writes batch as CSV and streams it to the server with LOAD DATA LOCAL INFILE.
+func loadOrders(ctx context.Context, db *gorm.DB, batch []Order) error {
+	var buf bytes.Buffer
+	if err := writeOrdersCSV(&buf, batch); err != nil {
+		return err
+	}
+	mysql.RegisterReaderHandler(loadDataReader, func() io.Reader { return &buf })
+	defer mysql.DeregisterReaderHandler(loadDataReader)
+
+	err := db.WithContext(ctx).Exec(fmt.Sprintf(`LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE orders
+		CHARACTER SET utf8mb4
+		FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' ESCAPED BY ''
+		LINES TERMINATED BY '\n'
+		(%s)`, loadDataReader, strings.Join(loadDataColumns, ", "))).Error
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) && (myErr.Number == errLocalInfileDisabled || myErr.Number == errNotAllowedCommand) {
+		return fmt.Errorf("%w: %v", errLoadDataUnavailable, err)
+	}
+	return err
+}
+
+// writeOrdersCSV encodes orders in loadDataColumns order. With ESCAPED BY ” an unquoted NULL
+// loads as SQL NULL, and times are written in the session's local zone like gorm does (loc=Local).
+func writeOrdersCSV(w io.Writer, batch []Order) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, len(loadDataColumns))
+	for _, o := range batch {
+		shipped := "NULL"
+		if o.ShippedAt != nil {
+			shipped = o.ShippedAt.In(time.Local).Format(loadDataTime)
+		}
+		record = append(record[:0],
+			strconv.FormatUint(uint64(o.CustomerID), 10),
+			o.CustomerName,
+			o.Phone,
+			o.Status,
+			o.ProductCategory,
+			o.Region,
+			strconv.FormatFloat(o.TotalAmount, 'f', -1, 64),
+			o.DiscountCode,
+			o.Note,
+			o.CreatedAt.In(time.Local).Format(loadDataTime),
+			o.UpdatedAt.In(time.Local).Format(loadDataTime),
+			shipped,
+		)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,119 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// implicitCommitPrefixes lists statement prefixes that make MySQL commit the current transaction before running.
+// See https://dev.mysql.com/doc/refman/8.0/en/implicit-commit.html.
+var implicitCommitPrefixes = []string{
+	"ALTER ", "CREATE ", "DROP ", "RENAME ", "TRUNCATE ",
+	"LOCK TABLES", "UNLOCK TABLES", "ANALYZE ", "OPTIMIZE ", "REPAIR ", "CACHE INDEX", "LOAD INDEX",
+	"GRANT ", "REVOKE ", "SET PASSWORD", "INSTALL ", "UNINSTALL ", "FLUSH ", "RESET ",
+	"BEGIN", "START TRANSACTION", "START REPLICA", "STOP REPLICA", "START SLAVE", "STOP SLAVE", "CHANGE REPLICATION", "CHANGE MASTER",
+}
+
+// causesImplicitCommit reports whether stmt is documented to implicitly commit an open transaction.
+func causesImplicitCommit(stmt string) bool {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(stmt), " "))
+	if strings.HasPrefix(normalized, "CREATE TEMPORARY ") || strings.HasPrefix(normalized, "DROP TEMPORARY ") {
+		return false
+	}
+	for _, prefix := range implicitCommitPrefixes {
+		if strings.HasPrefix(normalized, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunSetup prepares a scenario the way RunScenarios does before running it: the Setup hook,
+// then SetupSQL. Failures are *SetupError; the returned warnings are worth surfacing to the user.
+func RunSetup(ctx context.Context, db *gorm.DB, sc Scenario) ([]string, error) {
+	ctx = withStage(ctx, "setup: "+sc.Name)
+	if sc.Setup != nil {
+		if err := sc.Setup(ctx, db); err != nil {
+			return nil, &SetupError{Stage: "setup", Err: err}
+		}
+	}
+	warnings, err := runSetupSQL(ctx, db, sc)
+	if err != nil {
+		return warnings, &SetupError{Stage: "setup sql", Err: err}
+	}
+	return warnings, nil
+}
+
+// runSetupSQL executes the scenario's SetupSQL statements and returns warnings worth surfacing to the user.
+// With SetupInTx the statements share one transaction; any statement that ends that transaction early
+// (detected through performance_schema, or by statement type when that is unavailable) is reported, since
+// everything after it silently runs in autocommit mode and can no longer be rolled back.
+func runSetupSQL(ctx context.Context, db *gorm.DB, sc Scenario) ([]string, error) {
+	if len(sc.SetupSQL) == 0 {
+		return nil, nil
+	}
+
+	if !sc.SetupInTx {
+		for _, stmt := range sc.SetupSQL {
+			if err := db.WithContext(ctx).Exec(stmt).Error; err != nil {
+				return nil, fmt.Errorf("%s: %w", abbreviateSQL(stmt), err)
+			}
+		}
+		return nil, nil
+	}
+
+	var warnings []string
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		threadID, err := currentThreadID(tx)
+		if err != nil {
+			threadID = 0
+		}
+		for _, stmt := range sc.SetupSQL {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("%s: %w", abbreviateSQL(stmt), err)
+			}
+			if len(warnings) > 0 {
+				// Only the first implicit commit matters; the rest of the batch already runs outside the transaction.
+				continue
+			}
+			ended, known := transactionEnded(tx, threadID)
+			if !known {
+				ended = causesImplicitCommit(stmt)
+			}
+			if ended {
+				warnings = append(warnings, fmt.Sprintf("setup statement %q implicitly committed the setup transaction; later statements are not atomic", abbreviateSQL(stmt)))
+			}
+		}
+		return nil
+	})
+	return warnings, err
+}
+
+// transactionEnded checks performance_schema to see whether the thread's transaction is still active.
+// known is false when the server-side check is unavailable (e.g. missing privileges or consumer disabled).
+func transactionEnded(tx *gorm.DB, threadID uint64) (ended bool, known bool) {
+	if threadID == 0 {
+		return false, false
+	}
+	var states []string
+	if err := tx.Raw("SELECT STATE FROM performance_schema.events_transactions_current WHERE THREAD_ID = ?", threadID).
+		Scan(&states).Error; err != nil || len(states) == 0 {
+		return false, false
+	}
+	return states[0] != "ACTIVE", true
+}
+
+func abbreviateSQL(stmt string) string {
+	return truncateRunes(strings.Join(strings.Fields(stmt), " "), 60)
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
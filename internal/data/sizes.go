@@ -0,0 +1,121 @@
+package data
+
+import (
+	"context"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// TableSize is the on-disk footprint of one lab table as InnoDB reports it.
+type TableSize struct {
+	Table string `gorm:"column:table_name"`
+	// Rows is the estimate from information_schema.TABLES, not an exact count.
+	Rows        int64 `gorm:"column:table_rows"`
+	DataBytes   int64
+	IndexBytes  int64
+	FreeBytes   int64
+	Indexes     []IndexSize
+	IndexesRead bool
+}
+
+// IndexSize is one index of a table: its columns in key order and the pages it occupies.
+type IndexSize struct {
+	Name    string
+	Columns string
+	Bytes   int64
+}
+
+// TableSizes reports data, index and per-index sizes of the lab tables present in this
+// database. The per-index sizes come from mysql.innodb_index_stats, which needs SELECT on the
+// mysql schema; without it Indexes only lists the columns and IndexesRead stays false. With
+// analyze the statistics are refreshed first, since both sources lag behind recent writes.
+func TableSizes(ctx context.Context, db *gorm.DB, analyze bool) ([]TableSize, error) {
+	var sizes []TableSize
+	err := db.WithContext(ctx).Raw(`SELECT TABLE_NAME AS table_name, TABLE_ROWS AS table_rows,
+		DATA_LENGTH AS data_bytes, INDEX_LENGTH AS index_bytes, DATA_FREE AS free_bytes
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME IN ?
+		ORDER BY DATA_LENGTH + INDEX_LENGTH DESC, TABLE_NAME`, append(slices.Clone(coreTables), helperTables()...)).
+		Scan(&sizes).Error
+	if err != nil || len(sizes) == 0 {
+		return sizes, err
+	}
+	if analyze {
+		// information_schema caches table statistics, so the session reading them again after
+		// ANALYZE TABLE must not use the cached copy.
+		err := db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+			for _, t := range sizes {
+				if err := conn.Exec("ANALYZE TABLE " + t.Table).Error; err != nil {
+					return err
+				}
+			}
+			if err := conn.Exec("SET SESSION information_schema_stats_expiry = 0").Error; err != nil {
+				return err
+			}
+			var err error
+			sizes, err = TableSizes(ctx, conn, false)
+			return err
+		})
+		return sizes, err
+	}
+
+	tables := make([]string, len(sizes))
+	for i, t := range sizes {
+		tables[i] = t.Table
+	}
+	var columns []struct {
+		TableName  string
+		IndexName  string
+		KeyColumns string
+	}
+	err = db.WithContext(ctx).Raw(`SELECT TABLE_NAME AS table_name, INDEX_NAME AS index_name,
+		GROUP_CONCAT(COALESCE(COLUMN_NAME, CONCAT('(', EXPRESSION, ')')) ORDER BY SEQ_IN_INDEX SEPARATOR ', ') AS key_columns
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME IN ?
+		GROUP BY TABLE_NAME, INDEX_NAME ORDER BY TABLE_NAME, INDEX_NAME`, tables).Scan(&columns).Error
+	if err != nil {
+		return nil, err
+	}
+	var pages []struct {
+		TableName string
+		IndexName string
+		Bytes     int64
+	}
+	statsErr := db.WithContext(ctx).Raw(`SELECT table_name, index_name, stat_value * @@innodb_page_size AS bytes
+		FROM mysql.innodb_index_stats
+		WHERE database_name = DATABASE() AND table_name IN ? AND stat_name = 'size'`, tables).Scan(&pages).Error
+
+	for i := range sizes {
+		t := &sizes[i]
+		t.IndexesRead = statsErr == nil
+		for _, c := range columns {
+			if c.TableName != t.Table {
+				continue
+			}
+			idx := IndexSize{Name: c.IndexName, Columns: c.KeyColumns, Bytes: -1}
+			for _, p := range pages {
+				if p.TableName == t.Table && p.IndexName == c.IndexName {
+					idx.Bytes = p.Bytes
+				}
+			}
+			t.Indexes = append(t.Indexes, idx)
+		}
+		// The clustered index holds the rows themselves, so it leads; secondary indexes follow by size.
+		slices.SortStableFunc(t.Indexes, func(a, b IndexSize) int {
+			switch {
+			case a.Name == "PRIMARY":
+				return -1
+			case b.Name == "PRIMARY":
+				return 1
+			case a.Bytes != b.Bytes:
+				if a.Bytes > b.Bytes {
+					return -1
+				}
+				return 1
+			}
+			return 0
+		})
+	}
+	return sizes, nil
+}
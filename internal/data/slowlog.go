@@ -0,0 +1,35 @@
+package data
+
+import (
+	"mysql-slow-query-lab/internal/slowlog"
+	"mysql-slow-query-lab/pkg/fingerprint"
+)
+
+// AttachSlowLog pairs scenario results with the server's slow log entries of their queries,
+// read after the run, and returns how many were paired. Scenarios run one after another, so
+// each result takes the first not yet paired entry with the same fingerprint; statements from
+// other sessions and the EXPLAINs around each query have other fingerprints or are skipped.
+func AttachSlowLog(results []ScenarioResult, entries []slowlog.Entry) int {
+	fingerprints := make([]string, len(entries))
+	for i, e := range entries {
+		fingerprints[i] = fingerprint.Normalize(e.SQL)
+	}
+	paired, next := 0, 0
+	for i := range results {
+		res := &results[i]
+		if res.Query == "" || res.Err != nil && ErrorKind(res.Err) != "expectation" && ErrorKind(res.Err) != "explain" {
+			continue
+		}
+		fp := fingerprint.Normalize(res.Query)
+		for j := next; j < len(entries); j++ {
+			if fingerprints[j] == fp {
+				entry := entries[j]
+				res.SlowLog = &entry
+				paired++
+				next = j + 1
+				break
+			}
+		}
+	}
+	return paired
+}
@@ -0,0 +1,19 @@
+package data
+
+import "context"
+
+type stageKey struct{}
+
+// withStage labels the statements run under ctx with the lab step they belong to, such as
+// "seed" or "setup: <scenario>", for SQL logging.
+func withStage(ctx context.Context, stage string) context.Context {
+	return context.WithValue(ctx, stageKey{}, stage)
+}
+
+// Stage returns the step label of the statements run under ctx: "seed" while seeding,
+// "setup: <scenario>" while a scenario's Setup and SetupSQL run (or "setup: <table>" while a
+// table the scenarios share is built), "" otherwise.
+func Stage(ctx context.Context) string {
+	stage, _ := ctx.Value(stageKey{}).(string)
+	return stage
+}
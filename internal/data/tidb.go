@@ -0,0 +1,182 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// tidbMinVersion is the oldest TiDB the lab supports, the first LTS release with the invisible
+// indexes and multi-valued indexes the schema and JSON scenarios use.
+const tidbMinVersion = "7.1.0"
+
+// TiDBSupport describes how a scenario behaves on TiDB. The zero value runs it unchanged;
+// MySQL MinVersion gates do not apply, as TiDB implements the MySQL 8.0 syntax the scenarios
+// use independently of the version it reports.
+type TiDBSupport struct {
+	// Skip, when set, is why the scenario cannot run on TiDB.
+	Skip string
+	// Note is added to the result on TiDB, for plans or behavior that differ from MySQL.
+	Note string
+}
+
+// tidbHints are the MySQL optimizer hints TiDB understands with the same meaning.
+var tidbHints = map[string]bool{
+	"MAX_EXECUTION_TIME": true,
+}
+
+// tidbSkipReason returns why sc cannot run on TiDB, or "" when it can.
+func tidbSkipReason(sc Scenario) string {
+	switch {
+	case sc.TiDB.Skip != "":
+		return "not supported on TiDB: " + sc.TiDB.Skip
+	case sc.OptimizerSwitch != "":
+		return "TiDB accepts optimizer_switch for compatibility but its optimizer ignores it"
+	}
+	if sc.Hints != "" {
+		name, _, _ := strings.Cut(sc.Hints, "(")
+		if !tidbHints[strings.ToUpper(strings.TrimSpace(name))] {
+			return fmt.Sprintf("TiDB does not implement the MySQL hint %s", strings.TrimSpace(name))
+		}
+	}
+	return ""
+}
+
+// tidbStatement rewrites MySQL DDL for TiDB, which cannot add STORED generated columns with
+// ALTER TABLE; an indexed VIRTUAL column serves the same queries.
+func tidbStatement(stmt string) string {
+	if strings.HasPrefix(stmt, "ALTER TABLE") && strings.HasSuffix(stmt, " STORED") {
+		return strings.TrimSuffix(stmt, " STORED") + " VIRTUAL"
+	}
+	return stmt
+}
+
+// tidbExplain runs a TiDB EXPLAIN or EXPLAIN ANALYZE and returns one line per operator: the
+// indented operator id followed by the other columns in server order.
+func tidbExplain(ctx context.Context, db *gorm.DB, sql string, args ...interface{}) ([]string, error) {
+	cols, rows, err := explainTable(ctx, db, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		parts := []string{row[0]}
+		for i := 1; i < len(cols); i++ {
+			if row[i] != "" {
+				parts = append(parts, cols[i]+"="+row[i])
+			}
+		}
+		lines = append(lines, strings.Join(parts, "  "))
+	}
+	return lines, nil
+}
+
+// explainTable reads every column of a plan as text, keeping the server's column order.
+func explainTable(ctx context.Context, db *gorm.DB, query string, args ...interface{}) ([]string, [][]string, error) {
+	rows, err := db.WithContext(ctx).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	table, err := scanText(rows, len(cols))
+	return cols, table, err
+}
+
+func scanText(rows *sql.Rows, n int) ([][]string, error) {
+	var table [][]string
+	for rows.Next() {
+		values := make([]sql.NullString, n)
+		dest := make([]interface{}, n)
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make([]string, n)
+		for i, v := range values {
+			row[i] = v.String
+		}
+		table = append(table, row)
+	}
+	return table, rows.Err()
+}
+
+// tidbAccessTypes maps TiDB scan operators to the closest MySQL access type, so plan
+// expectations and the advisor read TiDB plans the same way.
+var tidbAccessTypes = map[string]string{
+	"TableFullScan":   "ALL",
+	"TableRangeScan":  "range",
+	"TableRowIDScan":  "eq_ref",
+	"IndexFullScan":   "index",
+	"IndexRangeScan":  "range",
+	"Point_Get":       "const",
+	"Batch_Point_Get": "range",
+}
+
+var (
+	tidbOperatorID = regexp.MustCompile(`([A-Za-z_]+?)(_\d+)?$`)
+	tidbIndexName  = regexp.MustCompile(`index:([^(,\s]+)`)
+)
+
+// tidbPlanRows turns the scan operators of a TiDB EXPLAIN (id, estRows, task, access object,
+// operator info) into PlanRows: the table and index come from the access object, the access
+// type from the operator, and Extra carries the operator and its info.
+func tidbPlanRows(cols []string, table [][]string) []PlanRow {
+	col := make(map[string]int, len(cols))
+	for i, name := range cols {
+		col[name] = i
+	}
+	var plan []PlanRow
+	for _, row := range table {
+		access := row[col["access object"]]
+		if !strings.HasPrefix(access, "table:") {
+			continue
+		}
+		// Ids look like "├─IndexRangeScan_8(Build)": tree drawing, operator, plan id, child role.
+		id, _, _ := strings.Cut(strings.TrimLeft(row[col["id"]], "└├│─ "), "(")
+		m := tidbOperatorID.FindStringSubmatch(id)
+		if m == nil {
+			continue
+		}
+		operator := m[1]
+		accessType, ok := tidbAccessTypes[operator]
+		if !ok {
+			continue
+		}
+		tableName, _, _ := strings.Cut(strings.TrimPrefix(access, "table:"), ",")
+		key := ""
+		switch m := tidbIndexName.FindStringSubmatch(access); {
+		case m != nil:
+			key = m[1]
+		case strings.HasPrefix(operator, "Table") && operator != "TableFullScan",
+			strings.Contains(access, "handle:"):
+			key = "PRIMARY"
+		}
+		extra := operator
+		if info := row[col["operator info"]]; info != "" {
+			extra += ": " + info
+		}
+		est, _ := strconv.ParseFloat(row[col["estRows"]], 64)
+		plan = append(plan, PlanRow{
+			ID:       int64(len(plan) + 1),
+			Table:    strings.TrimSpace(tableName),
+			Type:     accessType,
+			Key:      key,
+			Rows:     int64(math.Round(est)),
+			Filtered: 100,
+			Extra:    extra,
+		})
+	}
+	return plan
+}
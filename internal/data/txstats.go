@@ -0,0 +1,57 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// txStats describes one write transaction, read from information_schema.INNODB_TRX and
+// performance_schema before the transaction ends.
+type txStats struct {
+	affected     int64
+	rowsExamined int64
+	rowsLocked   int64
+	lockStructs  int64
+	held         time.Duration
+}
+
+// execInTx runs one write statement in an explicit transaction so its lock footprint can be read
+// before the transaction ends, then commits it or — for demonstrations that must not change
+// data — rolls it back. held spans BEGIN to the end of COMMIT/ROLLBACK, i.e. how long other
+// sessions could be blocked.
+func execInTx(ctx context.Context, db *gorm.DB, commit bool, query string, args ...interface{}) (txStats, error) {
+	var stats txStats
+	start := time.Now()
+	tx := db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return stats, tx.Error
+	}
+	err := func() error {
+		threadID, err := currentThreadID(tx)
+		if err != nil {
+			return err
+		}
+		result := tx.Exec(query, args...)
+		if result.Error != nil {
+			return result.Error
+		}
+		stats.affected = result.RowsAffected
+		if err := tx.Raw(`SELECT ROWS_EXAMINED FROM performance_schema.events_statements_history
+			WHERE THREAD_ID = ? ORDER BY EVENT_ID DESC LIMIT 1`, threadID).Row().Scan(&stats.rowsExamined); err != nil {
+			return err
+		}
+		return tx.Raw(`SELECT trx_rows_locked, trx_lock_structs FROM information_schema.INNODB_TRX
+			WHERE trx_mysql_thread_id = CONNECTION_ID()`).Row().Scan(&stats.rowsLocked, &stats.lockStructs)
+	}()
+	if err != nil || !commit {
+		if rbErr := tx.Rollback().Error; err == nil {
+			err = rbErr
+		}
+	} else {
+		err = tx.Commit().Error
+	}
+	stats.held = time.Since(start)
+	return stats, err
+}
@@ -0,0 +1,141 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// MySQL errors PREPARE reports for objects a scenario's Setup may still create.
+const (
+	errNoSuchTable   = 1146
+	errUnknownColumn = 1054
+)
+
+// ValidateScenarios checks every scenario RunScenarios would run with cfg without executing
+// any of them: the query must prepare (a server-side PREPARE, so syntax and names are checked
+// by the server itself), placeholders must match Args, MinVersion must parse and the
+// optimizer_switch must be accepted. Queries on tables that do not exist yet only pass when
+// the scenario can create them (Setup, SetupSQL or Run). All problems are returned joined.
+func ValidateScenarios(ctx context.Context, db *gorm.DB, cfg RunConfig) error {
+	if cfg.ServerVersion.Major == 0 {
+		if version, err := DetectServerVersion(ctx, db); err == nil {
+			cfg.ServerVersion = version
+		}
+	}
+	scenarios := allScenarios(cfg)
+	var errs []error
+	err := db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		for _, sc := range scenarios {
+			if !cfg.selected(sc) {
+				continue
+			}
+			for _, err := range validateScenario(ctx, conn, sc, cfg) {
+				errs = append(errs, fmt.Errorf("%s / %s: %w", sc.Type, sc.Name, err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// CheckScenario runs the checks of ValidateScenarios that need no server: Query or Run is
+// set, MinVersion parses and the query's placeholders match Args.
+func CheckScenario(sc Scenario) []error {
+	var errs []error
+	if sc.Query == "" && sc.Run == nil {
+		errs = append(errs, errors.New("neither Query nor Run is set"))
+	}
+	if sc.MinVersion != "" {
+		if _, err := ParseServerVersion(sc.MinVersion); err != nil {
+			errs = append(errs, fmt.Errorf("MinVersion: %w", err))
+		}
+	}
+	if sc.MariaDB.MinVersion != "" {
+		if _, err := ParseServerVersion(sc.MariaDB.MinVersion); err != nil {
+			errs = append(errs, fmt.Errorf("MariaDB.MinVersion: %w", err))
+		}
+	}
+	if sc.Query != "" && sc.ArgsFunc == nil {
+		if n := countPlaceholders(sc.Query); n != len(sc.Args) {
+			errs = append(errs, fmt.Errorf("query has %d placeholders but %d args", n, len(sc.Args)))
+		}
+	}
+	return errs
+}
+
+func validateScenario(ctx context.Context, conn *gorm.DB, sc Scenario, cfg RunConfig) []error {
+	errs := CheckScenario(sc)
+	if skipReason(sc, cfg.ServerVersion) != "" {
+		// The server may not even parse this scenario's syntax; runScenario skips it anyway.
+		return errs
+	}
+	if cfg.ReadOnly && writeReason(sc) != "" {
+		return errs
+	}
+	if sc.OptimizerSwitch != "" {
+		if err := conn.Exec("SET SESSION optimizer_switch = ?", optimizerSwitch(sc, cfg.ServerVersion)).Error; err != nil {
+			errs = append(errs, fmt.Errorf("optimizer_switch %q: %w", sc.OptimizerSwitch, err))
+		}
+		conn.Exec("SET SESSION optimizer_switch = DEFAULT")
+	}
+	if sc.Query == "" {
+		return errs
+	}
+	stmt, err := conn.Statement.ConnPool.PrepareContext(ctx, sc.SQL())
+	if err == nil {
+		stmt.Close()
+		return errs
+	}
+	var myErr *mysql.MySQLError
+	createsObjects := sc.Setup != nil || len(sc.SetupSQL) > 0 || sc.Run != nil
+	if errors.As(err, &myErr) && (myErr.Number == errNoSuchTable || myErr.Number == errUnknownColumn) && createsObjects {
+		return errs
+	}
+	if errors.As(err, &myErr) && myErr.Number == errNoSuchTable && cfg.Partitioned {
+		// orders_by_month is built by EnsurePartitionedOrders after seeding, before any scenario runs.
+		return errs
+	}
+	return append(errs, fmt.Errorf("prepare: %w", err))
+}
+
+// countPlaceholders counts ? outside quoted strings, quoted identifiers and comments.
+func countPlaceholders(query string) int {
+	n := 0
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; c {
+		case '?':
+			n++
+		case '\'', '"', '`':
+			for i++; i < len(query) && query[i] != c; i++ {
+				if query[i] == '\\' && c != '`' {
+					i++
+				}
+			}
+		case '/':
+			if i+1 < len(query) && query[i+1] == '*' {
+				end := strings.Index(query[i+2:], "*/")
+				if end < 0 {
+					return n
+				}
+				i += 2 + end + 1
+			}
+		case '-', '#':
+			if c == '#' || (i+2 < len(query) && query[i+1] == '-' && (query[i+2] == ' ' || query[i+2] == '\t')) {
+				end := strings.Index(query[i:], "\n")
+				if end < 0 {
+					return n
+				}
+				i += end
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,107 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Server flavors, see ServerVersion.Flavor.
+const (
+	FlavorMySQL   = "mysql"
+	FlavorMariaDB = "mariadb"
+	FlavorTiDB    = "tidb"
+)
+
+// ServerVersion is the parsed result of SELECT VERSION().
+type ServerVersion struct {
+	Raw string
+	// Flavor is FlavorMySQL, FlavorMariaDB or FlavorTiDB; Major, Minor and Patch number releases of that flavor.
+	Flavor string
+	Major  int
+	Minor  int
+	Patch  int
+}
+
+// DetectServerVersion queries and parses the server version string.
+func DetectServerVersion(ctx context.Context, db *gorm.DB) (ServerVersion, error) {
+	var raw string
+	if err := db.WithContext(ctx).Raw("SELECT VERSION()").Row().Scan(&raw); err != nil {
+		return ServerVersion{}, err
+	}
+	return ParseServerVersion(raw)
+}
+
+// ParseServerVersion parses strings such as "8.0.36", "8.0.36-debug", "5.7.44-log" or
+// "10.11.6-MariaDB-1:10.11.6+maria~ubu2204". TiDB ("8.0.11-TiDB-v7.5.1") is numbered by its
+// own release, not the MySQL version it reports compatibility with.
+func ParseServerVersion(raw string) (ServerVersion, error) {
+	v := ServerVersion{Raw: raw, Flavor: FlavorMySQL}
+	numeric := raw
+	lower := strings.ToLower(raw)
+	switch {
+	case strings.Contains(lower, "-tidb-v"):
+		v.Flavor = FlavorTiDB
+		numeric = raw[strings.Index(lower, "-tidb-v")+len("-tidb-v"):]
+	case strings.Contains(lower, "mariadb"):
+		v.Flavor = FlavorMariaDB
+		// Replication-compatible servers prefix the real version with "5.5.5-".
+		numeric = strings.TrimPrefix(numeric, "5.5.5-")
+	}
+	if i := strings.IndexFunc(numeric, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		numeric = numeric[:i]
+	}
+	parts := strings.Split(numeric, ".")
+	if len(parts) < 2 {
+		return v, fmt.Errorf("unrecognized server version %q", raw)
+	}
+	nums := make([]int, 3)
+	for i := 0; i < len(parts) && i < 3; i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return v, fmt.Errorf("unrecognized server version %q", raw)
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	return v, nil
+}
+
+// AtLeast reports whether the server is at or above min ("8.0.13"); an empty min always matches.
+// An unknown (zero) version is treated as new enough so gating never hides scenarios by accident.
+func (v ServerVersion) AtLeast(min string) bool {
+	if min == "" || v.Major == 0 {
+		return true
+	}
+	want, err := ParseServerVersion(min)
+	if err != nil {
+		return true
+	}
+	if v.Major != want.Major {
+		return v.Major > want.Major
+	}
+	if v.Minor != want.Minor {
+		return v.Minor > want.Minor
+	}
+	return v.Patch >= want.Patch
+}
+
+// MariaDB reports whether the server is MariaDB rather than MySQL.
+func (v ServerVersion) MariaDB() bool {
+	return v.Flavor == FlavorMariaDB
+}
+
+// TiDB reports whether the server is TiDB.
+func (v ServerVersion) TiDB() bool {
+	return v.Flavor == FlavorTiDB
+}
+
+func (v ServerVersion) String() string {
+	if v.Raw != "" {
+		return v.Raw
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
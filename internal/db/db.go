@@ -1,10 +1,17 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"mysql-slow-query-lab/internal/retry"
+
+	gomysql "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -18,6 +25,26 @@ type Config struct {
 	Port     string
 	Database string
 	Params   string
+	// TLS is the driver's tls parameter ("true", "skip-verify", "preferred"); empty connects in
+	// plain text unless a CA or client certificate below is set, see tlsParam.
+	TLS string
+	// TLSCA is a PEM file of the CA that signed the server certificate, for managed instances
+	// whose certificates are not in the system pool.
+	TLSCA string
+	// TLSCert and TLSKey are a PEM client certificate and key, for servers requiring X509 users.
+	TLSCert string
+	TLSKey  string
+	// TLSSkipVerify accepts any server certificate; with TLSCA it still encrypts but does not
+	// check the certificate against it.
+	TLSSkipVerify bool
+	// Pool sizes the client connection pool; zero fields take DefaultPool's values.
+	Pool Pool
+	// Retry is how Open retries transient connection errors; FromEnv and FromDSN set
+	// retry.Default.
+	Retry retry.Policy
+
+	// dsn, set by FromDSN, is used verbatim instead of the fields above.
+	dsn string
 }
 
 // FromEnv populates a Config using sensible defaults that can be overridden via environment variables.
@@ -29,20 +56,174 @@ func FromEnv() Config {
 		Port:     getEnv("MYSQL_PORT", "3307"),
 		Database: getEnv("MYSQL_DATABASE", "slowlab"),
 		Params:   getEnv("MYSQL_PARAMS", "charset=utf8mb4&parseTime=True&loc=Local"),
+
+		TLS:           os.Getenv("MYSQL_TLS"),
+		TLSCA:         os.Getenv("MYSQL_TLS_CA"),
+		TLSCert:       os.Getenv("MYSQL_TLS_CERT"),
+		TLSKey:        os.Getenv("MYSQL_TLS_KEY"),
+		TLSSkipVerify: os.Getenv("MYSQL_TLS_SKIP_VERIFY") == "true" || os.Getenv("MYSQL_TLS_SKIP_VERIFY") == "1",
+		Retry:         retry.Default,
 	}
 	return cfg
 }
 
-// Open returns a gorm DB using the provided configuration.
-func Open(cfg Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s",
+// FromDSN returns a Config that connects with a complete go-sql-driver DSN, ignoring the
+// environment; the address and database are parsed out for reports and provisioning.
+func FromDSN(dsn string) (Config, error) {
+	driverCfg, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		return Config{}, err
+	}
+	if driverCfg.Net != "tcp" {
+		return Config{User: driverCfg.User, Host: driverCfg.Addr, Database: driverCfg.DBName, Retry: retry.Default, dsn: dsn}, nil
+	}
+	host, port, err := net.SplitHostPort(driverCfg.Addr)
+	if err != nil {
+		return Config{}, fmt.Errorf("DSN address %q: %w", driverCfg.Addr, err)
+	}
+	return Config{
+		User:     driverCfg.User,
+		Password: driverCfg.Passwd,
+		Host:     host,
+		Port:     port,
+		Database: driverCfg.DBName,
+		TLS:      driverCfg.TLSConfig,
+		Retry:    retry.Default,
+		dsn:      dsn,
+	}, nil
+}
+
+// DSN returns the go-sql-driver data source name of cfg. A custom TLS configuration is
+// referenced by name, so Open must have registered it before the DSN is used elsewhere.
+func (cfg Config) DSN() string {
+	if cfg.dsn != "" {
+		return cfg.dsn
+	}
+	params := cfg.Params
+	if tls := cfg.tlsParam(); tls != "" {
+		if params != "" {
+			params += "&"
+		}
+		params += "tls=" + tls
+	}
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s",
 		cfg.User,
 		cfg.Password,
 		cfg.Host,
 		cfg.Port,
 		cfg.Database,
-		cfg.Params,
+		params,
 	)
+}
+
+// Pool holds the database/sql connection pool limits.
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// DefaultPool is the pool of a Config that does not set one.
+var DefaultPool = Pool{MaxOpenConns: 25, MaxIdleConns: 5, ConnMaxLifetime: 5 * time.Minute}
+
+// PoolFromEnv reads MYSQL_MAX_OPEN_CONNS, MYSQL_MAX_IDLE_CONNS and MYSQL_CONN_MAX_LIFETIME
+// (a Go duration such as 30s); unset variables stay zero.
+func PoolFromEnv() (Pool, error) {
+	var pool Pool
+	for _, v := range []struct {
+		key string
+		dst *int
+	}{{"MYSQL_MAX_OPEN_CONNS", &pool.MaxOpenConns}, {"MYSQL_MAX_IDLE_CONNS", &pool.MaxIdleConns}} {
+		if raw := os.Getenv(v.key); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return Pool{}, fmt.Errorf("%s=%q: want a non-negative integer", v.key, raw)
+			}
+			*v.dst = n
+		}
+	}
+	if raw := os.Getenv("MYSQL_CONN_MAX_LIFETIME"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return Pool{}, fmt.Errorf("MYSQL_CONN_MAX_LIFETIME=%q: want a duration such as 5m", raw)
+		}
+		pool.ConnMaxLifetime = d
+	}
+	return pool, nil
+}
+
+// Or returns p with its zero fields taken from fallback.
+func (p Pool) Or(fallback Pool) Pool {
+	if p.MaxOpenConns == 0 {
+		p.MaxOpenConns = fallback.MaxOpenConns
+	}
+	if p.MaxIdleConns == 0 {
+		p.MaxIdleConns = fallback.MaxIdleConns
+	}
+	if p.ConnMaxLifetime == 0 {
+		p.ConnMaxLifetime = fallback.ConnMaxLifetime
+	}
+	return p
+}
+
+// Open returns a gorm DB using the provided configuration, retrying transient connection
+// errors (the server refusing or dropping connections) with cfg.Retry.
+func Open(cfg Config) (*gorm.DB, error) {
+	var gdb *gorm.DB
+	err := cfg.Retry.Do(context.Background(), "connect", func() error {
+		var err error
+		gdb, err = open(cfg)
+		return err
+	})
+	return gdb, err
+}
+
+func open(cfg Config) (*gorm.DB, error) {
+	if err := cfg.registerTLS(); err != nil {
+		return nil, err
+	}
+	return OpenDSN(cfg.DSN(), cfg.Pool)
+}
+
+// dsnHasParam reports whether the query string of dsn sets name.
+func dsnHasParam(dsn, name string) bool {
+	i := strings.LastIndexByte(dsn, '?')
+	if i < 0 {
+		return false
+	}
+	for _, param := range strings.Split(dsn[i+1:], "&") {
+		if key, _, _ := strings.Cut(param, "="); key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DSNTarget describes the server and database of a DSN as host:port/database, for reports.
+func DSNTarget(dsn string) string {
+	cfg, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		return "invalid DSN"
+	}
+	return cfg.Addr + "/" + cfg.DBName
+}
+
+// OpenDSN returns a gorm DB for a go-sql-driver DSN with the given pool (zero fields take
+// DefaultPool's values). parseTime is switched on, as the lab scans DATETIME columns into time.Time,
+// and loc defaults to Local like MYSQL_PARAMS: seeding writes local times, which the driver would
+// otherwise store as UTC.
+func OpenDSN(dsn string, pool Pool) (*gorm.DB, error) {
+	driverCfg, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if hasLoc := dsnHasParam(dsn, "loc"); !driverCfg.ParseTime || !hasLoc {
+		driverCfg.ParseTime = true
+		if !hasLoc {
+			driverCfg.Loc = time.Local
+		}
+		dsn = driverCfg.FormatDSN()
+	}
 
 	gormCfg := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Warn),
@@ -58,13 +239,48 @@ func Open(cfg Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	sqlDB.SetConnMaxLifetime(5 * time.Minute)
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetMaxIdleConns(5)
+	pool = pool.Or(DefaultPool)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
 
 	return gdb, nil
 }
 
+// OpenWait tries to connect every second, instead of with cfg.Retry's backoff, until the
+// server accepts connections or timeout passes, for a server that is still starting (a
+// freshly provisioned container).
+func OpenWait(ctx context.Context, cfg Config, timeout time.Duration) (*gorm.DB, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		gdb, err := open(cfg)
+		if err == nil {
+			return gdb, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("server not ready after %s: %w", timeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// DropIdle closes the idle pooled connections, so the next queries run in new sessions that
+// pick up global variables changed since (session values are copied at connect time). pool
+// is the one gdb was opened with, whose idle limit is restored afterwards.
+func DropIdle(gdb *gorm.DB, pool Pool) error {
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxIdleConns(0)
+	sqlDB.SetMaxIdleConns(pool.Or(DefaultPool).MaxIdleConns)
+	return nil
+}
+
 func getEnv(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
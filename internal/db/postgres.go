@@ -0,0 +1,54 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PostgresConfig captures the connection parameters of the optional PostgreSQL comparison database.
+type PostgresConfig struct {
+	User     string
+	Password string
+	Host     string
+	Port     string
+	Database string
+	SSLMode  string
+}
+
+// PostgresFromEnv reads the PG_* environment variables, with the defaults of the postgres
+// service in docker-compose.yml.
+func PostgresFromEnv() PostgresConfig {
+	return PostgresConfig{
+		User:     getEnv("PG_USER", "slowuser"),
+		Password: getEnv("PG_PASSWORD", "slowpass"),
+		Host:     getEnv("PG_HOST", "127.0.0.1"),
+		Port:     getEnv("PG_PORT", "5433"),
+		Database: getEnv("PG_DATABASE", "slowlab"),
+		SSLMode:  getEnv("PG_SSLMODE", "disable"),
+	}
+}
+
+// OpenPostgres returns a gorm DB for the PostgreSQL comparison database.
+func OpenPostgres(cfg PostgresConfig) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database, cfg.SSLMode)
+	gdb, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetConnMaxLifetime(5 * time.Minute)
+	sqlDB.SetMaxOpenConns(10)
+	sqlDB.SetMaxIdleConns(DefaultPool.MaxIdleConns)
+	return gdb, nil
+}
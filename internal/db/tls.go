@@ -0,0 +1,58 @@
+package db
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	gomysql "github.com/go-sql-driver/mysql"
+)
+
+// tlsConfigName is the name the custom TLS configuration is registered under with the driver.
+const tlsConfigName = "slowlab"
+
+// customTLS reports whether the connection needs a TLS configuration of its own: the driver's
+// named modes cannot carry a CA or a client certificate.
+func (cfg Config) customTLS() bool {
+	return cfg.TLSCA != "" || cfg.TLSCert != ""
+}
+
+// tlsParam returns the value of the DSN's tls parameter.
+func (cfg Config) tlsParam() string {
+	switch {
+	case cfg.customTLS():
+		return tlsConfigName
+	case cfg.TLSSkipVerify:
+		return "skip-verify"
+	}
+	return cfg.TLS
+}
+
+// registerTLS builds the custom TLS configuration from the CA and client certificate files and
+// registers it with the driver; it is a no-op for the named modes.
+func (cfg Config) registerTLS() error {
+	if !cfg.customTLS() {
+		return nil
+	}
+	tlsCfg := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.TLSSkipVerify}
+	if cfg.TLSCA != "" {
+		pem, err := os.ReadFile(cfg.TLSCA)
+		if err != nil {
+			return fmt.Errorf("MYSQL_TLS_CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("MYSQL_TLS_CA: no PEM certificates in %s", cfg.TLSCA)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if cfg.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return fmt.Errorf("MYSQL_TLS_CERT/MYSQL_TLS_KEY: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return gomysql.RegisterTLSConfig(tlsConfigName, tlsCfg)
+}
@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// overrideFile is written into ConfDir so it sorts after slow.cnf and wins on conflicting settings.
+const overrideFile = "zz-slowlab-override.cnf"
+
+// Config locates the docker compose project that runs the lab's MySQL service.
+type Config struct {
+	ComposeFile string
+	Service     string
+	// ConfDir is the host directory mounted at /etc/mysql/conf.d.
+	ConfDir string
+}
+
+// FromEnv returns the compose settings, overridable via SLOWLAB_COMPOSE_FILE and SLOWLAB_MYSQL_SERVICE.
+func FromEnv() Config {
+	return Config{
+		ComposeFile: getEnv("SLOWLAB_COMPOSE_FILE", "docker-compose.yml"),
+		Service:     getEnv("SLOWLAB_MYSQL_SERVICE", "mysql"),
+		ConfDir:     getEnv("SLOWLAB_MYSQL_CONF_DIR", filepath.Join("mysql", "conf.d")),
+	}
+}
+
+// Restart restarts the MySQL service; callers still need to wait for the server to accept connections.
+func (c Config) Restart(ctx context.Context) error {
+	return c.compose(ctx, "restart", c.Service)
+}
+
+// ApplyOverride writes settings as a [mysqld] override file and restarts the service so
+// non-dynamic variables take effect; empty settings remove the override.
+func (c Config) ApplyOverride(ctx context.Context, settings map[string]string) error {
+	if err := writeOverride(c.ConfDir, settings); err != nil {
+		return err
+	}
+	return c.Restart(ctx)
+}
+
+// writeOverride writes settings to the override file in confDir, or removes it when empty.
+func writeOverride(confDir string, settings map[string]string) error {
+	path := filepath.Join(confDir, overrideFile)
+	if len(settings) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("# generated by slowlab experiments; removed when the experiment finishes\n[mysqld]\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s = %s\n", k, settings[k])
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// DiskFree runs df inside the MySQL container and returns free and total bytes of the filesystem holding path.
+func (c Config) DiskFree(ctx context.Context, path string) (free, total uint64, err error) {
+	out, err := c.composeOutput(ctx, "exec", "-T", c.Service, "df", "-Pk", path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseDF(out)
+}
+
+func parseDF(out string) (free, total uint64, err error) {
+	// POSIX format: a header line, then "filesystem 1024-blocks used available capacity mount".
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) < 4 {
+		return 0, 0, fmt.Errorf("unexpected df output: %q", out)
+	}
+	totalKB, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected df output: %q", out)
+	}
+	freeKB, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected df output: %q", out)
+	}
+	return freeKB << 10, totalKB << 10, nil
+}
+
+func (c Config) compose(ctx context.Context, args ...string) error {
+	_, err := c.composeOutput(ctx, args...)
+	return err
+}
+
+func (c Config) composeOutput(ctx context.Context, args ...string) (string, error) {
+	full := append([]string{"compose", "-f", c.ComposeFile}, args...)
+	out, err := exec.CommandContext(ctx, "docker", full...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", strings.Join(full, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func getEnv(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
@@ -0,0 +1,155 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"mysql-slow-query-lab/internal/db"
+	labmysql "mysql-slow-query-lab/mysql"
+)
+
+// labUser is the account the embedded init scripts grant the lab privileges to.
+const labUser = "slowuser"
+
+// Container is a MySQL container slowlab runs itself with plain docker (-provision docker),
+// for machines without the compose project checked out.
+type Container struct {
+	Name  string
+	Image string
+	// Volume holds the data directory, so the seeded dataset survives restarts.
+	Volume string
+	// StateDir receives the conf.d and init files mounted into the container.
+	StateDir string
+}
+
+// ContainerFromEnv returns the provisioning settings, overridable via SLOWLAB_CONTAINER,
+// SLOWLAB_MYSQL_IMAGE and SLOWLAB_STATE_DIR.
+func ContainerFromEnv() Container {
+	name := getEnv("SLOWLAB_CONTAINER", "slowlab-mysql")
+	stateDir := os.Getenv("SLOWLAB_STATE_DIR")
+	if stateDir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		stateDir = filepath.Join(base, "slowlab", name)
+	}
+	return Container{
+		Name:     name,
+		Image:    getEnv("SLOWLAB_MYSQL_IMAGE", "mysql:8.0"),
+		Volume:   name + "-data",
+		StateDir: stateDir,
+	}
+}
+
+// Start makes sure the container runs with the lab's server settings and the credentials,
+// database and port of cfg: it creates the container when missing, starts it when stopped and
+// leaves a running one alone. It reports what it did ("created", "started" or "running");
+// the server may still be initializing when it returns.
+func (c Container) Start(ctx context.Context, cfg db.Config) (string, error) {
+	switch host := strings.ToLower(cfg.Host); host {
+	case "127.0.0.1", "localhost", "::1":
+	default:
+		return "", fmt.Errorf("MYSQL_HOST is %s; a provisioned container only listens on 127.0.0.1", cfg.Host)
+	}
+	out, err := dockerOutput(ctx, "inspect", "-f", "{{.State.Running}}", c.Name)
+	switch {
+	case err == nil && strings.TrimSpace(out) == "true":
+		return "running", nil
+	case err == nil:
+		if _, err := dockerOutput(ctx, "start", c.Name); err != nil {
+			return "", err
+		}
+		return "started", nil
+	}
+
+	if err := c.writeFiles(cfg); err != nil {
+		return "", err
+	}
+	args := []string{"run", "-d", "--name", c.Name, "--label", "slowlab=provisioned",
+		"-p", "127.0.0.1:" + cfg.Port + ":3306",
+		"-v", c.Volume + ":/var/lib/mysql",
+		"-v", filepath.Join(c.StateDir, "conf.d") + ":/etc/mysql/conf.d:ro",
+		"-v", filepath.Join(c.StateDir, "init") + ":/docker-entrypoint-initdb.d:ro",
+		"-e", "MYSQL_DATABASE=" + cfg.Database,
+	}
+	if cfg.User == "root" {
+		args = append(args, "-e", "MYSQL_ROOT_PASSWORD="+cfg.Password)
+	} else {
+		args = append(args, "-e", "MYSQL_ROOT_PASSWORD=rootpass", "-e", "MYSQL_USER="+cfg.User, "-e", "MYSQL_PASSWORD="+cfg.Password)
+	}
+	if _, err := dockerOutput(ctx, append(args, c.Image)...); err != nil {
+		return "", err
+	}
+	return "created", nil
+}
+
+// Restart restarts the container; callers still need to wait for the server to accept connections.
+func (c Container) Restart(ctx context.Context) error {
+	_, err := dockerOutput(ctx, "restart", c.Name)
+	return err
+}
+
+// ApplyOverride is Config.ApplyOverride for the provisioned container, whose conf.d lives in StateDir.
+func (c Container) ApplyOverride(ctx context.Context, settings map[string]string) error {
+	if err := writeOverride(filepath.Join(c.StateDir, "conf.d"), settings); err != nil {
+		return err
+	}
+	return c.Restart(ctx)
+}
+
+// DiskFree is Config.DiskFree for the provisioned container.
+func (c Container) DiskFree(ctx context.Context, path string) (free, total uint64, err error) {
+	out, err := dockerOutput(ctx, "exec", c.Name, "df", "-Pk", path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseDF(out)
+}
+
+// Remove deletes the container and its data volume.
+func (c Container) Remove(ctx context.Context) error {
+	if _, err := dockerOutput(ctx, "rm", "-f", "-v", c.Name); err != nil {
+		return err
+	}
+	_, err := dockerOutput(ctx, "volume", "rm", c.Volume)
+	return err
+}
+
+// writeFiles copies the embedded conf.d and init files into StateDir, granting the init
+// privileges to cfg.User instead of the compose default. Root needs no grants.
+func (c Container) writeFiles(cfg db.Config) error {
+	return fs.WalkDir(labmysql.Files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := labmysql.Files.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(path, "init/") {
+			if cfg.User == "root" {
+				return nil
+			}
+			content = []byte(strings.ReplaceAll(string(content), "'"+labUser+"'", "'"+cfg.User+"'"))
+		}
+		dst := filepath.Join(c.StateDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, content, 0o644)
+	})
+}
+
+func dockerOutput(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
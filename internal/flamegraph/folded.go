@@ -0,0 +1,79 @@
+// Package flamegraph exports captured performance_schema stages as folded stacks.
+//
+// The output follows the "frame;frame;frame value" format understood by
+// flamegraph.pl, inferno and speedscope, with values expressed in microseconds.
+package flamegraph
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// Fold renders the stage/wait hierarchy of a scenario result as folded-stack lines.
+// Each stage contributes its self time (duration not covered by nested waits) and each wait its own time.
+func Fold(res data.ScenarioResult) []string {
+	root := frame(res.Type) + ";" + frame(res.Name)
+	if res.Type == "" {
+		root = frame(res.Name)
+	}
+
+	lines := make([]string, 0, len(res.Stages))
+	for _, stage := range res.Stages {
+		stack := root + ";" + frame(stage.Name)
+		self := stage.Duration
+		for _, wait := range stage.Waits {
+			self -= wait.Duration
+			if us := wait.Duration.Microseconds(); us > 0 {
+				lines = append(lines, fmt.Sprintf("%s;%s %d", stack, frame(wait.Name), us))
+			}
+		}
+		if us := self.Microseconds(); us > 0 {
+			lines = append(lines, fmt.Sprintf("%s %d", stack, us))
+		}
+	}
+	return lines
+}
+
+// WriteDir writes one .folded file per scenario that captured stages and returns the written paths.
+func WriteDir(dir string, results []data.ScenarioResult) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for i, res := range results {
+		lines := Fold(res)
+		if len(lines) == 0 {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%02d-%s.folded", i+1, fileSlug(res.Name)))
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// frame strips characters that would break the folded format (frame separator and value delimiter).
+func frame(name string) string {
+	return strings.NewReplacer(";", "_", " ", "_", "\n", "_").Replace(name)
+}
+
+func fileSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if slug == "" {
+		return "scenario"
+	}
+	return slug
+}
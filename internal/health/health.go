@@ -0,0 +1,222 @@
+// Package health checks that the MySQL server can take the lab's load before a run and is
+// still in shape afterwards, so the lab does not tip over a shared or fragile instance.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Level grades a finding; Fail findings stop a run in enforce mode.
+type Level string
+
+const (
+	OK   Level = "ok"
+	Warn Level = "warn"
+	Fail Level = "fail"
+)
+
+// Finding is the outcome of one check.
+type Finding struct {
+	Check   string
+	Level   Level
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(string(f.Level)), f.Check, f.Message)
+}
+
+// Config holds the check thresholds; start from DefaultConfig.
+type Config struct {
+	// MinFreeConnections is the number of connection slots that must stay available for other clients.
+	MinFreeConnections int
+	// MinDiskFree is the free space in bytes required on the filesystem holding the datadir.
+	MinDiskFree uint64
+	// MaxReplicaLag warns when a configured replica is further behind than this.
+	MaxReplicaLag time.Duration
+	// MaxForeignTrx is the age after which another session's open transaction is reported.
+	MaxForeignTrx time.Duration
+	// DiskFree reports free and total bytes of the filesystem holding path; MySQL does not expose
+	// free disk space over SQL, so the caller supplies a way to look at the server host.
+	DiskFree func(ctx context.Context, path string) (free, total uint64, err error)
+}
+
+// DefaultConfig returns thresholds suited to the default lab dataset (a few GiB with all scenario tables).
+func DefaultConfig() Config {
+	return Config{
+		MinFreeConnections: 10,
+		MinDiskFree:        5 << 30,
+		MaxReplicaLag:      30 * time.Second,
+		MaxForeignTrx:      time.Minute,
+	}
+}
+
+// Failed reports whether any finding is at Fail level.
+func Failed(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Level == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Check runs every check and returns one finding per check; a check that cannot run
+// (missing privilege, unsupported server) is reported as Warn rather than aborting the others.
+func Check(ctx context.Context, db *gorm.DB, cfg Config) []Finding {
+	checks := []struct {
+		name string
+		run  func(context.Context, *gorm.DB, Config) (Level, string, error)
+	}{
+		{"connections", checkConnections},
+		{"disk", checkDisk},
+		{"replication", checkReplication},
+		{"transactions", checkForeignTransactions},
+	}
+	findings := make([]Finding, 0, len(checks))
+	for _, c := range checks {
+		level, msg, err := c.run(ctx, db, cfg)
+		if err != nil {
+			level, msg = Warn, "check not possible: "+err.Error()
+		}
+		findings = append(findings, Finding{Check: c.name, Level: level, Message: msg})
+	}
+	return findings
+}
+
+func checkConnections(ctx context.Context, db *gorm.DB, cfg Config) (Level, string, error) {
+	var maxConn, connected int
+	if err := db.WithContext(ctx).Raw("SELECT @@GLOBAL.max_connections").Row().Scan(&maxConn); err != nil {
+		return "", "", err
+	}
+	if err := db.WithContext(ctx).Raw(`SELECT VARIABLE_VALUE FROM performance_schema.global_status
+		WHERE VARIABLE_NAME = 'Threads_connected'`).Row().Scan(&connected); err != nil {
+		return "", "", err
+	}
+	free := maxConn - connected
+	msg := fmt.Sprintf("%d of %d connections in use, %d free", connected, maxConn, free)
+	if free < cfg.MinFreeConnections {
+		return Fail, msg + fmt.Sprintf(" (need %d)", cfg.MinFreeConnections), nil
+	}
+	return OK, msg, nil
+}
+
+func checkDisk(ctx context.Context, db *gorm.DB, cfg Config) (Level, string, error) {
+	var datadir string
+	if err := db.WithContext(ctx).Raw("SELECT @@GLOBAL.datadir").Row().Scan(&datadir); err != nil {
+		return "", "", err
+	}
+	if cfg.DiskFree == nil {
+		return Warn, fmt.Sprintf("free space of %s unknown (no way to reach the server host)", datadir), nil
+	}
+	free, total, err := cfg.DiskFree(ctx, datadir)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", datadir, err)
+	}
+	msg := fmt.Sprintf("%s: %s free of %s", datadir, gib(free), gib(total))
+	if free < cfg.MinDiskFree {
+		return Fail, msg + fmt.Sprintf(" (need %s)", gib(cfg.MinDiskFree)), nil
+	}
+	return OK, msg, nil
+}
+
+// checkReplication is a no-op on a server that is not a replica.
+func checkReplication(ctx context.Context, db *gorm.DB, cfg Config) (Level, string, error) {
+	status, err := replicaStatus(ctx, db)
+	if err != nil {
+		return "", "", err
+	}
+	if status == nil {
+		return OK, "not a replica", nil
+	}
+	io, sqlThread := first(status, "Replica_IO_Running", "Slave_IO_Running"), first(status, "Replica_SQL_Running", "Slave_SQL_Running")
+	if io != "Yes" || sqlThread != "Yes" {
+		return Fail, fmt.Sprintf("replication configured but not running (IO=%s, SQL=%s)", io, sqlThread), nil
+	}
+	lag := first(status, "Seconds_Behind_Source", "Seconds_Behind_Master")
+	var seconds int64
+	if _, err := fmt.Sscan(lag, &seconds); err == nil && time.Duration(seconds)*time.Second > cfg.MaxReplicaLag {
+		return Warn, fmt.Sprintf("replica running but %ds behind", seconds), nil
+	}
+	return OK, "replica running, lag " + lag + "s", nil
+}
+
+// replicaStatus returns the first row of SHOW REPLICA STATUS (SHOW SLAVE STATUS before 8.0.22)
+// keyed by column name, or nil when replication is not configured.
+func replicaStatus(ctx context.Context, db *gorm.DB) (map[string]string, error) {
+	rows, err := db.WithContext(ctx).Raw("SHOW REPLICA STATUS").Rows()
+	if err != nil {
+		rows, err = db.WithContext(ctx).Raw("SHOW SLAVE STATUS").Rows()
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	values := make([]sql.NullString, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	status := make(map[string]string, len(cols))
+	for i, col := range cols {
+		status[col] = values[i].String
+	}
+	return status, nil
+}
+
+func first(status map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v, ok := status[k]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// checkForeignTransactions looks for old transactions of other sessions: they hold undo history
+// and locks, so the lab's bulk writes would make both them and the purge thread suffer.
+func checkForeignTransactions(ctx context.Context, db *gorm.DB, cfg Config) (Level, string, error) {
+	var trx []struct {
+		ThreadID uint64
+		User     string
+		Age      int64
+	}
+	err := db.WithContext(ctx).Raw(`SELECT t.trx_mysql_thread_id AS thread_id, COALESCE(p.USER, '') AS user,
+			TIMESTAMPDIFF(SECOND, t.trx_started, NOW()) AS age
+		FROM information_schema.INNODB_TRX t
+		LEFT JOIN information_schema.PROCESSLIST p ON p.ID = t.trx_mysql_thread_id
+		WHERE t.trx_mysql_thread_id <> CONNECTION_ID() AND t.trx_started < NOW() - INTERVAL ? SECOND
+		ORDER BY t.trx_started`, int64(cfg.MaxForeignTrx/time.Second)).
+		Scan(&trx).Error
+	if err != nil {
+		return "", "", err
+	}
+	if len(trx) == 0 {
+		return OK, fmt.Sprintf("no other transaction open longer than %s", cfg.MaxForeignTrx), nil
+	}
+	parts := make([]string, 0, len(trx))
+	for _, t := range trx {
+		parts = append(parts, fmt.Sprintf("thread %d (%s) %ds", t.ThreadID, t.User, t.Age))
+	}
+	return Warn, fmt.Sprintf("%d long-running transaction(s): %s", len(trx), strings.Join(parts, ", ")), nil
+}
+
+func gib(n uint64) string {
+	return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+}
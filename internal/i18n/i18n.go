@@ -0,0 +1,48 @@
+// Package i18n selects the language of the lab's user-facing text (-lang). The lab is written
+// in Chinese; packages keep English catalogs keyed by the Chinese source text, and anything
+// without an entry is shown as written.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lang is a language of user-facing text.
+type Lang string
+
+const (
+	Chinese Lang = "zh"
+	English Lang = "en"
+)
+
+// Langs lists the accepted -lang values.
+func Langs() []string {
+	return []string{string(Chinese), string(English)}
+}
+
+// Parse returns the language for a -lang value; "" selects Chinese.
+func Parse(s string) (Lang, error) {
+	switch Lang(strings.ToLower(s)) {
+	case "", Chinese:
+		return Chinese, nil
+	case English:
+		return English, nil
+	}
+	return "", fmt.Errorf("unknown language %q (want %s)", s, strings.Join(Langs(), " or "))
+}
+
+// Catalog maps Chinese source text to its English translation.
+type Catalog map[string]string
+
+// T returns s in lang: the catalog's translation for English, otherwise (or when the catalog
+// has no entry) s itself.
+func (c Catalog) T(lang Lang, s string) string {
+	if lang != English {
+		return s
+	}
+	if t, ok := c[s]; ok {
+		return t
+	}
+	return s
+}
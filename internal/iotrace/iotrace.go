@@ -0,0 +1,81 @@
+// Package iotrace exports the per-interval InnoDB IO samples of scenario results as CSV,
+// one file per scenario, so bursty and steady IO patterns can be told apart.
+package iotrace
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"unicode"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// WriteDir writes NN-<scenario>.csv for every result with samples and returns the written paths.
+// Rows hold the elapsed time, the interval length and each counter's delta over that interval.
+func WriteDir(dir string, results []data.ScenarioResult) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for i, res := range results {
+		if len(res.IOSamples) == 0 {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%02d-%s.csv", i+1, fileSlug(res.Name)))
+		if err := writeCSV(path, res.IOSamples); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeCSV(path string, samples []data.IOSample) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	header := []string{"elapsed_ms", "interval_ms"}
+	for _, c := range samples[0].Counters {
+		header = append(header, c.Name)
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		record := []string{strconv.FormatInt(s.Elapsed.Milliseconds(), 10), strconv.FormatInt(s.Interval.Milliseconds(), 10)}
+		for _, c := range s.Counters {
+			record = append(record, strconv.FormatInt(c.Delta, 10))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func fileSlug(name string) string {
+	slug := make([]rune, 0, len(name))
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			slug = append(slug, r)
+		} else {
+			slug = append(slug, '_')
+		}
+	}
+	if len(slug) == 0 {
+		return "scenario"
+	}
+	return string(slug)
+}
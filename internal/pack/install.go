@@ -0,0 +1,105 @@
+package pack
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Install validates the pack archive at zipPath and extracts it to packsDir/<name>,
+// replacing a previously installed version of the same pack.
+func Install(zipPath, packsDir string) (Pack, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return Pack{}, err
+	}
+	defer zr.Close()
+
+	root, err := archiveRoot(zr.File)
+	if err != nil {
+		return Pack{}, err
+	}
+
+	if err := os.MkdirAll(packsDir, 0o755); err != nil {
+		return Pack{}, err
+	}
+	staging, err := os.MkdirTemp(packsDir, ".install-")
+	if err != nil {
+		return Pack{}, err
+	}
+	defer os.RemoveAll(staging)
+
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(f.Name, root)
+		if name == "" || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		if err := extractFile(f, staging, name); err != nil {
+			return Pack{}, err
+		}
+	}
+
+	p, err := Load(staging)
+	if err != nil {
+		return Pack{}, fmt.Errorf("invalid pack: %w", err)
+	}
+
+	target := filepath.Join(packsDir, p.Manifest.Name)
+	if err := os.RemoveAll(target); err != nil {
+		return Pack{}, err
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return Pack{}, err
+	}
+	return Load(target)
+}
+
+// archiveRoot returns the directory prefix holding pack.yaml ("" or "name/").
+func archiveRoot(files []*zip.File) (string, error) {
+	for _, f := range files {
+		if path.Base(f.Name) != ManifestFile {
+			continue
+		}
+		dir := path.Dir(f.Name)
+		switch {
+		case dir == ".":
+			return "", nil
+		case !strings.Contains(dir, "/"):
+			return dir + "/", nil
+		}
+	}
+	return "", fmt.Errorf("%s not found at the archive root or in a single top-level directory", ManifestFile)
+}
+
+func extractFile(f *zip.File, dir, name string) error {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("archive entry %q escapes the pack directory", f.Name)
+	}
+	if !f.Mode().IsRegular() {
+		return fmt.Errorf("archive entry %q is not a regular file", f.Name)
+	}
+	dest := filepath.Join(dir, clean)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
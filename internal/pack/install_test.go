@@ -0,0 +1,108 @@
+package pack
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type zipEntry struct {
+	name, content string
+	mode          os.FileMode
+}
+
+func writeZip(t *testing.T, entries ...zipEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pack.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		h := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		h.SetMode(0o644)
+		if e.mode != 0 {
+			h.SetMode(e.mode)
+		}
+		w, err := zw.CreateHeader(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+var (
+	manifest = zipEntry{name: "demo/pack.yaml", content: "name: demo\nversion: 1.0.0\n"}
+	scenario = zipEntry{name: "demo/scenarios/a.yaml", content: "name: a\nquery: SELECT 1\n"}
+)
+
+func TestInstall(t *testing.T) {
+	packsDir := filepath.Join(t.TempDir(), "packs")
+	p, err := Install(writeZip(t, manifest, scenario, zipEntry{name: "demo/docs/notes.md", content: "notes"}), packsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Manifest.Name != "demo" || p.Dir != filepath.Join(packsDir, "demo") || len(p.Scenarios) != 1 || len(p.Docs) != 1 {
+		t.Errorf("installed pack = %+v", p)
+	}
+
+	// Reinstalling replaces the previous version, leaving no staging directories behind.
+	updated := zipEntry{name: "pack.yaml", content: "name: demo\nversion: 2.0.0\n"}
+	if _, err := Install(writeZip(t, updated, zipEntry{name: "scenarios/b.yaml", content: "name: b\nquery: SELECT 2\n"}), packsDir); err != nil {
+		t.Fatal(err)
+	}
+	packs, err := LoadAll(packsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packs) != 1 || packs[0].Manifest.Version != "2.0.0" || packs[0].Scenarios[0].Name != "b" {
+		t.Errorf("packs after reinstall = %+v", packs)
+	}
+	if _, err := os.Stat(filepath.Join(packsDir, "demo", "docs")); !os.IsNotExist(err) {
+		t.Errorf("files of the old version survived the reinstall: %v", err)
+	}
+}
+
+func TestInstallRejectsUnsafeArchives(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		want    string
+	}{
+		{"parent traversal", []zipEntry{manifest, scenario, {name: "demo/../../evil.sh", content: "x"}}, "escapes the pack directory"},
+		{"nested traversal", []zipEntry{manifest, scenario, {name: "demo/docs/../../../evil.sh", content: "x"}}, "escapes the pack directory"},
+		{"symlink", []zipEntry{manifest, scenario, {name: "demo/docs/link", content: "/etc/passwd", mode: os.ModeSymlink | 0o777}}, "not a regular file"},
+		{"no manifest", []zipEntry{scenario}, "pack.yaml not found"},
+		{"manifest too deep", []zipEntry{{name: "a/b/pack.yaml", content: "name: demo\n"}}, "pack.yaml not found"},
+		{"invalid pack", []zipEntry{manifest}, "invalid pack"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			packsDir := filepath.Join(root, "packs")
+			_, err := Install(writeZip(t, tt.entries...), packsDir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Install error = %v, want one containing %q", err, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(root, "evil.sh")); !os.IsNotExist(err) {
+				t.Errorf("an entry was written outside the packs directory")
+			}
+			if entries, _ := os.ReadDir(packsDir); len(entries) != 0 {
+				t.Errorf("packs directory not left empty: %v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Package pack installs and loads scenario packs: zip archives holding YAML scenario
+// definitions together with their setup SQL, expected plans and documentation.
+//
+// Layout of a pack (at the archive root or inside a single top-level directory):
+//
+//	pack.yaml          manifest: name, version, description, scenario files
+//	scenarios/*.yaml   scenario definitions (default when the manifest lists none)
+//	setup/*.sql        setup scripts referenced via setup_sql_file
+//	plans/*.yaml       expected plans referenced via expected_plan
+//	docs/*             free-form teaching material, listed after install
+package pack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"mysql-slow-query-lab/internal/data"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the file that marks the root of a pack.
+const ManifestFile = "pack.yaml"
+
+// Manifest is the pack.yaml document.
+type Manifest struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	Description string   `yaml:"description"`
+	Scenarios   []string `yaml:"scenarios"`
+}
+
+// ScenarioSpec is the YAML form of a data.Scenario. A file may hold a single spec or a list.
+type ScenarioSpec struct {
+	Type            string        `yaml:"type"`
+	Name            string        `yaml:"name"`
+	Description     string        `yaml:"description"`
+	Query           string        `yaml:"query"`
+	Args            []interface{} `yaml:"args"`
+	Hints           string        `yaml:"hints"`
+	MinVersion      string        `yaml:"min_version"`
+	MariaDB         MariaDBSpec   `yaml:"mariadb"`
+	TiDB            TiDBSpec      `yaml:"tidb"`
+	Postgres        PostgresSpec  `yaml:"postgres"`
+	OptimizerSwitch string        `yaml:"optimizer_switch"`
+	Counters        []string      `yaml:"counters"`
+	SetupSQL        []string      `yaml:"setup_sql"`
+	SetupSQLFile    string        `yaml:"setup_sql_file"`
+	SetupInTx       bool          `yaml:"setup_in_tx"`
+	Expect          []PlanSpec    `yaml:"expect"`
+	ExpectedPlan    string        `yaml:"expected_plan"`
+	ExpectError     string        `yaml:"expect_error"`
+}
+
+// MariaDBSpec is the YAML form of a data.MariaDBSupport.
+type MariaDBSpec struct {
+	MinVersion string `yaml:"min_version"`
+	Skip       string `yaml:"skip"`
+	Note       string `yaml:"note"`
+}
+
+// TiDBSpec is the YAML form of a data.TiDBSupport.
+type TiDBSpec struct {
+	Skip string `yaml:"skip"`
+	Note string `yaml:"note"`
+}
+
+// PostgresSpec is the YAML form of a data.PostgresSupport.
+type PostgresSpec struct {
+	Query    string   `yaml:"query"`
+	SetupSQL []string `yaml:"setup_sql"`
+	Skip     string   `yaml:"skip"`
+	Note     string   `yaml:"note"`
+}
+
+// PlanSpec is the YAML form of a data.PlanExpectation.
+type PlanSpec struct {
+	Table string `yaml:"table"`
+	Type  string `yaml:"type"`
+	Key   string `yaml:"key"`
+	Extra string `yaml:"extra"`
+}
+
+// Pack is a validated, loaded pack.
+type Pack struct {
+	Manifest  Manifest
+	Dir       string
+	Scenarios []data.Scenario
+	Docs      []string
+}
+
+// Load reads and validates the pack rooted at dir.
+func Load(dir string) (Pack, error) {
+	p := Pack{Dir: dir}
+	raw, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return p, err
+	}
+	if err := yaml.Unmarshal(raw, &p.Manifest); err != nil {
+		return p, fmt.Errorf("%s: %w", ManifestFile, err)
+	}
+	if p.Manifest.Name == "" {
+		return p, fmt.Errorf("%s: name is required", ManifestFile)
+	}
+	if strings.ContainsAny(p.Manifest.Name, `/\.`) {
+		return p, fmt.Errorf("%s: name %q must not contain path separators or dots", ManifestFile, p.Manifest.Name)
+	}
+
+	files := p.Manifest.Scenarios
+	if len(files) == 0 {
+		files, _ = filepath.Glob(filepath.Join(dir, "scenarios", "*.yaml"))
+		for i, f := range files {
+			files[i], _ = filepath.Rel(dir, f)
+		}
+		sort.Strings(files)
+	}
+	if len(files) == 0 {
+		return p, fmt.Errorf("pack %s has no scenarios", p.Manifest.Name)
+	}
+	for _, file := range files {
+		specs, err := readSpecs(dir, file)
+		if err != nil {
+			return p, err
+		}
+		for _, spec := range specs {
+			sc, err := spec.toScenario(dir, p.Manifest.Name)
+			if err != nil {
+				return p, fmt.Errorf("%s: %w", file, err)
+			}
+			p.Scenarios = append(p.Scenarios, sc)
+		}
+	}
+
+	docs, _ := filepath.Glob(filepath.Join(dir, "docs", "*"))
+	sort.Strings(docs)
+	p.Docs = docs
+	return p, nil
+}
+
+// LoadAll loads every installed pack under packsDir; a missing directory means no packs.
+func LoadAll(packsDir string) ([]Pack, error) {
+	entries, err := os.ReadDir(packsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var packs []Pack
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		p, err := Load(filepath.Join(packsDir, entry.Name()))
+		if err != nil {
+			return packs, fmt.Errorf("pack %s: %w", entry.Name(), err)
+		}
+		packs = append(packs, p)
+	}
+	return packs, nil
+}
+
+// Scenarios flattens the scenarios of all packs in order.
+func Scenarios(packs []Pack) []data.Scenario {
+	var scenarios []data.Scenario
+	for _, p := range packs {
+		scenarios = append(scenarios, p.Scenarios...)
+	}
+	return scenarios
+}
+
+func readSpecs(dir, file string) ([]ScenarioSpec, error) {
+	raw, err := readPackFile(dir, file)
+	if err != nil {
+		return nil, err
+	}
+	var specs []ScenarioSpec
+	if err := yaml.Unmarshal(raw, &specs); err == nil {
+		return specs, nil
+	}
+	var single ScenarioSpec
+	if err := yaml.Unmarshal(raw, &single); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return []ScenarioSpec{single}, nil
+}
+
+func (s ScenarioSpec) toScenario(dir, packName string) (data.Scenario, error) {
+	if s.Name == "" || s.Query == "" {
+		return data.Scenario{}, fmt.Errorf("scenario needs name and query (got name=%q)", s.Name)
+	}
+	sc := data.Scenario{
+		Type:            s.Type,
+		Name:            s.Name,
+		Description:     s.Description,
+		Query:           s.Query,
+		Args:            s.Args,
+		Hints:           s.Hints,
+		MinVersion:      s.MinVersion,
+		MariaDB:         data.MariaDBSupport(s.MariaDB),
+		TiDB:            data.TiDBSupport(s.TiDB),
+		Postgres:        data.PostgresSupport(s.Postgres),
+		OptimizerSwitch: s.OptimizerSwitch,
+		Counters:        s.Counters,
+		SetupSQL:        s.SetupSQL,
+		SetupInTx:       s.SetupInTx,
+		ExpectErr:       s.ExpectError,
+	}
+	if sc.Type == "" {
+		sc.Type = packName
+	}
+	if s.SetupSQLFile != "" {
+		raw, err := readPackFile(dir, s.SetupSQLFile)
+		if err != nil {
+			return sc, err
+		}
+		sc.SetupSQL = append(sc.SetupSQL, SplitStatements(string(raw))...)
+	}
+
+	expect := s.Expect
+	if s.ExpectedPlan != "" {
+		raw, err := readPackFile(dir, s.ExpectedPlan)
+		if err != nil {
+			return sc, err
+		}
+		var fromFile []PlanSpec
+		if err := yaml.Unmarshal(raw, &fromFile); err != nil {
+			return sc, fmt.Errorf("%s: %w", s.ExpectedPlan, err)
+		}
+		expect = append(expect, fromFile...)
+	}
+	for _, e := range expect {
+		sc.ExpectPlan = append(sc.ExpectPlan, data.PlanExpectation{Table: e.Table, Type: e.Type, Key: e.Key, Extra: e.Extra})
+	}
+	return sc, nil
+}
+
+// readPackFile reads a file referenced from pack metadata, refusing paths that escape the pack.
+func readPackFile(dir, rel string) ([]byte, error) {
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path %q escapes the pack", rel)
+	}
+	return os.ReadFile(filepath.Join(dir, clean))
+}
+
+// SplitStatements splits a SQL script on semicolons outside quotes and comments. Line
+// comments ("-- " and "#") are dropped; block comments are kept, since optimizer hints and
+// versioned /*! ... */ comments are part of the statement. As in MySQL, "--" only starts a
+// comment when whitespace or the end of the script follows, so "5--3" is an expression.
+func SplitStatements(script string) []string {
+	var (
+		stmts   []string
+		current strings.Builder
+		quote   rune
+	)
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == '\\' && i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			j := i + 2
+			for j < len(runes) && (runes[j-1] != '*' || runes[j] != '/' || j == i+2) {
+				j++
+			}
+			end := min(j+1, len(runes))
+			current.WriteString(string(runes[i:end]))
+			i = end - 1
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-' && (i+2 == len(runes) || unicode.IsSpace(runes[i+2])), r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == ';':
+			if stmt := strings.TrimSpace(current.String()); stmt != "" {
+				stmts = append(stmts, stmt)
+			}
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
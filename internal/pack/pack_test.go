@@ -0,0 +1,122 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name, script string
+		want         []string
+	}{
+		{"simple", "CREATE TABLE t (id INT);\nINSERT INTO t VALUES (1);", []string{"CREATE TABLE t (id INT)", "INSERT INTO t VALUES (1)"}},
+		{"no trailing semicolon", "SELECT 1;SELECT 2", []string{"SELECT 1", "SELECT 2"}},
+		{"empty statements", " ;; \n;", nil},
+		{"semicolons in quotes", `INSERT INTO t VALUES ('a;b', "c;d"); SELECT ` + "`x;y`" + ` FROM t`,
+			[]string{`INSERT INTO t VALUES ('a;b', "c;d")`, "SELECT `x;y` FROM t"}},
+		{"escaped quotes", `INSERT INTO t VALUES ('it\'s; fine');`, []string{`INSERT INTO t VALUES ('it\'s; fine')`}},
+		{"comments", "-- setup; not a statement\nSELECT 1; # done; really\nSELECT 2;", []string{"SELECT 1", "SELECT 2"}},
+		{"semicolons in block comments", "/* setup; part 1 */ CREATE TABLE t (id INT) /* ; */;\nSELECT /*+ NO_ICP(t) */ id FROM t; /*!80000 SET @a = 1; */",
+			[]string{"/* setup; part 1 */ CREATE TABLE t (id INT) /* ; */", "SELECT /*+ NO_ICP(t) */ id FROM t", "/*!80000 SET @a = 1; */"}},
+		{"unterminated block comment", "SELECT 1 /* ; ", []string{"SELECT 1 /* ;"}},
+		{"empty block comment", "SELECT /**/ 1; SELECT 2", []string{"SELECT /**/ 1", "SELECT 2"}},
+		{"double minus without space", "SELECT 5--3; SELECT 1;", []string{"SELECT 5--3", "SELECT 1"}},
+		{"double minus comment at end", "SELECT 1;--", []string{"SELECT 1"}},
+		{"double minus before tab", "SELECT 1;--\tnot; a statement\nSELECT 2", []string{"SELECT 1", "SELECT 2"}},
+		{"multi-line", "INSERT INTO t\n  SELECT id\n  FROM u;\n", []string{"INSERT INTO t\n  SELECT id\n  FROM u"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements(%q) = %q, want %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+// writePack writes files (slash-separated relative paths) under a new directory.
+func writePack(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"pack.yaml": "name: joins\nversion: 1.0.0\n",
+		"scenarios/a.yaml": `
+- name: nested loop
+  query: SELECT * FROM a JOIN b ON b.a_id = a.id
+  setup_sql_file: setup/a.sql
+  expected_plan: plans/a.yaml
+- name: typed
+  type: custom
+  query: SELECT 1
+`,
+		"scenarios/b.yaml": "name: single\nquery: SELECT 2\nexpect:\n  - {table: t, type: ALL}\n",
+		"setup/a.sql":      "CREATE TABLE a (id INT);\nCREATE TABLE b (a_id INT);\n",
+		"plans/a.yaml":     "- {table: a, type: ALL}\n- {table: b, type: ref, key: idx_b_a_id}\n",
+		"docs/README.md":   "read me",
+	})
+	p, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names, types []string
+	for _, sc := range p.Scenarios {
+		names = append(names, sc.Name)
+		types = append(types, sc.Type)
+	}
+	if want := []string{"nested loop", "typed", "single"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("scenarios = %q, want %q", names, want)
+	}
+	if want := []string{"joins", "custom", "joins"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("types = %q, want %q", types, want)
+	}
+	first := p.Scenarios[0]
+	if len(first.SetupSQL) != 2 || len(first.ExpectPlan) != 2 || first.ExpectPlan[1].Key != "idx_b_a_id" {
+		t.Errorf("first scenario setup %q, plan %+v", first.SetupSQL, first.ExpectPlan)
+	}
+	if len(p.Docs) != 1 || filepath.Base(p.Docs[0]) != "README.md" {
+		t.Errorf("docs = %q", p.Docs)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"no name", map[string]string{"pack.yaml": "version: 1\n"}, "name is required"},
+		{"dotted name", map[string]string{"pack.yaml": "name: ../up\n"}, "must not contain"},
+		{"no scenarios", map[string]string{"pack.yaml": "name: empty\n"}, "has no scenarios"},
+		{"scenario without query", map[string]string{"pack.yaml": "name: p\n", "scenarios/a.yaml": "name: a\n"}, "needs name and query"},
+		{"setup file outside the pack", map[string]string{"pack.yaml": "name: p\n",
+			"scenarios/a.yaml": "name: a\nquery: SELECT 1\nsetup_sql_file: ../../etc/passwd\n"}, "escapes the pack"},
+		{"absolute plan file", map[string]string{"pack.yaml": "name: p\n",
+			"scenarios/a.yaml": "name: a\nquery: SELECT 1\nexpected_plan: /etc/passwd\n"}, "escapes the pack"},
+		{"manifest scenario outside the pack", map[string]string{"pack.yaml": "name: p\nscenarios: [../x.yaml]\n"}, "escapes the pack"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writePack(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package pack
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// APIVersion is the semantic version of the Go pack registration API. Packs declare the
+// version they were written against; a pack is accepted when the major versions match and
+// the pack does not require a newer minor version than this build provides.
+const APIVersion = "1.1.0"
+
+// GoPack is a scenario pack compiled into the binary, for scenarios that need Go Setup or Run code.
+type GoPack struct {
+	Name        string
+	Version     string
+	APIVersion  string
+	Description string
+	Scenarios   []data.Scenario
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []GoPack
+	semverRe   = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:[-+].*)?$`)
+)
+
+// Register adds a Go pack to the registry. It is meant to be called from init functions
+// and panics on invalid or incompatible packs, like database/sql.Register does.
+func Register(p GoPack) {
+	if err := validateGoPack(p); err != nil {
+		panic(fmt.Sprintf("slowlab: RegisterPack(%q): %v", p.Name, err))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, existing := range registry {
+		if existing.Name == p.Name {
+			panic(fmt.Sprintf("slowlab: RegisterPack called twice for pack %q", p.Name))
+		}
+	}
+	registry = append(registry, p)
+}
+
+// Registered returns the registered Go packs in registration order.
+func Registered() []GoPack {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]GoPack(nil), registry...)
+}
+
+// RegisteredScenarios flattens the scenarios of all registered Go packs, defaulting Type to the pack name.
+func RegisteredScenarios() []data.Scenario {
+	var scenarios []data.Scenario
+	for _, p := range Registered() {
+		for _, sc := range p.Scenarios {
+			if sc.Type == "" {
+				sc.Type = p.Name
+			}
+			scenarios = append(scenarios, sc)
+		}
+	}
+	return scenarios
+}
+
+func validateGoPack(p GoPack) error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, err := parseSemver(p.Version); err != nil {
+		return fmt.Errorf("pack version: %w", err)
+	}
+	want, err := parseSemver(p.APIVersion)
+	if err != nil {
+		return fmt.Errorf("api version: %w", err)
+	}
+	have, _ := parseSemver(APIVersion)
+	if want[0] != have[0] || want[1] > have[1] {
+		return fmt.Errorf("pack targets API %s, this build provides %s", p.APIVersion, APIVersion)
+	}
+	if len(p.Scenarios) == 0 {
+		return fmt.Errorf("pack has no scenarios")
+	}
+	for _, sc := range p.Scenarios {
+		if sc.Name == "" || (sc.Query == "" && sc.Run == nil) {
+			return fmt.Errorf("scenario %q needs a name and a Query or Run", sc.Name)
+		}
+	}
+	return nil
+}
+
+func parseSemver(v string) ([3]int, error) {
+	var out [3]int
+	m := semverRe.FindStringSubmatch(v)
+	if m == nil {
+		return out, fmt.Errorf("%q is not a semantic version (MAJOR.MINOR.PATCH)", v)
+	}
+	for i := range out {
+		out[i], _ = strconv.Atoi(m[i+1])
+	}
+	return out, nil
+}
@@ -0,0 +1,219 @@
+// Package planwatch periodically EXPLAINs a fixed set of queries and reports when the
+// chosen access type or index changes compared to a stored baseline plan.
+//
+// Queries are listed in a YAML file; each entry gives either literal SQL or a statement
+// digest, in which case the sample text MySQL keeps in
+// performance_schema.events_statements_summary_by_digest is explained.
+package planwatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/internal/data"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Config is the watch.yaml document.
+type Config struct {
+	// Interval between checks, e.g. "30s" or "5m".
+	Interval string `yaml:"interval"`
+	// Baseline is the JSON file storing the accepted plan of every query.
+	Baseline string `yaml:"baseline"`
+	// Webhook, when set, receives a JSON POST for every detected change.
+	Webhook string  `yaml:"webhook"`
+	Queries []Query `yaml:"queries"`
+}
+
+// Query is one watched statement.
+type Query struct {
+	Name   string        `yaml:"name"`
+	SQL    string        `yaml:"sql"`
+	Digest string        `yaml:"digest"`
+	Args   []interface{} `yaml:"args"`
+}
+
+// Step is the part of a plan row that defines the access path.
+type Step struct {
+	Table string `json:"table"`
+	Type  string `json:"type"`
+	Key   string `json:"key"`
+}
+
+func (s Step) String() string {
+	key := s.Key
+	if key == "" {
+		key = "NULL"
+	}
+	return fmt.Sprintf("%s:%s/%s", s.Table, s.Type, key)
+}
+
+// Plan is the ordered list of steps of one query.
+type Plan []Step
+
+func (p Plan) String() string {
+	parts := make([]string, 0, len(p))
+	for _, s := range p {
+		parts = append(parts, s.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Change is a detected plan regression (or improvement) of a watched query.
+type Change struct {
+	Query    string    `json:"query"`
+	SQL      string    `json:"sql"`
+	Baseline Plan      `json:"baseline"`
+	Current  Plan      `json:"current"`
+	At       time.Time `json:"at"`
+}
+
+// LoadConfig reads and validates a watch configuration.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.Interval == "" {
+		cfg.Interval = "1m"
+	}
+	if _, err := time.ParseDuration(cfg.Interval); err != nil {
+		return cfg, fmt.Errorf("%s: interval: %w", path, err)
+	}
+	if cfg.Baseline == "" {
+		cfg.Baseline = "plan-baseline.json"
+	}
+	if len(cfg.Queries) == 0 {
+		return cfg, fmt.Errorf("%s: no queries configured", path)
+	}
+	seen := make(map[string]bool, len(cfg.Queries))
+	for i, q := range cfg.Queries {
+		if q.Name == "" {
+			return cfg, fmt.Errorf("%s: query %d has no name", path, i+1)
+		}
+		if seen[q.Name] {
+			return cfg, fmt.Errorf("%s: duplicate query name %q", path, q.Name)
+		}
+		seen[q.Name] = true
+		if (q.SQL == "") == (q.Digest == "") {
+			return cfg, fmt.Errorf("%s: query %q needs exactly one of sql or digest", path, q.Name)
+		}
+	}
+	return cfg, nil
+}
+
+// LoadBaseline reads the stored plans; a missing file yields an empty baseline.
+func LoadBaseline(path string) (map[string]Plan, error) {
+	baseline := make(map[string]Plan)
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return baseline, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &baseline); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// SaveBaseline writes the plans as indented JSON.
+func SaveBaseline(path string, baseline map[string]Plan) error {
+	raw, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+// Explain resolves the query text and returns its current plan.
+func Explain(ctx context.Context, db *gorm.DB, q Query) (string, Plan, error) {
+	sql := q.SQL
+	if q.Digest != "" {
+		var sample string
+		if err := db.WithContext(ctx).Raw(`SELECT COALESCE(QUERY_SAMPLE_TEXT, '') FROM performance_schema.events_statements_summary_by_digest
+			WHERE DIGEST = ? AND SCHEMA_NAME = DATABASE() LIMIT 1`, q.Digest).
+			Row().Scan(&sample); err != nil {
+			return "", nil, fmt.Errorf("digest %s: %w", q.Digest, err)
+		}
+		if sample == "" {
+			return "", nil, fmt.Errorf("digest %s has no sample text", q.Digest)
+		}
+		sql = sample
+	}
+
+	rows, err := data.ExplainPlan(ctx, db, sql, q.Args...)
+	if err != nil {
+		return sql, nil, err
+	}
+	plan := make(Plan, 0, len(rows))
+	for _, row := range rows {
+		plan = append(plan, Step{Table: row.Table, Type: row.Type, Key: row.Key})
+	}
+	return sql, plan, nil
+}
+
+// Check explains every configured query once. Queries without a baseline entry are added to
+// baseline (reported via the returned bool) instead of being reported as changes.
+func Check(ctx context.Context, db *gorm.DB, cfg Config, baseline map[string]Plan) ([]Change, bool, []error) {
+	var (
+		changes []Change
+		errs    []error
+		updated bool
+	)
+	for _, q := range cfg.Queries {
+		sql, plan, err := Explain(ctx, db, q)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", q.Name, err))
+			continue
+		}
+		prev, ok := baseline[q.Name]
+		if !ok {
+			baseline[q.Name] = plan
+			updated = true
+			continue
+		}
+		if prev.String() != plan.String() {
+			changes = append(changes, Change{Query: q.Name, SQL: sql, Baseline: prev, Current: plan, At: time.Now()})
+		}
+	}
+	return changes, updated, errs
+}
+
+// Notify posts the change as JSON to the webhook URL.
+func Notify(ctx context.Context, webhook string, change Change) error {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
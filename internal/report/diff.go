@@ -0,0 +1,118 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"mysql-slow-query-lab/internal/data"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// Side is one target of a dual-target run (-compare-dsn).
+type Side struct {
+	Meta    Metadata
+	Results []data.ScenarioResult
+}
+
+// DiffTable renders the scenarios of both targets side by side: latency of each, the ratio
+// B/A, and the access path of every table (table:type/key) when the plans differ. Scenarios
+// are paired by type and name; those run on one side only are listed with an empty other side.
+func DiffTable(w io.Writer, f Formatter, a, b Side) error {
+	for _, line := range []string{"A: " + a.Meta.Server + " @ " + a.Meta.Target, "B: " + b.Meta.Server + " @ " + b.Meta.Target} {
+		if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+			return err
+		}
+	}
+	table := tablewriter.NewTable(w,
+		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{
+			Settings: tw.Settings{Separators: tw.Separators{BetweenRows: tw.On}},
+		})),
+		tablewriter.WithConfig(tablewriter.Config{
+			Header: tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignCenter}},
+			Row: tw.CellConfig{
+				Merging:   tw.CellMerging{Mode: tw.MergeHierarchical},
+				Alignment: tw.CellAlignment{Global: tw.AlignLeft},
+			},
+		}),
+	)
+	header, rows := diffRows(f, a, b)
+	table.Header(header)
+	for _, row := range rows {
+		if err := table.Append(row); err != nil {
+			return err
+		}
+	}
+	return table.Render()
+}
+
+// diffRows lays out the side-by-side table of DiffTable: the header, then one row per scenario.
+func diffRows(f Formatter, a, b Side) ([]string, [][]string) {
+	header := []string{f.t("类型"), f.t("场景"), f.t("A 耗时"), f.t("B 耗时"), "B/A", f.t("执行计划")}
+	byKey := make(map[string]data.ScenarioResult, len(b.Results))
+	for _, res := range b.Results {
+		byKey[res.Type+"\x00"+res.Name] = res
+	}
+	rows := make([][]string, 0, len(a.Results))
+	seen := make(map[string]bool, len(a.Results))
+	for _, ra := range a.Results {
+		key := ra.Type + "\x00" + ra.Name
+		seen[key] = true
+		rb, ok := byKey[key]
+		row := []string{ra.Type, ra.Name, diffCell(f, &ra), "", "", ""}
+		if ok {
+			row[3], row[4], row[5] = diffCell(f, &rb), ratioCell(ra, rb), planDiff(f, ra.Plan, rb.Plan)
+		}
+		rows = append(rows, row)
+	}
+	for _, rb := range b.Results {
+		if seen[rb.Type+"\x00"+rb.Name] {
+			continue
+		}
+		rows = append(rows, []string{rb.Type, rb.Name, "", diffCell(f, &rb), "", ""})
+	}
+	return header, rows
+}
+
+// diffCell is the latency of a successful scenario, otherwise its shortened status.
+func diffCell(f Formatter, res *data.ScenarioResult) string {
+	if res.Err != nil {
+		return truncateText(Status(*res), 30)
+	}
+	return f.Duration(res.Duration)
+}
+
+func ratioCell(a, b data.ScenarioResult) string {
+	if a.Err != nil || b.Err != nil || a.Duration <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.2fx", float64(b.Duration)/float64(a.Duration))
+}
+
+// planDiff is "相同" when both sides read every table the same way, otherwise both access paths.
+func planDiff(f Formatter, a, b []data.PlanRow) string {
+	pa, pb := planShape(a), planShape(b)
+	switch {
+	case pa == "" && pb == "":
+		return ""
+	case pa == pb:
+		return f.t("相同")
+	}
+	return "A: " + pa + "\nB: " + pb
+}
+
+// planShape lists the access path of every plan row as table:type/key, like plan watch.
+func planShape(rows []data.PlanRow) string {
+	parts := make([]string, 0, len(rows))
+	for _, row := range rows {
+		key := row.Key
+		if key == "" {
+			key = "NULL"
+		}
+		parts = append(parts, fmt.Sprintf("%s:%s/%s", row.Table, row.Type, key))
+	}
+	return strings.Join(parts, " ")
+}
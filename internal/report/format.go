@@ -0,0 +1,152 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/internal/i18n"
+)
+
+// Formatter renders numbers, durations and sizes for one locale so large values in
+// teaching material stay readable. The zero value is the raw Go formatting.
+type Formatter struct {
+	locale    string
+	thousands string
+	decimal   string
+	unitSep   string
+	lang      i18n.Lang
+}
+
+// locales maps --locale values to their separators: thousands, decimal mark, and the space before units.
+var locales = map[string]Formatter{
+	"raw": {},
+	"en":  {thousands: ",", decimal: ".", unitSep: ""},
+	"zh":  {thousands: ",", decimal: ".", unitSep: ""},
+	"de":  {thousands: ".", decimal: ",", unitSep: " "},
+	"fr":  {thousands: " ", decimal: ",", unitSep: " "},
+	"go":  {thousands: "_", decimal: ".", unitSep: ""},
+}
+
+// Locales lists the accepted locale names.
+func Locales() []string {
+	return []string{"raw", "en", "zh", "de", "fr", "go"}
+}
+
+// NewFormatter returns the formatter for a locale name; "" selects raw.
+func NewFormatter(locale string) (Formatter, error) {
+	if locale == "" {
+		locale = "raw"
+	}
+	f, ok := locales[strings.ToLower(locale)]
+	if !ok {
+		return Formatter{}, fmt.Errorf("unknown locale %q (available: %s)", locale, strings.Join(Locales(), ", "))
+	}
+	f.locale = strings.ToLower(locale)
+	return f, nil
+}
+
+func (f Formatter) raw() bool {
+	return f.locale == "" || f.locale == "raw"
+}
+
+// Count formats an integer with thousands separators, e.g. 1,234,567.
+func (f Formatter) Count(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if f.raw() {
+		return s
+	}
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(f.thousands)
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
+
+// Duration formats d with three significant digits in the largest fitting unit, e.g. 1.23s or 456ms.
+func (f Formatter) Duration(d time.Duration) string {
+	if f.raw() {
+		return d.String()
+	}
+	if d >= time.Minute {
+		return f.Count(int64(d/time.Minute)) + "m" + fmt.Sprintf("%02ds", int64((d%time.Minute)/time.Second))
+	}
+	units := []struct {
+		size time.Duration
+		name string
+	}{{time.Second, "s"}, {time.Millisecond, "ms"}, {time.Microsecond, "µs"}}
+	for _, u := range units {
+		if d >= u.size {
+			return f.decimalValue(float64(d)/float64(u.size)) + f.unitSep + u.name
+		}
+	}
+	return f.Count(int64(d)) + f.unitSep + "ns"
+}
+
+// Bytes formats a size with binary units, e.g. 1.2 MiB.
+func (f Formatter) Bytes(n int64) string {
+	if f.raw() {
+		return strconv.FormatInt(n, 10)
+	}
+	units := []string{"KiB", "MiB", "GiB", "TiB"}
+	if n < 1024 {
+		return f.Count(n) + f.unitSep + "B"
+	}
+	value := float64(n)
+	unit := ""
+	for _, u := range units {
+		value /= 1024
+		unit = u
+		if value < 1024 {
+			break
+		}
+	}
+	sep := f.unitSep
+	if sep == "" {
+		sep = " "
+	}
+	return f.decimalValue(value) + sep + unit
+}
+
+// Cell reformats a preformatted table cell when it is a plain integer or a Go duration,
+// so experiment tables built from strings follow the locale too; other text is returned unchanged.
+func (f Formatter) Cell(s string) string {
+	if f.raw() {
+		return s
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return f.Count(n)
+	}
+	if d, err := time.ParseDuration(s); err == nil && strings.ContainsAny(s, "smhµn") {
+		return f.Duration(d)
+	}
+	return s
+}
+
+// decimalValue keeps three significant digits.
+func (f Formatter) decimalValue(v float64) string {
+	var s string
+	switch {
+	case v >= 100:
+		s = strconv.FormatFloat(v, 'f', 0, 64)
+	case v >= 10:
+		s = strconv.FormatFloat(v, 'f', 1, 64)
+	default:
+		s = strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	n, _ := strconv.ParseInt(whole, 10, 64)
+	out := f.Count(n)
+	if hasFrac {
+		out += f.decimal + frac
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// Results is the machine-readable record of one run, written as results.json into run folders.
+// Exactly one of Scenarios and Experiment is set.
+type Results struct {
+	Metadata   Metadata          `json:"metadata"`
+	Scenarios  []ScenarioRecord  `json:"scenarios,omitempty"`
+	Experiment *ExperimentRecord `json:"experiment,omitempty"`
+	// Compare holds the scenario results of the second target of a dual-target run.
+	Compare *Results `json:"compare,omitempty"`
+}
+
+// ScenarioRecord is the JSON form of a data.ScenarioResult.
+type ScenarioRecord struct {
+	Type        string  `json:"type"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	DurationMS  float64 `json:"duration_ms"`
+	Rows        int64   `json:"rows"`
+	// Status is "ok" or the data.ErrorKind of the failure.
+	Status   string           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+	Counters map[string]int64 `json:"counters,omitempty"`
+	Notes    []string         `json:"notes,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+	Explain  []string         `json:"explain,omitempty"`
+	SlowLog  *SlowLogRecord   `json:"slow_log,omitempty"`
+	Postgres *PostgresRecord  `json:"postgres,omitempty"`
+}
+
+// SlowLogRecord is what the server's slow log recorded for a scenario query.
+type SlowLogRecord struct {
+	QueryTimeMS  float64 `json:"query_time_ms"`
+	LockTimeMS   float64 `json:"lock_time_ms"`
+	RowsExamined int64   `json:"rows_examined"`
+	RowsSent     int64   `json:"rows_sent"`
+}
+
+// PostgresRecord is the same scenario measured on PostgreSQL.
+type PostgresRecord struct {
+	Query      string   `json:"query,omitempty"`
+	DurationMS float64  `json:"duration_ms"`
+	Rows       int64    `json:"rows"`
+	Status     string   `json:"status"`
+	Error      string   `json:"error,omitempty"`
+	Scans      []string `json:"scans,omitempty"`
+	Notes      []string `json:"notes,omitempty"`
+	Explain    []string `json:"explain,omitempty"`
+}
+
+// ExperimentRecord is the JSON form of a data.ExperimentReport.
+type ExperimentRecord struct {
+	Name    string     `json:"name"`
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+	Notes   []string   `json:"notes,omitempty"`
+}
+
+// ScenarioResults converts scenario results into their JSON records.
+func ScenarioResults(meta Metadata, results []data.ScenarioResult) Results {
+	out := Results{Metadata: meta, Scenarios: make([]ScenarioRecord, 0, len(results))}
+	for _, res := range results {
+		rec := ScenarioRecord{
+			Type:        res.Type,
+			Name:        res.Name,
+			Description: res.Description,
+			DurationMS:  float64(res.Duration.Microseconds()) / 1000,
+			Rows:        res.RowCount,
+			Status:      "ok",
+			Notes:       res.Notes,
+			Warnings:    res.Warnings,
+			Explain:     res.Explain,
+		}
+		if res.Err != nil {
+			rec.Status = data.ErrorKind(res.Err)
+			rec.Error = res.Err.Error()
+		}
+		if e := res.SlowLog; e != nil {
+			rec.SlowLog = &SlowLogRecord{
+				QueryTimeMS:  float64(e.QueryTime.Microseconds()) / 1000,
+				LockTimeMS:   float64(e.LockTime.Microseconds()) / 1000,
+				RowsExamined: e.RowsExamined,
+				RowsSent:     e.RowsSent,
+			}
+		}
+		if p := res.Postgres; p != nil {
+			rec.Postgres = &PostgresRecord{
+				Query:      p.Query,
+				DurationMS: float64(p.Duration.Microseconds()) / 1000,
+				Rows:       p.RowCount,
+				Status:     "ok",
+				Scans:      p.Scans,
+				Notes:      p.Notes,
+				Explain:    p.Plan,
+			}
+			if p.Err != nil {
+				rec.Postgres.Status = data.ErrorKind(p.Err)
+				rec.Postgres.Error = p.Err.Error()
+			}
+		}
+		if len(res.Counters) > 0 {
+			rec.Counters = make(map[string]int64, len(res.Counters))
+			for _, c := range res.Counters {
+				rec.Counters[c.Name] = c.Delta
+			}
+		}
+		out.Scenarios = append(out.Scenarios, rec)
+	}
+	return out
+}
+
+// ExperimentResults converts an experiment report into its JSON record.
+func ExperimentResults(meta Metadata, report data.ExperimentReport) Results {
+	return Results{Metadata: meta, Experiment: &ExperimentRecord{
+		Name:    report.Name,
+		Columns: report.Columns,
+		Rows:    report.Rows,
+		Notes:   report.Notes,
+	}}
+}
+
+// WriteJSON writes indented JSON.
+func (r Results) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
+
+// WriteFile writes the results as JSON to path.
+func (r Results) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteJSON(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,69 @@
+package report
+
+import "mysql-slow-query-lab/internal/i18n"
+
+// WithLang returns a copy of f that renders table headers and notes in lang.
+func (f Formatter) WithLang(lang i18n.Lang) Formatter {
+	f.lang = lang
+	return f
+}
+
+// t translates a header, cell or note of the report tables into f's language.
+func (f Formatter) t(s string) string {
+	return reportEnglish.T(f.lang, s)
+}
+
+// reportEnglish translates the Chinese text of the report tables. Format strings are keyed
+// as written and translated before formatting.
+var reportEnglish = i18n.Catalog{
+	"类型":     "Type",
+	"子序号":    "#",
+	"场景":     "Scenario",
+	"说明(截断)": "Short description",
+	"说明":     "Description",
+	"耗时":     "Time",
+	"行数":     "Rows",
+	"状态":     "Status",
+	" 行":     " rows",
+
+	"A 耗时": "A time",
+	"B 耗时": "B time",
+	"执行计划": "Plan",
+	"相同":   "same",
+
+	"表":     "Table",
+	"估算行数":  "Est. rows",
+	"数据":    "Data",
+	"索引":    "Index",
+	"空闲":    "Free",
+	"索引/数据": "Index/data",
+	"列":     "Columns",
+	"大小":    "Size",
+	"占表":    "Share",
+	"note: PRIMARY 是聚簇索引，存放整行数据，它的大小就是“数据”列；其余每个二级索引都是一棵独立的 B+ 树，保存索引列加主键。":            "note: PRIMARY is the clustered index holding the full rows, so its size is the Data column; every secondary index is a separate B+ tree of the indexed columns plus the primary key.",
+	"note: 每次 INSERT/DELETE 都要维护所有二级索引，UPDATE 要维护包含被改列的索引：索引越多越宽，写入越慢，缓冲池里能缓存的热数据也越少。": "note: every INSERT/DELETE maintains all secondary indexes and every UPDATE those containing changed columns: the more and wider the indexes, the slower the writes and the less hot data fits in the buffer pool.",
+	"note: 行数和大小来自 InnoDB 统计信息，是估算值；刚写入大量数据后可加 -analyze 先刷新统计。":                        "note: rows and sizes come from InnoDB statistics and are estimates; after large writes, add -analyze to refresh them first.",
+
+	"本次使用":        "Uses this run",
+	"服务器启动以来":     "Since server start",
+	"结论":          "Verdict",
+	"从未使用":        "never used",
+	"用过":          "used",
+	"聚簇索引":        "clustered index",
+	"在用":          "in use",
+	"未使用，但承担唯一约束": "unused, but enforces uniqueness",
+	"未使用":         "unused",
+	"note: %d 个二级索引在本次运行中没有被任何场景用到，却在每次写入时都要维护、占用磁盘和缓冲池；生产库中可结合 sys.schema_unused_indexes 观察一个完整业务周期后再删除（先设为 INVISIBLE 验证，见 -experiment invisible-index）。\n": "note: %d secondary indexes were not used by any scenario in this run, yet every write maintains them and they take disk and buffer pool space; in production, watch sys.schema_unused_indexes over a full business cycle before dropping one (make it INVISIBLE first to verify, see -experiment invisible-index).\n",
+	"note: 计数来自 performance_schema.table_io_waits_summary_by_index_usage，包含 UPDATE/DELETE 定位行时的读取；索引重建或服务器重启会清零。":                                            "note: counts come from performance_schema.table_io_waits_summary_by_index_usage and include reads that locate rows for UPDATE/DELETE; rebuilding the index or restarting the server resets them.",
+
+	"排名":     "Rank",
+	"次数":     "Count",
+	"总耗时":    "Total",
+	"占比":     "Share",
+	"平均":     "Avg",
+	"最大":     "Max",
+	"锁等待":    "Lock",
+	"平均扫描行":  "Avg examined",
+	"平均返回行":  "Avg sent",
+	"指纹(截断)": "Fingerprint (truncated)",
+}
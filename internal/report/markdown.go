@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// ScenarioMarkdown renders scenario results as a Markdown document: the run metadata as a list,
+// then the summary table of ScenarioTable with full descriptions.
+func ScenarioMarkdown(w io.Writer, meta Metadata, f Formatter, results []data.ScenarioResult) error {
+	if err := meta.writeMarkdown(w); err != nil {
+		return err
+	}
+	header, rows := scenarioRows(f, results, 0)
+	return markdownTable(w, header, rows)
+}
+
+// DiffMarkdown renders the side-by-side table of DiffTable as Markdown.
+func DiffMarkdown(w io.Writer, f Formatter, a, b Side) error {
+	if _, err := fmt.Fprintf(w, "\n- A: %s @ %s\n- B: %s @ %s\n\n", a.Meta.Server, a.Meta.Target, b.Meta.Server, b.Meta.Target); err != nil {
+		return err
+	}
+	header, rows := diffRows(f, a, b)
+	return markdownTable(w, header, rows)
+}
+
+// ExperimentMarkdown renders an experiment report as Markdown: metadata, the variant table and
+// its notes.
+func ExperimentMarkdown(w io.Writer, meta Metadata, f Formatter, report data.ExperimentReport) error {
+	if err := meta.writeMarkdown(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "## experiment: %s\n\n", report.Name); err != nil {
+		return err
+	}
+	rows := make([][]string, len(report.Rows))
+	for i, row := range report.Rows {
+		rows[i] = make([]string, len(row))
+		for j, cell := range row {
+			rows[i][j] = f.Cell(cell)
+		}
+	}
+	if err := markdownTable(w, report.Columns, rows); err != nil {
+		return err
+	}
+	for _, note := range report.Notes {
+		if _, err := fmt.Fprintf(w, "\n> note: %s\n", markdownCell(note)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m Metadata) writeMarkdown(w io.Writer) error {
+	for _, line := range m.Lines() {
+		if _, err := fmt.Fprintf(w, "- %s\n", line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// markdownTable writes a GitHub-flavoured Markdown table.
+func markdownTable(w io.Writer, header []string, rows [][]string) error {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" " + markdownCell(cell) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(header)
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes the pipes and line breaks that would end a table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
@@ -0,0 +1,343 @@
+// Package report renders scenario and experiment results for humans.
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mysql-slow-query-lab/internal/buildinfo"
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/slowlog"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// Metadata identifies a run so archived results can be traced to the lab build and server that produced them.
+type Metadata struct {
+	StartedAt time.Time      `json:"started_at"`
+	Build     buildinfo.Info `json:"build"`
+	Server    string         `json:"server"`
+	Target    string         `json:"target"`
+}
+
+// Lines renders the metadata as "key: value" lines.
+func (m Metadata) Lines() []string {
+	return []string{
+		"slowlab: " + m.Build.String(),
+		"server: " + m.Server + " @ " + m.Target,
+		"started: " + m.StartedAt.Format(time.RFC3339),
+	}
+}
+
+func (m Metadata) write(w io.Writer) error {
+	for _, line := range m.Lines() {
+		if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile stores the metadata next to exported artifacts (e.g. folded stacks) so they stay attributable.
+func (m Metadata) WriteFile(path string) error {
+	return os.WriteFile(path, []byte(strings.Join(m.Lines(), "\n")+"\n"), 0o644)
+}
+
+// ScenarioTable renders scenario results as the grouped summary table, preceded by the run metadata.
+// Durations and row counts are rendered with f.
+func ScenarioTable(w io.Writer, meta Metadata, f Formatter, results []data.ScenarioResult) error {
+	if err := meta.write(w); err != nil {
+		return err
+	}
+	table := tablewriter.NewTable(w,
+		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{
+			Settings: tw.Settings{Separators: tw.Separators{BetweenRows: tw.On}},
+		})),
+		tablewriter.WithConfig(tablewriter.Config{
+			Header: tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignCenter}},
+			Row: tw.CellConfig{
+				Merging:   tw.CellMerging{Mode: tw.MergeHierarchical},
+				Alignment: tw.CellAlignment{Global: tw.AlignLeft},
+			},
+		}),
+	)
+	header, rows := scenarioRows(f, results, 40)
+	table.Header(header)
+	for _, row := range rows {
+		if err := table.Append(row); err != nil {
+			return err
+		}
+	}
+	return table.Render()
+}
+
+// scenarioRows lays out the summary table of results: the header, then one row per scenario
+// numbered within its type, with descriptions truncated to descLimit runes (0 keeps them whole).
+func scenarioRows(f Formatter, results []data.ScenarioResult, descLimit int) ([]string, [][]string) {
+	header := []string{f.t("类型"), f.t("子序号"), f.t("场景"), f.t("说明(截断)"), f.t("耗时"), f.t("行数"), f.t("状态")}
+	if descLimit == 0 {
+		header[3] = f.t("说明")
+	}
+	compared := false
+	for _, res := range results {
+		compared = compared || res.Postgres != nil
+	}
+	if compared {
+		header = append(header, "PostgreSQL")
+	}
+	rows := make([][]string, 0, len(results))
+	currentType := ""
+	typeCounter := 0
+	for _, res := range results {
+		if res.Type != "" && res.Type != currentType {
+			currentType = res.Type
+			typeCounter = 0
+		}
+		typeCounter++
+		desc := res.Description
+		if descLimit > 0 {
+			desc = truncateText(desc, descLimit)
+		}
+		row := []string{res.Type, fmt.Sprint(typeCounter), res.Name, desc, f.Duration(res.Duration), f.Count(res.RowCount), Status(res)}
+		if compared {
+			row = append(row, postgresCell(f, res.Postgres))
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// postgresCell renders the PostgreSQL comparison: time and rows, or the shortened status.
+func postgresCell(f Formatter, p *data.PostgresResult) string {
+	switch {
+	case p == nil:
+		return ""
+	case p.Err != nil:
+		return truncateText(Status(data.ScenarioResult{Err: p.Err}), 30)
+	}
+	return f.Duration(p.Duration) + " / " + f.Count(p.RowCount) + f.t(" 行")
+}
+
+// statusLabels prefixes the status column by error kind so setup failures, query failures
+// and plan drift are distinguishable at a glance.
+var statusLabels = map[string]string{
+	"skipped":     "SKIP",
+	"setup":       "SETUP ERR",
+	"execution":   "ERR",
+	"explain":     "EXPLAIN ERR",
+	"expectation": "PLAN MISMATCH",
+}
+
+// Status renders the status column of a scenario result.
+func Status(res data.ScenarioResult) string {
+	kind := data.ErrorKind(res.Err)
+	if kind == "" {
+		return "OK"
+	}
+	return statusLabels[kind] + ": " + res.Err.Error()
+}
+
+// ExperimentTable renders an experiment report as a variant table, preceded by the run metadata and followed by its notes.
+// Integer and duration cells are re-rendered with f.
+func ExperimentTable(w io.Writer, meta Metadata, f Formatter, report data.ExperimentReport) error {
+	if err := meta.write(w); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "experiment: %s\n", report.Name)
+	table := tablewriter.NewTable(w,
+		tablewriter.WithConfig(tablewriter.Config{
+			Header: tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignCenter}},
+			Row:    tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignLeft}},
+		}),
+	)
+	table.Header(report.Columns)
+	for _, row := range report.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = f.Cell(cell)
+		}
+		if err := table.Append(cells); err != nil {
+			return err
+		}
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+	for _, note := range report.Notes {
+		fmt.Fprintf(w, "note: %s\n", note)
+	}
+	return nil
+}
+
+// SizesTable renders the table size report: one row per lab table, then one row per index
+// with its share of the table's pages, followed by notes. Sizes are rendered with f.
+func SizesTable(w io.Writer, f Formatter, sizes []data.TableSize) error {
+	tables := tablewriter.NewTable(w,
+		tablewriter.WithConfig(tablewriter.Config{
+			Header: tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignCenter}},
+			Row:    tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignLeft}},
+		}),
+	)
+	tables.Header([]string{f.t("表"), f.t("估算行数"), f.t("数据"), f.t("索引"), f.t("空闲"), f.t("索引/数据")})
+	missingStats := false
+	for _, t := range sizes {
+		ratio := "-"
+		if t.DataBytes > 0 {
+			ratio = fmt.Sprintf("%.0f%%", 100*float64(t.IndexBytes)/float64(t.DataBytes))
+		}
+		if err := tables.Append([]string{t.Table, f.Count(t.Rows), f.Bytes(t.DataBytes), f.Bytes(t.IndexBytes), f.Bytes(t.FreeBytes), ratio}); err != nil {
+			return err
+		}
+		missingStats = missingStats || !t.IndexesRead
+	}
+	if err := tables.Render(); err != nil {
+		return err
+	}
+
+	indexes := tablewriter.NewTable(w,
+		tablewriter.WithConfig(tablewriter.Config{
+			Header: tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignCenter}},
+			Row: tw.CellConfig{
+				Merging:   tw.CellMerging{Mode: tw.MergeHierarchical},
+				Alignment: tw.CellAlignment{Global: tw.AlignLeft},
+			},
+		}),
+	)
+	indexes.Header([]string{f.t("表"), f.t("索引"), f.t("列"), f.t("大小"), f.t("占表")})
+	for _, t := range sizes {
+		for _, idx := range t.Indexes {
+			size, share := "?", "?"
+			if idx.Bytes >= 0 {
+				size = f.Bytes(idx.Bytes)
+				if total := t.DataBytes + t.IndexBytes; total > 0 {
+					share = fmt.Sprintf("%.0f%%", 100*float64(idx.Bytes)/float64(total))
+				}
+			}
+			if err := indexes.Append([]string{t.Table, idx.Name, idx.Columns, size, share}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := indexes.Render(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, f.t("note: PRIMARY 是聚簇索引，存放整行数据，它的大小就是“数据”列；其余每个二级索引都是一棵独立的 B+ 树，保存索引列加主键。"))
+	fmt.Fprintln(w, f.t("note: 每次 INSERT/DELETE 都要维护所有二级索引，UPDATE 要维护包含被改列的索引：索引越多越宽，写入越慢，缓冲池里能缓存的热数据也越少。"))
+	fmt.Fprintln(w, f.t("note: 行数和大小来自 InnoDB 统计信息，是估算值；刚写入大量数据后可加 -analyze 先刷新统计。"))
+	if missingStats {
+		fmt.Fprintln(w, "note: mysql.innodb_index_stats is not readable by this account, so per-index sizes are unknown (grant SELECT on mysql.innodb_index_stats)")
+	}
+	return nil
+}
+
+// IndexUsageTable renders how often each index of orders was used by the scenario run,
+// followed by notes on which ones the run never touched. Counts are rendered with f.
+func IndexUsageTable(w io.Writer, f Formatter, usage []data.IndexUsage) error {
+	fmt.Fprintln(w, "index usage: orders")
+	table := tablewriter.NewTable(w,
+		tablewriter.WithConfig(tablewriter.Config{
+			Header: tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignCenter}},
+			Row:    tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignLeft}},
+		}),
+	)
+	table.Header([]string{f.t("索引"), f.t("列"), f.t("本次使用"), f.t("服务器启动以来"), f.t("结论")})
+	unused := 0
+	serverKnown := true
+	for _, u := range usage {
+		server := "?"
+		switch {
+		case u.Index == "PRIMARY":
+			server = "-"
+		case u.ServerKnown && u.ServerUnused:
+			server = f.t("从未使用")
+		case u.ServerKnown:
+			server = f.t("用过")
+		}
+		serverKnown = serverKnown && u.ServerKnown
+		verdict := ""
+		switch {
+		case u.Index == "PRIMARY":
+			verdict = f.t("聚簇索引")
+		case u.Uses > 0:
+			verdict = f.t("在用")
+		case u.Unique:
+			verdict = f.t("未使用，但承担唯一约束")
+		default:
+			verdict = f.t("未使用")
+			unused++
+		}
+		if err := table.Append([]string{u.Index, u.Columns, f.Count(u.Uses), server, verdict}); err != nil {
+			return err
+		}
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+	if unused > 0 {
+		fmt.Fprintf(w, f.t("note: %d 个二级索引在本次运行中没有被任何场景用到，却在每次写入时都要维护、占用磁盘和缓冲池；生产库中可结合 sys.schema_unused_indexes 观察一个完整业务周期后再删除（先设为 INVISIBLE 验证，见 -experiment invisible-index）。\n"), unused)
+	}
+	fmt.Fprintln(w, f.t("note: 计数来自 performance_schema.table_io_waits_summary_by_index_usage，包含 UPDATE/DELETE 定位行时的读取；索引重建或服务器重启会清零。"))
+	if !serverKnown {
+		fmt.Fprintln(w, "note: sys.schema_unused_indexes is not readable by this account, so server-wide usage is unknown (grant SELECT on sys.*)")
+	}
+	return nil
+}
+
+// DigestTable renders the top query classes of a slow log: one summary row per class, then the
+// full fingerprint and slowest example of each. Durations and counts are rendered with f.
+func DigestTable(w io.Writer, f Formatter, d *slowlog.Digest, classes []slowlog.Class) error {
+	fmt.Fprintf(w, "slow log: %s statements, %s total, %s query classes shown\n", f.Count(d.Entries), f.Duration(d.TotalTime), f.Count(int64(len(classes))))
+	table := tablewriter.NewTable(w,
+		tablewriter.WithConfig(tablewriter.Config{
+			Header: tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignCenter}},
+			Row:    tw.CellConfig{Alignment: tw.CellAlignment{Global: tw.AlignLeft}},
+		}),
+	)
+	table.Header([]string{f.t("排名"), f.t("次数"), f.t("总耗时"), f.t("占比"), f.t("平均"), f.t("最大"), f.t("锁等待"), f.t("平均扫描行"), f.t("平均返回行"), f.t("指纹(截断)")})
+	for i, c := range classes {
+		share := 0.0
+		if d.TotalTime > 0 {
+			share = 100 * float64(c.TotalTime) / float64(d.TotalTime)
+		}
+		err := table.Append([]string{
+			fmt.Sprintf("%d", i+1), f.Count(c.Count), f.Duration(c.TotalTime), fmt.Sprintf("%.1f%%", share),
+			f.Duration(c.AvgTime()), f.Duration(c.MaxTime), f.Duration(c.LockTime),
+			f.Count(c.RowsExamined / c.Count), f.Count(c.RowsSent / c.Count), truncateText(c.Fingerprint, 40),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+	for i, c := range classes {
+		fmt.Fprintf(w, "\n#%d %s\n", i+1, c.Fingerprint)
+		if c.DB != "" {
+			fmt.Fprintf(w, "    db: %s\n", c.DB)
+		}
+		if !c.First.IsZero() {
+			fmt.Fprintf(w, "    seen: %s .. %s\n", c.First.Format(time.RFC3339), c.Last.Format(time.RFC3339))
+		}
+		fmt.Fprintf(w, "    slowest (%s): %s\n", f.Duration(c.MaxTime), truncateText(strings.Join(strings.Fields(c.Example), " "), 500))
+	}
+	return nil
+}
+
+func truncateText(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	if limit > len(runes) {
+		limit = len(runes)
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,77 @@
+// Package retry retries operations that failed on a transient MySQL connection error, such
+// as a dropped connection or a server restarting in the middle of a lab run.
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Policy is an exponential backoff: up to Attempts retries, the first after Backoff and each
+// later one after twice the previous delay, capped at MaxBackoff. The zero Policy never retries.
+type Policy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Default retries for about half a minute in total, enough for a server restart.
+var Default = Policy{Attempts: 5, Backoff: 500 * time.Millisecond, MaxBackoff: 15 * time.Second}
+
+// MySQL server errors that end the connection without anything being wrong with the statement.
+var transientCodes = map[uint16]bool{
+	1053: true, // ER_SERVER_SHUTDOWN: server shutdown in progress
+	1077: true, // ER_NORMAL_SHUTDOWN
+	4031: true, // ER_CLIENT_INTERACTION_TIMEOUT: disconnected for inactivity
+}
+
+// Transient reports whether err is a connection failure worth retrying: a bad or broken
+// connection, a refused, reset or failed TCP connection, or one of the server shutdown errors.
+// Cancellation, deadlocks, lock wait timeouts and "too many connections" (1040, which the
+// connection scenarios provoke on purpose) are not.
+func Transient(err error) bool {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return true
+	}
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return transientCodes[myErr.Number]
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Do runs op until it succeeds, fails with an error that is not Transient, the retries run
+// out or ctx is done, and returns op's last error. Each retry is logged as a warning naming what.
+func (p Policy) Do(ctx context.Context, what string, op func() error) error {
+	delay := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt > p.Attempts || !Transient(err) {
+			return err
+		}
+		slog.Warn("transient MySQL error, retrying", "op", what, "retry", attempt, "of", p.Attempts, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+		{"bad conn", driver.ErrBadConn, true},
+		{"invalid conn", mysql.ErrInvalidConn, true},
+		{"eof", io.EOF, true},
+		{"unexpected eof", fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"broken pipe", syscall.EPIPE, true},
+		{"dial refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("i/o timeout")}, true},
+		{"read timeout", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("i/o timeout")}, false},
+		{"server shutdown", &mysql.MySQLError{Number: 1053}, true},
+		{"normal shutdown", fmt.Errorf("exec: %w", &mysql.MySQLError{Number: 1077}), true},
+		{"interaction timeout", &mysql.MySQLError{Number: 4031}, true},
+		{"too many connections", &mysql.MySQLError{Number: 1040}, false},
+		{"lock wait timeout", &mysql.MySQLError{Number: 1205}, false},
+		{"deadlock", &mysql.MySQLError{Number: 1213}, false},
+		{"syntax error", &mysql.MySQLError{Number: 1064}, false},
+		{"other", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transient(tt.err); got != tt.want {
+				t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	p := Policy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	calls := 0
+	err := p.Do(context.Background(), "test", func() error {
+		calls++
+		if calls < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Do = %v after %d calls, want success on the third", err, calls)
+	}
+
+	calls = 0
+	err = p.Do(context.Background(), "test", func() error { calls++; return driver.ErrBadConn })
+	if !errors.Is(err, driver.ErrBadConn) || calls != 4 {
+		t.Errorf("Do = %v after %d calls, want ErrBadConn after 1 try and 3 retries", err, calls)
+	}
+
+	calls = 0
+	deadlock := &mysql.MySQLError{Number: 1213}
+	err = p.Do(context.Background(), "test", func() error { calls++; return deadlock })
+	if err != deadlock || calls != 1 {
+		t.Errorf("Do = %v after %d calls, want the deadlock without retrying", err, calls)
+	}
+
+	calls = 0
+	err = Policy{}.Do(context.Background(), "test", func() error { calls++; return driver.ErrBadConn })
+	if !errors.Is(err, driver.ErrBadConn) || calls != 1 {
+		t.Errorf("zero Policy made %d calls, want 1", calls)
+	}
+}
+
+func TestDoStopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := Policy{Attempts: 5, Backoff: time.Hour}.Do(ctx, "test", func() error { calls++; return io.EOF })
+	if !errors.Is(err, io.EOF) || calls != 1 {
+		t.Errorf("Do = %v after %d calls, want io.EOF after 1", err, calls)
+	}
+}
@@ -0,0 +1,73 @@
+// Package rundir lays out the per-run output folders under -out-dir, so reports, artifacts,
+// logs and results of earlier runs can be found again by name.
+package rundir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Layout of a run folder.
+const (
+	ReportFile  = "report.txt"
+	ResultsFile = "results.json"
+	LogFile     = "run.log"
+	InfoFile    = "run-info.txt"
+	// timeLayout sorts lexically in chronological order.
+	timeLayout = "20060102-150405"
+)
+
+// Create makes base/<timestamp>/ for a run started at t; a second run within the same second gets a -2, -3, ... suffix.
+func Create(base string, t time.Time) (string, error) {
+	if err := os.MkdirAll(base, 0o755); err != nil {
+		return "", err
+	}
+	name := t.Format(timeLayout)
+	for i := 1; ; i++ {
+		dir := filepath.Join(base, name)
+		if i > 1 {
+			dir = fmt.Sprintf("%s-%d", dir, i)
+		}
+		err := os.Mkdir(dir, 0o755)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+	}
+}
+
+// List returns the run folders under base that hold a results file, oldest first.
+func List(base string) ([]string, error) {
+	entries, err := os.ReadDir(base)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(base, e.Name())
+		if _, err := os.Stat(filepath.Join(dir, ResultsFile)); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// Resolve places a relative artifact directory inside the run folder; absolute paths are kept.
+func Resolve(runDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(runDir, path)
+}
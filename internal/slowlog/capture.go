@@ -0,0 +1,79 @@
+package slowlog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Capture has the server log statements to mysql.slow_log for the duration of a run.
+type Capture struct {
+	db     *gorm.DB
+	cursor string
+	// The global settings found before the capture, restored by Stop.
+	slowLog       int
+	longQueryTime float64
+	logOutput     string
+}
+
+// StartCapture turns the slow query log on with the given long_query_time and adds TABLE to
+// log_output, so statements that start from now on can be read back with Entries. The new
+// long_query_time only applies to sessions opened afterwards, so callers should drop their
+// idle pooled connections. It needs SYSTEM_VARIABLES_ADMIN and SELECT on mysql.slow_log.
+func StartCapture(ctx context.Context, db *gorm.DB, longQueryTime time.Duration) (*Capture, error) {
+	c := &Capture{db: db}
+	err := db.WithContext(ctx).Raw("SELECT @@GLOBAL.slow_query_log, @@GLOBAL.long_query_time, @@GLOBAL.log_output").
+		Row().Scan(&c.slowLog, &c.longQueryTime, &c.logOutput)
+	if err != nil {
+		return nil, err
+	}
+	output := c.logOutput
+	if !strings.Contains(strings.ToUpper(output), "TABLE") {
+		output = strings.TrimPrefix(strings.TrimPrefix(output, "NONE")+",TABLE", ",")
+	}
+	for _, stmt := range [][]interface{}{
+		{"SET GLOBAL log_output = ?", output},
+		{"SET GLOBAL long_query_time = ?", longQueryTime.Seconds()},
+		{"SET GLOBAL slow_query_log = ON"},
+	} {
+		if err := db.WithContext(ctx).Exec(stmt[0].(string), stmt[1:]...).Error; err != nil {
+			c.Stop(ctx)
+			return nil, err
+		}
+	}
+	if c.cursor, err = serverNow(ctx, db); err != nil {
+		c.Stop(ctx)
+		return nil, err
+	}
+	return c, nil
+}
+
+// Entries returns every statement logged since the capture started, oldest first.
+func (c *Capture) Entries(ctx context.Context) ([]Entry, error) {
+	var all []Entry
+	cursor := c.cursor
+	for {
+		entries, next, err := readTable(ctx, c.db, cursor, tableBatch)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, entries...)
+		if len(entries) < tableBatch {
+			return all, nil
+		}
+		cursor = next
+	}
+}
+
+// Stop restores the slow log settings found when the capture started.
+func (c *Capture) Stop(ctx context.Context) error {
+	if err := c.db.WithContext(ctx).Exec("SET GLOBAL slow_query_log = ?", c.slowLog).Error; err != nil {
+		return err
+	}
+	if err := c.db.WithContext(ctx).Exec("SET GLOBAL long_query_time = ?", c.longQueryTime).Error; err != nil {
+		return err
+	}
+	return c.db.WithContext(ctx).Exec("SET GLOBAL log_output = ?", c.logOutput).Error
+}
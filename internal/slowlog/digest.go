@@ -0,0 +1,151 @@
+package slowlog
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"mysql-slow-query-lab/pkg/fingerprint"
+)
+
+// Class aggregates the entries that share a fingerprint.
+type Class struct {
+	Fingerprint string
+	// Example is the slowest statement of the class, as logged.
+	Example      string
+	DB           string
+	Count        int64
+	TotalTime    time.Duration
+	MaxTime      time.Duration
+	LockTime     time.Duration
+	RowsSent     int64
+	RowsExamined int64
+	First, Last  time.Time
+	// samples keeps the metrics of every entry for percentiles and the query time histogram.
+	samples *samples
+}
+
+// samples are per-entry metrics of a class, times in seconds.
+type samples struct {
+	queryTime, lockTime, rowsSent, rowsExamined []float64
+	users, hosts, dbs                           map[string]int64
+}
+
+// AvgTime is the mean query time of the class.
+func (c Class) AvgTime() time.Duration {
+	if c.Count == 0 {
+		return 0
+	}
+	return c.TotalTime / time.Duration(c.Count)
+}
+
+// Digest groups entries by fingerprint.
+type Digest struct {
+	classes map[string]*Class
+	// overall aggregates every entry as if they shared one fingerprint.
+	overall Class
+	// Entries and TotalTime cover every entry added, for shares of the whole log.
+	Entries   int64
+	TotalTime time.Duration
+}
+
+// NewDigest returns an empty digest.
+func NewDigest() *Digest {
+	return &Digest{classes: map[string]*Class{}}
+}
+
+// Add counts one entry into its class and returns the class as updated.
+func (d *Digest) Add(e Entry) Class {
+	fp := fingerprint.Normalize(e.SQL)
+	c := d.classes[fp]
+	if c == nil {
+		c = &Class{Fingerprint: fp, DB: e.DB}
+		d.classes[fp] = c
+	}
+	c.add(e)
+	d.overall.add(e)
+	d.Entries++
+	d.TotalTime += e.QueryTime
+	return *c
+}
+
+func (c *Class) add(e Entry) {
+	c.Count++
+	c.TotalTime += e.QueryTime
+	c.LockTime += e.LockTime
+	c.RowsSent += e.RowsSent
+	c.RowsExamined += e.RowsExamined
+	if e.QueryTime >= c.MaxTime {
+		c.MaxTime = e.QueryTime
+		c.Example = e.SQL
+	}
+	if !e.Time.IsZero() && (c.First.IsZero() || e.Time.Before(c.First)) {
+		c.First = e.Time
+	}
+	if e.Time.After(c.Last) {
+		c.Last = e.Time
+	}
+	if c.samples == nil {
+		c.samples = &samples{users: map[string]int64{}, hosts: map[string]int64{}, dbs: map[string]int64{}}
+	}
+	s := c.samples
+	s.queryTime = append(s.queryTime, e.QueryTime.Seconds())
+	s.lockTime = append(s.lockTime, e.LockTime.Seconds())
+	s.rowsSent = append(s.rowsSent, float64(e.RowsSent))
+	s.rowsExamined = append(s.rowsExamined, float64(e.RowsExamined))
+	if e.User != "" {
+		s.users[e.User]++
+	}
+	if e.Host != "" {
+		s.hosts[e.Host]++
+	}
+	if e.DB != "" {
+		s.dbs[e.DB]++
+	}
+}
+
+// Sort orders accepted by Digest.Top.
+const (
+	SortTotal        = "total"
+	SortCount        = "count"
+	SortAvg          = "avg"
+	SortMax          = "max"
+	SortRowsExamined = "rows"
+)
+
+// SortOrders lists the accepted sort orders.
+func SortOrders() []string {
+	return []string{SortTotal, SortCount, SortAvg, SortMax, SortRowsExamined}
+}
+
+// Top returns the n largest classes by the given sort order (all of them when n <= 0),
+// ties broken by fingerprint so reports are stable.
+func (d *Digest) Top(n int, by string) []Class {
+	key := func(c Class) int64 {
+		switch by {
+		case SortCount:
+			return c.Count
+		case SortAvg:
+			return int64(c.AvgTime())
+		case SortMax:
+			return int64(c.MaxTime)
+		case SortRowsExamined:
+			return c.RowsExamined
+		}
+		return int64(c.TotalTime)
+	}
+	classes := make([]Class, 0, len(d.classes))
+	for _, c := range d.classes {
+		classes = append(classes, *c)
+	}
+	slices.SortFunc(classes, func(a, b Class) int {
+		if c := cmp.Compare(key(b), key(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Fingerprint, b.Fingerprint)
+	})
+	if n > 0 && len(classes) > n {
+		classes = classes[:n]
+	}
+	return classes
+}
@@ -0,0 +1,81 @@
+package slowlog
+
+import (
+	"testing"
+	"time"
+)
+
+func digestOf(entries ...Entry) *Digest {
+	d := NewDigest()
+	for _, e := range entries {
+		d.Add(e)
+	}
+	return d
+}
+
+func TestDigestGroupsByFingerprint(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	d := digestOf(
+		Entry{Time: t0, DB: "slowlab", QueryTime: time.Second, RowsExamined: 100, SQL: "SELECT * FROM orders WHERE id = 1"},
+		Entry{Time: t0.Add(time.Minute), QueryTime: 3 * time.Second, RowsExamined: 300, SQL: "select *  from orders where id = 2"},
+		Entry{Time: t0.Add(-time.Minute), QueryTime: 2 * time.Second, RowsExamined: 50, SQL: "SELECT COUNT(*) FROM orders"},
+	)
+	if d.Entries != 3 || d.TotalTime != 6*time.Second {
+		t.Errorf("Entries = %d, TotalTime = %v, want 3 and 6s", d.Entries, d.TotalTime)
+	}
+	top := d.Top(0, SortTotal)
+	if len(top) != 2 {
+		t.Fatalf("got %d classes, want 2", len(top))
+	}
+	c := top[0]
+	if c.Fingerprint != "select * from orders where id = ?" || c.Count != 2 || c.TotalTime != 4*time.Second ||
+		c.MaxTime != 3*time.Second || c.AvgTime() != 2*time.Second || c.RowsExamined != 400 || c.DB != "slowlab" {
+		t.Errorf("top class = %+v", c)
+	}
+	if c.Example != "select *  from orders where id = 2" {
+		t.Errorf("Example = %q, want the slowest statement", c.Example)
+	}
+	if !c.First.Equal(t0) || !c.Last.Equal(t0.Add(time.Minute)) {
+		t.Errorf("range = %v..%v", c.First, c.Last)
+	}
+}
+
+func TestDigestTop(t *testing.T) {
+	d := digestOf(
+		Entry{QueryTime: 10 * time.Second, RowsExamined: 1, SQL: "SELECT a FROM t"},
+		Entry{QueryTime: time.Second, RowsExamined: 5000, SQL: "SELECT b FROM t"},
+		Entry{QueryTime: time.Second, RowsExamined: 5000, SQL: "SELECT b FROM t"},
+		Entry{QueryTime: time.Second, SQL: "SELECT c FROM t"},
+		Entry{QueryTime: time.Second, SQL: "SELECT c FROM t"},
+		Entry{QueryTime: time.Second, SQL: "SELECT c FROM t"},
+	)
+	tests := []struct {
+		by   string
+		n    int
+		want []string
+	}{
+		{SortTotal, 0, []string{"select a from t", "select c from t", "select b from t"}},
+		{SortCount, 0, []string{"select c from t", "select b from t", "select a from t"}},
+		{SortAvg, 0, []string{"select a from t", "select b from t", "select c from t"}},
+		{SortMax, 1, []string{"select a from t"}},
+		{SortRowsExamined, 2, []string{"select b from t", "select a from t"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			top := d.Top(tt.n, tt.by)
+			var got []string
+			for _, c := range top {
+				got = append(got, c.Fingerprint)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Top(%d, %q) = %q, want %q", tt.n, tt.by, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Top(%d, %q) = %q, want %q", tt.n, tt.by, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
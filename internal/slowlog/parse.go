@@ -0,0 +1,179 @@
+// Package slowlog reads MySQL slow query logs and digests them into query classes.
+package slowlog
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is one statement captured by the slow query log.
+type Entry struct {
+	Time         time.Time
+	User         string
+	Host         string
+	ThreadID     int64
+	DB           string
+	QueryTime    time.Duration
+	LockTime     time.Duration
+	RowsSent     int64
+	RowsExamined int64
+	// Attrs holds the remaining "# Name: value" attributes, such as those log_slow_extra adds
+	// (Bytes_sent, Created_tmp_disk_tables, ...).
+	Attrs map[string]string
+	SQL   string
+}
+
+// Parse reads a slow query log and calls fn for every entry in file order, stopping at the
+// first error fn returns. Server start banners and the administrative "use db;" and
+// "SET timestamp=...;" lines that precede each statement are consumed, not reported.
+func Parse(r io.Reader, fn func(Entry) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	var (
+		cur      Entry
+		sql      strings.Builder
+		inEntry  bool
+		db       string
+		lastTime time.Time
+	)
+	flush := func() error {
+		if !inEntry {
+			return nil
+		}
+		inEntry = false
+		cur.SQL = strings.TrimSpace(sql.String())
+		sql.Reset()
+		if cur.SQL == "" {
+			return nil
+		}
+		if cur.DB == "" {
+			cur.DB = db
+		}
+		if cur.Time.IsZero() {
+			cur.Time = lastTime
+		}
+		return fn(cur)
+	}
+
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "# Time:"):
+			if err := flush(); err != nil {
+				return err
+			}
+			lastTime = parseTime(strings.TrimSpace(strings.TrimPrefix(line, "# Time:")))
+		case strings.HasPrefix(line, "# User@Host:"):
+			if err := flush(); err != nil {
+				return err
+			}
+			cur = Entry{Time: lastTime}
+			inEntry = true
+			parseUserHost(&cur, strings.TrimPrefix(line, "# User@Host:"))
+		case strings.HasPrefix(line, "# ") && inEntry && sql.Len() == 0:
+			parseAttrs(&cur, line[2:])
+		case isBanner(line):
+			if err := flush(); err != nil {
+				return err
+			}
+		case !inEntry:
+			// Lines outside an entry, e.g. a log truncated in the middle of one.
+		case strings.HasPrefix(line, "use ") && sql.Len() == 0:
+			db = strings.Trim(strings.TrimSuffix(strings.TrimSpace(line[4:]), ";"), "`")
+			cur.DB = db
+		case strings.HasPrefix(line, "SET timestamp=") && sql.Len() == 0:
+			if ts, err := strconv.ParseInt(strings.TrimSuffix(line[len("SET timestamp="):], ";"), 10, 64); err == nil && cur.Time.IsZero() {
+				cur.Time = time.Unix(ts, 0).UTC()
+			}
+		default:
+			if sql.Len() > 0 {
+				sql.WriteByte('\n')
+			}
+			sql.WriteString(line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// isBanner matches the three header lines mysqld writes whenever it opens the log.
+func isBanner(line string) bool {
+	return strings.Contains(line, ", Version: ") && strings.Contains(line, "started with:") ||
+		strings.HasPrefix(line, "Tcp port: ") ||
+		strings.HasPrefix(line, "Time                 Id Command    Argument")
+}
+
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "060102 15:04:05", "060102  15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseUserHost reads "user[user] @ host [ip]  Id: 12".
+func parseUserHost(e *Entry, s string) {
+	if i := strings.Index(s, "Id:"); i >= 0 {
+		e.ThreadID, _ = strconv.ParseInt(strings.TrimSpace(s[i+3:]), 10, 64)
+		s = s[:i]
+	}
+	user, host, _ := strings.Cut(s, "@")
+	user = strings.TrimSpace(user)
+	if i := strings.Index(user, "["); i >= 0 {
+		user = user[:i]
+	}
+	e.User = user
+	host = strings.TrimSpace(host)
+	if i := strings.Index(host, "["); i >= 0 {
+		ip := strings.Trim(host[i:], "[] ")
+		host = strings.TrimSpace(host[:i])
+		if host == "" {
+			host = ip
+		}
+	}
+	e.Host = host
+}
+
+// parseAttrs reads "Name: value  Name: value" pairs such as the Query_time line.
+func parseAttrs(e *Entry, s string) {
+	fields := strings.Fields(s)
+	for i := 0; i+1 < len(fields); i++ {
+		if !strings.HasSuffix(fields[i], ":") {
+			continue
+		}
+		name, value := strings.TrimSuffix(fields[i], ":"), fields[i+1]
+		i++
+		switch name {
+		case "Query_time":
+			e.QueryTime = seconds(value)
+		case "Lock_time":
+			e.LockTime = seconds(value)
+		case "Rows_sent":
+			e.RowsSent, _ = strconv.ParseInt(value, 10, 64)
+		case "Rows_examined":
+			e.RowsExamined, _ = strconv.ParseInt(value, 10, 64)
+		case "Schema":
+			e.DB = value
+		default:
+			if e.Attrs == nil {
+				e.Attrs = map[string]string{}
+			}
+			e.Attrs[name] = value
+		}
+	}
+}
+
+func seconds(s string) time.Duration {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(v * float64(time.Second))
+}
@@ -0,0 +1,96 @@
+package slowlog
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseAll(t *testing.T, log string) []Entry {
+	t.Helper()
+	var entries []Entry
+	if err := Parse(strings.NewReader(log), func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestParse(t *testing.T) {
+	log, err := os.ReadFile("testdata/slow.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parseAll(t, string(log))
+	want := []Entry{
+		{
+			Time: time.Date(2024, 3, 1, 10, 0, 0, 123456000, time.UTC), User: "app", Host: "web-1", ThreadID: 42, DB: "slowlab",
+			QueryTime: 1500 * time.Millisecond, LockTime: 100 * time.Microsecond, RowsSent: 10, RowsExamined: 100000,
+			SQL: "SELECT * FROM orders\nWHERE customer_id = 7;",
+		},
+		{
+			Time: time.Date(2024, 3, 1, 10, 0, 0, 123456000, time.UTC), User: "report", Host: "10.0.0.9", ThreadID: 43, DB: "slowlab",
+			QueryTime: 250 * time.Millisecond, RowsSent: 1, RowsExamined: 500,
+			Attrs: map[string]string{"Bytes_sent": "120", "Created_tmp_disk_tables": "1"},
+			SQL:   "SELECT COUNT(*) FROM orders WHERE status = 'paid';",
+		},
+		{
+			Time: time.Date(2024, 3, 1, 10, 0, 5, 0, time.UTC), User: "app", Host: "web-2", ThreadID: 44, DB: "shop",
+			QueryTime: 3 * time.Second, LockTime: 200 * time.Microsecond, RowsSent: 3, RowsExamined: 200000,
+			Attrs: map[string]string{"Last_errno": "0", "Killed": "0"},
+			SQL:   "SELECT * FROM orders WHERE customer_id = 8;",
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("entry %d:\n got %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseTimestampWithoutTimeLine(t *testing.T) {
+	got := parseAll(t, "# User@Host: root[root] @ localhost []  Id: 1\n# Query_time: 2.0\nSET timestamp=1709287200;\nSELECT SLEEP(2);\n")
+	if len(got) != 1 {
+		t.Fatalf("got %d entries, want 1", len(got))
+	}
+	if want := time.Unix(1709287200, 0).UTC(); !got[0].Time.Equal(want) {
+		t.Errorf("Time = %v, want %v from SET timestamp", got[0].Time, want)
+	}
+	if got[0].Host != "localhost" || got[0].QueryTime != 2*time.Second {
+		t.Errorf("entry = %+v", got[0])
+	}
+}
+
+func TestParseSkipsTruncatedAndEmptyEntries(t *testing.T) {
+	log := "WHERE id = 1;\n" + // tail of an entry cut off by rotation
+		"# User@Host: app[app] @ web-1 []  Id: 1\n# Query_time: 0.1\n" + // no statement
+		"# User@Host: app[app] @ web-1 []  Id: 2\n# Query_time: 0.2\nSELECT 1;\n"
+	got := parseAll(t, log)
+	if len(got) != 1 || got[0].ThreadID != 2 {
+		t.Errorf("entries = %+v, want only thread 2", got)
+	}
+}
+
+func TestParseStopsOnCallbackError(t *testing.T) {
+	log, err := os.ReadFile("testdata/slow.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err = Parse(strings.NewReader(string(log)), func(Entry) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Parse = %v after %d calls, want stop after 1", err, calls)
+	}
+}
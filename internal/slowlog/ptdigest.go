@@ -0,0 +1,283 @@
+package slowlog
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"mysql-slow-query-lab/pkg/fingerprint"
+)
+
+// WritePTDigest writes classes in the layout of pt-query-digest's default report: the overall
+// attribute summary, the profile ranked by response time, then one section per class with
+// its attribute table, string attributes, query time histogram and slowest sample. Figures
+// pt-query-digest derives from its process (rusage) or the byte offset of samples are left
+// out; percentiles are exact rather than bucketed, so they can differ slightly.
+func WritePTDigest(w io.Writer, d *Digest, classes []Class, files []string) error {
+	bw := bufio.NewWriter(w)
+	o := d.overall
+	fmt.Fprintf(bw, "# Current date: %s\n", time.Now().Format("Mon Jan _2 15:04:05 2006"))
+	fmt.Fprintf(bw, "# Files: %s\n", strings.Join(files, ", "))
+	qps, conc := rates(o)
+	fmt.Fprintln(bw, title(fmt.Sprintf("# Overall: %s total, %s unique, %s QPS, %sx concurrency ",
+		ptNum(float64(o.Count)), ptNum(float64(len(d.classes))), ptNum(qps), ptNum(conc))))
+	writeTimeRange(bw, o)
+	fmt.Fprintln(bw, "# Attribute          total     min     max     avg     95%  stddev  median")
+	fmt.Fprintln(bw, "# ============     ======= ======= ======= ======= ======= ======= =======")
+	if o.samples != nil {
+		for _, a := range attributes(o.samples) {
+			st := summarize(a.values)
+			fmt.Fprintf(bw, "# %-16s %7s %7s %7s %7s %7s %7s %7s\n", a.name,
+				a.format(st.total), a.format(st.min), a.format(st.max), a.format(st.avg), a.format(st.p95), a.format(st.stddev), a.format(st.median))
+		}
+	}
+
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "# Profile")
+	fmt.Fprintln(bw, "# Rank Query ID                     Response time  Calls R/Call V/M   Item")
+	fmt.Fprintln(bw, "# ==== ============================ ============== ===== ====== ===== ==========")
+	for i, c := range classes {
+		st := summarize(c.samples.queryTime)
+		share := 0.0
+		if o.TotalTime > 0 {
+			share = 100 * float64(c.TotalTime) / float64(o.TotalTime)
+		}
+		fmt.Fprintf(bw, "# %4d 0x%-26s %8.4f %5.1f%% %5d %6.4f %5.2f %s\n",
+			i+1, fingerprint.ID(c.Fingerprint), c.TotalTime.Seconds(), share, c.Count, st.avg, varianceToMean(st), item(c.Fingerprint))
+	}
+
+	for i, c := range classes {
+		fmt.Fprintln(bw)
+		qps, conc := rates(c)
+		fmt.Fprintln(bw, title(fmt.Sprintf("# Query %d: %s QPS, %sx concurrency, ID 0x%s ", i+1, ptNum(qps), ptNum(conc), fingerprint.ID(c.Fingerprint))))
+		st := summarize(c.samples.queryTime)
+		fmt.Fprintf(bw, "# Scores: V/M = %.2f\n", varianceToMean(st))
+		writeTimeRange(bw, c)
+		fmt.Fprintln(bw, "# Attribute    pct   total     min     max     avg     95%  stddev  median")
+		fmt.Fprintln(bw, "# ============ === ======= ======= ======= ======= ======= ======= =======")
+		fmt.Fprintf(bw, "# %-12s %3.0f %7d\n", "Count", pct(float64(c.Count), float64(o.Count)), c.Count)
+		overall := attributes(o.samples)
+		for j, a := range attributes(c.samples) {
+			cs := summarize(a.values)
+			fmt.Fprintf(bw, "# %-12s %3.0f %7s %7s %7s %7s %7s %7s %7s\n", a.name, pct(cs.total, summarize(overall[j].values).total),
+				a.format(cs.total), a.format(cs.min), a.format(cs.max), a.format(cs.avg), a.format(cs.p95), a.format(cs.stddev), a.format(cs.median))
+		}
+		fmt.Fprintln(bw, "# String:")
+		writeStrings(bw, "Databases", c.samples.dbs)
+		writeStrings(bw, "Hosts", c.samples.hosts)
+		writeStrings(bw, "Users", c.samples.users)
+		fmt.Fprintln(bw, "# Query_time distribution")
+		writeHistogram(bw, c.samples.queryTime)
+		tables := tablesOf(c.Fingerprint)
+		if len(tables) > 0 {
+			fmt.Fprintln(bw, "# Tables")
+			for _, t := range tables {
+				qualified := fmt.Sprintf("`%s`", t)
+				if c.DB != "" && !strings.Contains(t, ".") {
+					fmt.Fprintf(bw, "#    SHOW TABLE STATUS FROM `%s` LIKE '%s'\\G\n", c.DB, t)
+					qualified = fmt.Sprintf("`%s`.`%s`", c.DB, t)
+				} else {
+					fmt.Fprintf(bw, "#    SHOW TABLE STATUS LIKE '%s'\\G\n", t)
+				}
+				fmt.Fprintf(bw, "#    SHOW CREATE TABLE %s\\G\n", qualified)
+			}
+		}
+		example := strings.TrimSuffix(strings.TrimSpace(c.Example), ";")
+		if strings.HasPrefix(c.Fingerprint, "select") {
+			fmt.Fprintln(bw, "# EXPLAIN /*!50100 PARTITIONS*/")
+		}
+		fmt.Fprintf(bw, "%s\\G\n", example)
+	}
+	return bw.Flush()
+}
+
+// title pads a heading with underscores to pt-query-digest's 74 columns.
+func title(s string) string {
+	if n := 74 - len(s); n > 0 {
+		return s + strings.Repeat("_", n)
+	}
+	return s
+}
+
+func writeTimeRange(w io.Writer, c Class) {
+	switch {
+	case c.First.IsZero():
+	case c.First.Equal(c.Last):
+		fmt.Fprintf(w, "# Time range: all events occurred at %s\n", ptTime(c.First))
+	default:
+		fmt.Fprintf(w, "# Time range: %s to %s\n", ptTime(c.First), ptTime(c.Last))
+	}
+}
+
+func ptTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05")
+}
+
+// rates returns queries per second and concurrency (query time per second) over the time range.
+func rates(c Class) (float64, float64) {
+	span := c.Last.Sub(c.First).Seconds()
+	if span <= 0 {
+		return 0, 0
+	}
+	return float64(c.Count) / span, c.TotalTime.Seconds() / span
+}
+
+type attribute struct {
+	name   string
+	values []float64
+	format func(float64) string
+}
+
+func attributes(s *samples) []attribute {
+	return []attribute{
+		{"Exec time", s.queryTime, ptSeconds},
+		{"Lock time", s.lockTime, ptSeconds},
+		{"Rows sent", s.rowsSent, ptNum},
+		{"Rows examine", s.rowsExamined, ptNum},
+	}
+}
+
+type stats struct {
+	total, min, max, avg, p95, stddev, median float64
+}
+
+func summarize(values []float64) stats {
+	if len(values) == 0 {
+		return stats{}
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	var st stats
+	for _, v := range sorted {
+		st.total += v
+	}
+	st.min, st.max = sorted[0], sorted[len(sorted)-1]
+	st.avg = st.total / float64(len(sorted))
+	for _, v := range sorted {
+		st.stddev += (v - st.avg) * (v - st.avg)
+	}
+	st.stddev = math.Sqrt(st.stddev / float64(len(sorted)))
+	st.p95 = sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+	st.median = sorted[(len(sorted)-1)/2]
+	return st
+}
+
+// varianceToMean is pt-query-digest's V/M score: how much a query's time varies for its mean.
+func varianceToMean(st stats) float64 {
+	if st.avg == 0 {
+		return 0
+	}
+	return st.stddev * st.stddev / st.avg
+}
+
+func pct(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return 100 * part / whole
+}
+
+// ptSeconds formats a time in seconds the way pt-query-digest does: 0, 12us, 340ms, 3s.
+func ptSeconds(s float64) string {
+	switch {
+	case s >= 1:
+		return fmt.Sprintf("%.0fs", s)
+	case s >= 0.001:
+		return fmt.Sprintf("%.0fms", s*1000)
+	case s > 0:
+		return fmt.Sprintf("%dus", int64(s*1e6))
+	}
+	return "0"
+}
+
+// ptNum formats a count with k/M/G suffixes above 1000 and two decimals for fractions.
+func ptNum(n float64) string {
+	units := []string{"", "k", "M", "G", "T"}
+	i := 0
+	for n >= 1000 && i < len(units)-1 {
+		n /= 1000
+		i++
+	}
+	if i == 0 && n == math.Trunc(n) {
+		return fmt.Sprintf("%.0f", n)
+	}
+	return fmt.Sprintf("%.2f%s", n, units[i])
+}
+
+func writeStrings(w io.Writer, name string, counts map[string]int64) {
+	if len(counts) == 0 {
+		return
+	}
+	type kv struct {
+		k string
+		n int64
+	}
+	var items []kv
+	var total int64
+	for k, n := range counts {
+		items = append(items, kv{k, n})
+		total += n
+	}
+	slices.SortFunc(items, func(a, b kv) int {
+		if c := cmp.Compare(b.n, a.n); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.k, b.k)
+	})
+	if len(items) == 1 {
+		fmt.Fprintf(w, "# %-12s %s\n", name, items[0].k)
+		return
+	}
+	parts := make([]string, 0, len(items))
+	for _, it := range items {
+		parts = append(parts, fmt.Sprintf("%s (%d/%.0f%%)", it.k, it.n, pct(float64(it.n), float64(total))))
+	}
+	fmt.Fprintf(w, "# %-12s %s\n", name, strings.Join(parts, ", "))
+}
+
+// writeHistogram draws the Query_time distribution over decades from 1us to 10s+, the bars
+// scaled so the fullest bucket has 64 marks.
+func writeHistogram(w io.Writer, queryTimes []float64) {
+	labels := []string{"  1us", " 10us", "100us", "  1ms", " 10ms", "100ms", "   1s", " 10s+"}
+	counts := make([]int, len(labels))
+	for _, s := range queryTimes {
+		b := 0
+		for limit := 1e-5; b < len(labels)-1 && s >= limit; limit *= 10 {
+			b++
+		}
+		counts[b]++
+	}
+	top := slices.Max(counts)
+	for i, label := range labels {
+		bar := ""
+		if counts[i] > 0 {
+			bar = "  " + strings.Repeat("#", max(1, counts[i]*64/top))
+		}
+		fmt.Fprintf(w, "# %s%s\n", label, bar)
+	}
+}
+
+var tableRefRe = regexp.MustCompile(`\b(?:from|join|into|update|table)\s+([a-z0-9_$]+(?:\.[a-z0-9_$]+)?)`)
+
+// tablesOf lists the tables a fingerprint reads or writes, in order of appearance.
+func tablesOf(fingerprint string) []string {
+	var tables []string
+	for _, m := range tableRefRe.FindAllStringSubmatch(fingerprint, -1) {
+		if m[1] != "select" && !slices.Contains(tables, m[1]) {
+			tables = append(tables, m[1])
+		}
+	}
+	return tables
+}
+
+// item is the profile's short description: the statement type and its tables, e.g. "SELECT orders customers".
+func item(fingerprint string) string {
+	verb, _, _ := strings.Cut(fingerprint, " ")
+	return strings.TrimSpace(strings.ToUpper(verb) + " " + strings.Join(tablesOf(fingerprint), " "))
+}
@@ -0,0 +1,83 @@
+package slowlog
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	st := summarize([]float64{4, 1, 3, 2, 10})
+	want := stats{total: 20, min: 1, max: 10, avg: 4, p95: 10, stddev: math.Sqrt(10), median: 3}
+	if st != want {
+		t.Errorf("summarize = %+v, want %+v", st, want)
+	}
+	if st := summarize([]float64{2, 2, 2}); st.stddev != 0 || varianceToMean(st) != 0 {
+		t.Errorf("constant values: stddev %v, V/M %v, want 0", st.stddev, varianceToMean(st))
+	}
+	if st := summarize(nil); st != (stats{}) {
+		t.Errorf("summarize(nil) = %+v", st)
+	}
+}
+
+func TestPTFormats(t *testing.T) {
+	seconds := map[float64]string{0: "0", 0.000012: "12us", 0.34: "340ms", 3.2: "3s"}
+	for in, want := range seconds {
+		if got := ptSeconds(in); got != want {
+			t.Errorf("ptSeconds(%v) = %q, want %q", in, got, want)
+		}
+	}
+	nums := map[float64]string{0: "0", 42: "42", 0.5: "0.50", 1500: "1.50k", 2e6: "2.00M"}
+	for in, want := range nums {
+		if got := ptNum(in); got != want {
+			t.Errorf("ptNum(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestItem(t *testing.T) {
+	tests := map[string]string{
+		"select * from orders o join customers c on c.id = o.customer_id": "SELECT orders customers",
+		"update slowlab.orders set note = ? where id = ?":                 "UPDATE slowlab.orders",
+		"insert into orders(a) values(?+)":                                "INSERT orders",
+		"select ?":                                                        "SELECT",
+	}
+	for fp, want := range tests {
+		if got := item(fp); got != want {
+			t.Errorf("item(%q) = %q, want %q", fp, got, want)
+		}
+	}
+}
+
+func TestWritePTDigest(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	d := digestOf(
+		Entry{Time: t0, User: "app", Host: "web-1", DB: "slowlab", QueryTime: 2 * time.Second, SQL: "SELECT * FROM orders WHERE id = 1"},
+		Entry{Time: t0.Add(10 * time.Second), User: "app", Host: "web-2", DB: "slowlab", QueryTime: 4 * time.Second, SQL: "SELECT * FROM orders WHERE id = 2"},
+		Entry{Time: t0.Add(10 * time.Second), User: "report", DB: "slowlab", QueryTime: 50 * time.Millisecond, SQL: "SELECT COUNT(*) FROM customers"},
+	)
+	var buf bytes.Buffer
+	if err := WritePTDigest(&buf, d, d.Top(0, SortTotal), []string{"slow.log"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Files: slow.log\n",
+		"# Overall: 3 total, 2 unique, 0.30 QPS, 0.60x concurrency _",
+		"# Time range: 2024-03-01T10:00:00 to 2024-03-01T10:00:10\n",
+		"SELECT orders\n",
+		"# Query 1: 0.20 QPS, 0.60x concurrency, ID 0x",
+		"# Hosts        web-1 (1/50%), web-2 (1/50%)\n",
+		"# Users        app\n",
+		"#    1s  ################################################################\n",
+		"# Query 2: 0 QPS, 0x concurrency",
+		"# Time range: all events occurred at 2024-03-01T10:00:10\n",
+		"#  10ms  ################################################################\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+}
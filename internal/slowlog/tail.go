@@ -0,0 +1,275 @@
+package slowlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FollowFile streams the entries appended to a slow log file until ctx is done, checking for
+// new data every poll. It starts at the end of the file unless fromStart is set, and starts
+// over when the file shrinks (FLUSH SLOW LOGS after rotation, or truncation).
+func FollowFile(ctx context.Context, path string, fromStart bool, poll time.Duration, fn func(Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+	var offset int64
+	if !fromStart {
+		if offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+
+	s := &splitter{fn: fn}
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			offset += int64(n)
+			if err := s.push(buf[:n]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if err := s.idle(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(poll):
+		}
+		if info, err := os.Stat(path); err == nil && info.Size() < offset {
+			f.Close()
+			if f, err = os.Open(path); err != nil {
+				return err
+			}
+			offset = 0
+			s.pending = nil
+		}
+	}
+}
+
+// FollowReader streams the entries of a log written to r as it grows, e.g. the output of
+// "tail -F" on a server's slow log piped to stdin, until r ends or ctx is done.
+func FollowReader(ctx context.Context, r io.Reader, poll time.Duration, fn func(Entry) error) error {
+	chunks := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			buf := make([]byte, 64*1024)
+			n, err := r.Read(buf)
+			if n > 0 {
+				select {
+				case chunks <- buf[:n]:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	s := &splitter{fn: fn}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case chunk := <-chunks:
+			if err := s.push(chunk); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := s.idle(); err != nil {
+				return err
+			}
+		case err := <-readErr:
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			return s.flush()
+		}
+	}
+}
+
+// splitter cuts a growing log into runs of complete entries for Parse. An entry is complete
+// once the header of the next one arrives, or when the log goes quiet after a statement.
+type splitter struct {
+	fn      func(Entry) error
+	pending []byte
+	// db carries the last "use" across chunks, since the server only logs it when it changes.
+	db string
+	// quiet counts idle polls, so a statement still being written is not cut off.
+	quiet int
+}
+
+func (s *splitter) push(b []byte) error {
+	s.pending = append(s.pending, b...)
+	s.quiet = 0
+	cut := lastEntryStart(s.pending)
+	if cut <= 0 {
+		return nil
+	}
+	complete := s.pending[:cut]
+	s.pending = append([]byte(nil), s.pending[cut:]...)
+	return s.parse(complete)
+}
+
+func (s *splitter) idle() error {
+	s.quiet++
+	if s.quiet < 2 || !bytes.HasSuffix(bytes.TrimRight(s.pending, "\n"), []byte(";")) {
+		return nil
+	}
+	return s.flush()
+}
+
+func (s *splitter) flush() error {
+	complete := s.pending
+	s.pending = nil
+	return s.parse(complete)
+}
+
+func (s *splitter) parse(b []byte) error {
+	return Parse(bytes.NewReader(b), func(e Entry) error {
+		if e.DB == "" {
+			e.DB = s.db
+		}
+		s.db = e.DB
+		return s.fn(e)
+	})
+}
+
+// lastEntryStart returns the offset of the header lines ("# Time:", "# User@Host:") that open
+// the last entry in b, or -1 when there is none.
+func lastEntryStart(b []byte) int {
+	for end := len(b); end > 0; {
+		start := bytes.LastIndexByte(b[:end-1], '\n') + 1
+		line := b[start:end]
+		if bytes.HasPrefix(line, []byte("# Time:")) {
+			return start
+		}
+		if bytes.HasPrefix(line, []byte("# User@Host:")) {
+			if start > 0 {
+				prev := bytes.LastIndexByte(b[:start-1], '\n') + 1
+				if bytes.HasPrefix(b[prev:start], []byte("# Time:")) {
+					return prev
+				}
+			}
+			return start
+		}
+		end = start
+	}
+	return -1
+}
+
+// FollowTable streams the entries the server writes to mysql.slow_log (log_output must include
+// TABLE) until ctx is done, polling every poll. It starts with entries logged from now on,
+// or with the whole table when fromStart is set.
+func FollowTable(ctx context.Context, db *gorm.DB, fromStart bool, poll time.Duration, fn func(Entry) error) error {
+	var output string
+	if err := db.WithContext(ctx).Raw("SELECT @@GLOBAL.log_output").Scan(&output).Error; err != nil {
+		return err
+	}
+	if !strings.Contains(strings.ToUpper(output), "TABLE") {
+		return fmt.Errorf("log_output is %s, so mysql.slow_log stays empty; run SET GLOBAL log_output = 'FILE,TABLE' first", output)
+	}
+	cursor := "1970-01-01 00:00:00.000000"
+	if !fromStart {
+		var err error
+		if cursor, err = serverNow(ctx, db); err != nil {
+			return err
+		}
+	}
+
+	for {
+		entries, next, err := readTable(ctx, db, cursor, tableBatch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, e := range entries {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		cursor = next
+		if len(entries) == tableBatch {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(poll):
+		}
+	}
+}
+
+const tableBatch = 1000
+
+// readTable returns up to limit entries of mysql.slow_log that started after cursor, oldest
+// first, and the cursor to continue from. The cursor is start_time as text, which sorts
+// chronologically and needs no time zone conversion.
+func readTable(ctx context.Context, db *gorm.DB, cursor string, limit int) ([]Entry, string, error) {
+	var rows []struct {
+		StartTime    string
+		UserHost     string
+		ThreadID     int64
+		DB           string
+		QuerySeconds float64
+		LockSeconds  float64
+		RowsSent     int64
+		RowsExamined int64
+		SQLText      string
+	}
+	err := db.WithContext(ctx).Raw(`SELECT CAST(start_time AS CHAR) AS start_time, user_host, thread_id, db,
+		HOUR(query_time) * 3600 + MINUTE(query_time) * 60 + SECOND(query_time) + MICROSECOND(query_time) / 1000000 AS query_seconds,
+		HOUR(lock_time) * 3600 + MINUTE(lock_time) * 60 + SECOND(lock_time) + MICROSECOND(lock_time) / 1000000 AS lock_seconds,
+		rows_sent, rows_examined, CONVERT(sql_text USING utf8mb4) AS sql_text
+		FROM mysql.slow_log WHERE start_time > ? ORDER BY start_time LIMIT ?`, cursor, limit).Scan(&rows).Error
+	if err != nil {
+		return nil, cursor, err
+	}
+	entries := make([]Entry, 0, len(rows))
+	for _, r := range rows {
+		e := Entry{
+			ThreadID:     r.ThreadID,
+			DB:           r.DB,
+			QueryTime:    time.Duration(r.QuerySeconds * float64(time.Second)),
+			LockTime:     time.Duration(r.LockSeconds * float64(time.Second)),
+			RowsSent:     r.RowsSent,
+			RowsExamined: r.RowsExamined,
+			SQL:          r.SQLText,
+		}
+		e.Time, _ = time.ParseInLocation("2006-01-02 15:04:05.999999", r.StartTime, time.Local)
+		parseUserHost(&e, r.UserHost)
+		entries = append(entries, e)
+		cursor = r.StartTime
+	}
+	return entries, cursor, nil
+}
+
+// serverNow returns the server clock as a readTable cursor.
+func serverNow(ctx context.Context, db *gorm.DB) (string, error) {
+	var now string
+	err := db.WithContext(ctx).Raw("SELECT CAST(NOW(6) AS CHAR)").Scan(&now).Error
+	return now, err
+}
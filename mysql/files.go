@@ -0,0 +1,10 @@
+// Package mysql embeds the lab server's configuration (conf.d) and init scripts, so a
+// container slowlab provisions itself is tuned exactly like the docker compose service.
+package mysql
+
+import "embed"
+
+// Files holds conf.d/*.cnf and init/*.sql.
+//
+//go:embed conf.d/*.cnf init/*.sql
+var Files embed.FS
@@ -0,0 +1,14 @@
+-- Extra privileges the lab needs beyond the default database grant.
+GRANT SELECT ON performance_schema.* TO 'slowuser'@'%';
+GRANT PROCESS ON *.* TO 'slowuser'@'%';
+-- SET GLOBAL / SET PERSIST_ONLY for the server-level experiments.
+GRANT SYSTEM_VARIABLES_ADMIN, PERSIST_RO_VARIABLES_ADMIN ON *.* TO 'slowuser'@'%';
+-- Per-index page counts for the index size notes.
+GRANT SELECT ON mysql.innodb_index_stats TO 'slowuser'@'%';
+-- sys.schema_unused_indexes for the index usage report.
+GRANT SELECT ON sys.* TO 'slowuser'@'%';
+-- mysql.slow_log for tail-slowlog -table.
+GRANT SELECT ON mysql.slow_log TO 'slowuser'@'%';
+-- SHOW REPLICA STATUS for the health checks.
+GRANT REPLICATION CLIENT ON *.* TO 'slowuser'@'%';
+FLUSH PRIVILEGES;
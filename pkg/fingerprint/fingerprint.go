@@ -0,0 +1,215 @@
+// Package fingerprint normalizes SQL statements into query classes, the way slowlab digests
+// slow logs, so other Go services can group their own query streams:
+//
+//	fp := fingerprint.Normalize("SELECT * FROM orders WHERE id IN (1, 2, 3) -- hot path")
+//	// fp == "select * from orders where id in(?+)"
+//	id := fingerprint.ID(fp) // "0x"+id matches the query ID of pt-query-digest style reports
+//
+// The normalized text and IDs are stable: a statement keeps its fingerprint across slowlab
+// releases, so stored IDs remain comparable.
+package fingerprint
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// Normalize rewrites a statement so every execution of the same query shape maps to the
+// same text: comments go, string and number literals become ?, IN lists and multi-row VALUES
+// collapse to a single element, whitespace collapses and everything but literals is lower-cased.
+func Normalize(sql string) string {
+	var toks []string
+	rs := []rune(strings.TrimSpace(sql))
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-', r == '#':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			j := i + 2
+			for j+1 < len(rs) && (rs[j] != '*' || rs[j+1] != '/') {
+				j++
+			}
+			i = j + 2
+		case r == '\'' || r == '"':
+			i = skipQuoted(rs, i)
+			toks = append(toks, "?")
+		case r == '`':
+			j := i + 1
+			for j < len(rs) && rs[j] != '`' {
+				j++
+			}
+			toks = append(toks, strings.ToLower(string(rs[i+1:min(j, len(rs))])))
+			i = j + 1
+		case r == '0' && i+1 < len(rs) && (rs[i+1] == 'x' || rs[i+1] == 'X'):
+			j := i + 2
+			for j < len(rs) && strings.ContainsRune("0123456789abcdefABCDEF", rs[j]) {
+				j++
+			}
+			toks = append(toks, "?")
+			i = j
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.' || rs[j] == 'e' || rs[j] == 'E' ||
+				((rs[j] == '-' || rs[j] == '+') && (rs[j-1] == 'e' || rs[j-1] == 'E'))) {
+				j++
+			}
+			toks = append(toks, "?")
+			i = j
+		case isWordRune(r):
+			j := i
+			for j < len(rs) && isWordRune(rs[j]) {
+				j++
+			}
+			word := strings.ToLower(string(rs[i:j]))
+			if word == "null" || word == "true" || word == "false" {
+				word = "?"
+			}
+			toks = append(toks, word)
+			i = j
+		case strings.ContainsRune("<>=!|&", r):
+			j := i + 1
+			for j < len(rs) && strings.ContainsRune("<>=!|&", rs[j]) {
+				j++
+			}
+			toks = append(toks, string(rs[i:j]))
+			i = j
+		default:
+			toks = append(toks, string(r))
+			i++
+		}
+	}
+	for len(toks) > 0 && toks[len(toks)-1] == ";" {
+		toks = toks[:len(toks)-1]
+	}
+	return collapseLists(join(toks))
+}
+
+// join separates tokens with single spaces, except around parentheses, commas and dots, so
+// differently spaced statements come out identical: "in(?, ?)", "count(*)", "o.id".
+func join(toks []string) string {
+	var b strings.Builder
+	for i, t := range toks {
+		if i > 0 {
+			prev := toks[i-1]
+			glued := prev == "(" || prev == "." || t == ")" || t == "," || t == "." ||
+				(t == "(" && isWordRune([]rune(prev)[len([]rune(prev))-1]))
+			if !glued {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(t)
+	}
+	return b.String()
+}
+
+// ID is a short, stable identifier of a fingerprint: the last 16 hex digits of its MD5, the
+// same "query ID" pt-query-digest prints, so classes can be matched across tools and reports.
+func ID(fingerprint string) string {
+	sum := md5.Sum([]byte(fingerprint))
+	return strings.ToUpper(hex.EncodeToString(sum[8:]))
+}
+
+func skipQuoted(rs []rune, i int) int {
+	quote := rs[i]
+	j := i + 1
+	for j < len(rs) {
+		switch {
+		case rs[j] == '\\':
+			j += 2
+			continue
+		case rs[j] == quote && j+1 < len(rs) && rs[j+1] == quote:
+			j += 2
+			continue
+		case rs[j] == quote:
+			return j + 1
+		}
+		j++
+	}
+	return j
+}
+
+// collapseLists turns "in(?, ?, ?)" into "in(?+)" and "values(?, ?), (?, ?)" into "values(?+)",
+// so statements differing only in list length share a fingerprint.
+func collapseLists(s string) string {
+	var b strings.Builder
+	for {
+		i := indexList(s)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		open := strings.IndexByte(s, '(')
+		b.WriteString(s[:open])
+		rest := s[open:]
+		// Consume "(?, ?)" groups separated by commas, as long as they contain only placeholders.
+		j, consumed := 0, 0
+		for {
+			end := strings.IndexByte(rest[j:], ')')
+			if end < 0 || !placeholdersOnly(rest[j+1:j+end]) {
+				break
+			}
+			j += end + 1
+			consumed = j
+			if !strings.HasPrefix(rest[j:], ", (") {
+				break
+			}
+			j += 2
+		}
+		j = consumed
+		if j == 0 {
+			b.WriteString("(")
+			s = rest[1:]
+			continue
+		}
+		b.WriteString("(?+)")
+		s = rest[j:]
+	}
+}
+
+// indexList finds the next "in(" or "values(" keyword followed by a parenthesis.
+func indexList(s string) int {
+	best := -1
+	for _, kw := range []string{"in(", "values(", "value("} {
+		from := 0
+		for {
+			i := strings.Index(s[from:], kw)
+			if i < 0 {
+				break
+			}
+			i += from
+			if i == 0 || !isWordRune(rune(s[i-1])) {
+				if best < 0 || i < best {
+					best = i
+				}
+				break
+			}
+			from = i + 1
+		}
+	}
+	return best
+}
+
+func placeholdersOnly(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) != "?" {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
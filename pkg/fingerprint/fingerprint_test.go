@@ -0,0 +1,49 @@
+package fingerprint
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name, sql, want string
+	}{
+		{"package example", "SELECT * FROM orders WHERE id IN (1, 2, 3) -- hot path", "select * from orders where id in(?+)"},
+		{"whitespace and case", "  select  *\n\tFROM Orders   where ID = 7 ; ", "select * from orders where id = ?"},
+		{"strings", `SELECT * FROM t WHERE a = 'it''s' AND b = "x\"y"`, "select * from t where a = ? and b = ?"},
+		{"numbers", "SELECT * FROM t WHERE a > -1.5e+3 AND b = 0xFF AND c = .5", "select * from t where a > - ? and b = ? and c = ?"},
+		{"null and booleans", "UPDATE t SET a = NULL, b = TRUE WHERE c IS false", "update t set a = ?, b = ? where c is ?"},
+		{"comments", "SELECT /* hint */ a # trailing\nFROM t", "select a from t"},
+		{"backquoted names", "SELECT `Order`.`ID` FROM `Order`", "select order.id from order"},
+		{"operators", "SELECT * FROM t WHERE a<>1 AND b>=2 OR c != 3", "select * from t where a <> ? and b >= ? or c != ?"},
+		{"function calls", "SELECT COUNT( * ) , o.id FROM orders o", "select count(*), o.id from orders o"},
+		{"multi-row values", "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'z')", "insert into t(a, b) values(?+)"},
+		{"single-row value", "INSERT INTO t VALUE (1)", "insert into t value(?+)"},
+		{"IN subquery stays", "SELECT * FROM t WHERE id IN (SELECT id FROM u WHERE x IN (1,2))", "select * from t where id in(select id from u where x in(?+))"},
+		{"identifier ending in in", "SELECT * FROM t WHERE join_in(1, 2)", "select * from t where join_in(?, ?)"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.sql); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeGroupsListLengths(t *testing.T) {
+	a := Normalize("SELECT * FROM orders WHERE id IN (1)")
+	b := Normalize("select * from orders where id in (4, 5, 6, 7)")
+	if a != b {
+		t.Errorf("IN lists of different length differ: %q vs %q", a, b)
+	}
+}
+
+func TestID(t *testing.T) {
+	// The last 16 hex digits of md5("select ?"), as pt-query-digest reports the query ID.
+	if got, want := ID("select ?"), "16219655761820A2"; got != want {
+		t.Errorf("ID = %q, want %q", got, want)
+	}
+	if ID("select ?") == ID("select ?, ?") {
+		t.Error("different fingerprints share an ID")
+	}
+}
@@ -0,0 +1,108 @@
+package slowlab
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"mysql-slow-query-lab/internal/buildinfo"
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/db"
+	"mysql-slow-query-lab/internal/report"
+
+	"gorm.io/gorm"
+)
+
+type (
+	// DBConfig holds the MySQL connection settings.
+	DBConfig = db.Config
+	// Pool holds the connection pool limits of DBConfig.Pool.
+	Pool = db.Pool
+	// SeedConfig controls the size and shape of the seeded orders.
+	SeedConfig = data.SeedConfig
+	// DatasetOptions fix the random seed, time anchor and realism of the generated data.
+	DatasetOptions = data.DatasetOptions
+	// RunConfig selects the scenario groups and instrumentation of a run.
+	RunConfig = data.RunConfig
+	// ServerVersion is a parsed MySQL server version.
+	ServerVersion = data.ServerVersion
+	// PlanRow is one row of a traditional EXPLAIN.
+	PlanRow = data.PlanRow
+	// Cache backs the cache-aside scenarios.
+	Cache = data.Cache
+)
+
+// Seeding options, see SeedConfig.
+const (
+	SeedMethodInsert    = data.SeedMethodInsert
+	SeedMethodLoadData  = data.SeedMethodLoadData
+	DistributionUniform = data.DistributionUniform
+	DistributionZipf    = data.DistributionZipf
+	TimeUniform         = data.TimeUniform
+	TimeBusinessHours   = data.TimeBusinessHours
+	TimeRecent          = data.TimeRecent
+)
+
+// ConfigFromEnv reads the connection settings from the MYSQL_* environment variables, with
+// the docker compose defaults.
+func ConfigFromEnv() DBConfig {
+	return db.FromEnv()
+}
+
+// Open connects to MySQL with cfg.Pool, or the pool settings slowlab uses by default.
+func Open(cfg DBConfig) (*gorm.DB, error) {
+	return db.Open(cfg)
+}
+
+// EnsureSchema creates or migrates the orders, customers and seed_state tables.
+func EnsureSchema(gdb *gorm.DB) error {
+	return data.EnsureSchema(gdb)
+}
+
+// WithDataset returns a context under which SeedDataset and scenario setup hooks generate
+// data according to opts.
+func WithDataset(ctx context.Context, opts DatasetOptions) context.Context {
+	return data.WithDataset(ctx, opts)
+}
+
+// WithProgress returns a context whose long-running steps report progress through logf.
+func WithProgress(ctx context.Context, logf func(format string, args ...interface{})) context.Context {
+	return data.WithProgress(ctx, logf)
+}
+
+// SeedDataset tops the dataset up to cfg.Orders orders; rows already written are kept.
+func SeedDataset(ctx context.Context, gdb *gorm.DB, cfg SeedConfig) error {
+	return data.SeedDataset(ctx, gdb, cfg)
+}
+
+// RunScenarios runs the built-in scenarios, those added with Register, then cfg.Extra, and
+// returns one result each. Scenarios of registered packs only run when added to cfg.Extra.
+func RunScenarios(ctx context.Context, gdb *gorm.DB, cfg RunConfig) []ScenarioResult {
+	return data.RunScenarios(ctx, gdb, cfg)
+}
+
+// DetectServerVersion reads and parses the server version.
+func DetectServerVersion(ctx context.Context, gdb *gorm.DB) (ServerVersion, error) {
+	return data.DetectServerVersion(ctx, gdb)
+}
+
+// ExplainPlan runs a traditional EXPLAIN of query.
+func ExplainPlan(ctx context.Context, gdb *gorm.DB, query string, args ...interface{}) ([]PlanRow, error) {
+	return data.ExplainPlan(ctx, gdb, query, args...)
+}
+
+// ErrorKind classifies a scenario error: "" on success, otherwise "skipped", "setup",
+// "execution", "explain" or "expectation".
+func ErrorKind(err error) string {
+	return data.ErrorKind(err)
+}
+
+// WriteScenarioTable renders results as the summary table the slowlab command prints.
+func WriteScenarioTable(w io.Writer, results []ScenarioResult) error {
+	format, err := report.NewFormatter("raw")
+	if err != nil {
+		return err
+	}
+	meta := report.Metadata{StartedAt: time.Now(), Build: buildinfo.Read()}
+	return report.ScenarioTable(w, meta, format, results)
+}
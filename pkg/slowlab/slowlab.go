@@ -0,0 +1,85 @@
+// Package slowlab lets other Go modules compile private scenario packs into a slowlab binary.
+//
+// A pack registers itself from init and a custom main wires it in:
+//
+//	package billing
+//
+//	func init() {
+//		slowlab.RegisterPack(slowlab.Pack{
+//			Name:       "billing",
+//			Version:    "1.2.0",
+//			APIVersion: slowlab.APIVersion,
+//			Scenarios:  []slowlab.Scenario{{Name: "...", Query: "...", Setup: seedInvoices}},
+//		})
+//	}
+//
+//	package main
+//
+//	import (
+//		_ "corp.example/slowlab-packs/billing"
+//		"mysql-slow-query-lab/pkg/slowlab"
+//	)
+//
+//	func main() { slowlab.Main() }
+//
+// Programs that drive a lab themselves use the library API instead of Main: connect, seed the
+// dataset and run the scenarios, built-in and their own:
+//
+//	gdb, err := slowlab.Open(slowlab.ConfigFromEnv())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := slowlab.EnsureSchema(gdb); err != nil {
+//		log.Fatal(err)
+//	}
+//	ctx := slowlab.WithDataset(context.Background(), slowlab.DatasetOptions{Seed: 7})
+//	if err := slowlab.SeedDataset(ctx, gdb, slowlab.SeedConfig{Orders: 2000000}); err != nil {
+//		log.Fatal(err)
+//	}
+//	results := slowlab.RunScenarios(ctx, gdb, slowlab.RunConfig{Extra: myScenarios})
+//	slowlab.WriteScenarioTable(os.Stdout, results)
+package slowlab
+
+import (
+	"mysql-slow-query-lab/internal/cli"
+	"mysql-slow-query-lab/internal/data"
+	"mysql-slow-query-lab/internal/pack"
+)
+
+// APIVersion is the semantic version of this registration API.
+const APIVersion = pack.APIVersion
+
+type (
+	// Pack is a scenario pack compiled into the binary.
+	Pack = pack.GoPack
+	// Scenario is a single scenario definition, including optional Go Setup and Run hooks.
+	Scenario = data.Scenario
+	// ScenarioResult is what a Run hook fills in.
+	ScenarioResult = data.ScenarioResult
+	// PlanExpectation describes the EXPLAIN shape a scenario expects.
+	PlanExpectation = data.PlanExpectation
+	// MariaDBSupport tags how a scenario behaves on MariaDB.
+	MariaDBSupport = data.MariaDBSupport
+	// TiDBSupport tags how a scenario behaves on TiDB.
+	TiDBSupport = data.TiDBSupport
+	// PostgresSupport adapts a scenario for the PostgreSQL comparison.
+	PostgresSupport = data.PostgresSupport
+)
+
+// Register adds a single scenario that runs after the built-in ones, without a pack; call it
+// from init. Type defaults to "自定义场景". It panics when the scenario has no name, no Query
+// and no Run, or when the name is already registered.
+func Register(sc Scenario) {
+	data.Register(sc)
+}
+
+// RegisterPack adds a pack to the binary; call it from init. It panics when the pack is
+// invalid or targets an incompatible APIVersion.
+func RegisterPack(p Pack) {
+	pack.Register(p)
+}
+
+// Main runs the slowlab command line with all registered packs.
+func Main() {
+	cli.Main()
+}
@@ -0,0 +1,83 @@
+package slowlabtest
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// AssertValid fails the test for every problem ValidateScenarios would report without a
+// server: missing Query and Run, an unparsable MinVersion, or placeholders not matching Args.
+func AssertValid(t testing.TB, sc data.Scenario) {
+	t.Helper()
+	for _, err := range data.CheckScenario(sc) {
+		t.Errorf("%s: %v", sc.Name, err)
+	}
+}
+
+// RunSetup runs the scenario's Setup hook and SetupSQL like a real run does, failing the test on error.
+func RunSetup(t testing.TB, db *gorm.DB, sc data.Scenario) {
+	t.Helper()
+	warnings, err := data.RunSetup(context.Background(), db, sc)
+	if err != nil {
+		t.Fatalf("%s: %v", sc.Name, err)
+	}
+	for _, w := range warnings {
+		t.Logf("%s: %s", sc.Name, w)
+	}
+}
+
+// AssertPlan fails the test for every way plan misses the scenario's ExpectPlan.
+func AssertPlan(t testing.TB, sc data.Scenario, plan []data.PlanRow) {
+	t.Helper()
+	if len(sc.ExpectPlan) == 0 {
+		t.Errorf("%s: scenario has no ExpectPlan", sc.Name)
+	}
+	for _, expect := range sc.ExpectPlan {
+		for _, mismatch := range expect.Check(plan) {
+			t.Errorf("%s: %s", sc.Name, mismatch)
+		}
+	}
+}
+
+// AssertExecuted fails the test unless some recorded statement matches pattern.
+func AssertExecuted(t testing.TB, fake *Fake, pattern string) {
+	t.Helper()
+	if matching(fake, pattern) == 0 {
+		t.Errorf("no statement matches %q; executed:\n%s", pattern, executed(fake))
+	}
+}
+
+// AssertNotExecuted fails the test if any recorded statement matches pattern.
+func AssertNotExecuted(t testing.TB, fake *Fake, pattern string) {
+	t.Helper()
+	if n := matching(fake, pattern); n > 0 {
+		t.Errorf("%d statement(s) match %q; executed:\n%s", n, pattern, executed(fake))
+	}
+}
+
+func matching(fake *Fake, pattern string) int {
+	re := regexp.MustCompile(pattern)
+	n := 0
+	for _, stmt := range fake.Statements() {
+		if re.MatchString(stmt.SQL) {
+			n++
+		}
+	}
+	return n
+}
+
+func executed(fake *Fake) string {
+	var b strings.Builder
+	for _, stmt := range fake.Statements() {
+		b.WriteString("  ")
+		b.WriteString(stmt.SQL)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package slowlabtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// recorder is a testing.TB that records failures instead of failing the real test.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper()             {}
+func (r *recorder) Logf(string, ...any) {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+	runtime.Goexit()
+}
+
+// record runs fn against a recorder in its own goroutine, so Fatalf can stop it.
+func record(fn func(tb testing.TB)) *recorder {
+	r := &recorder{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(r)
+	}()
+	<-done
+	return r
+}
+
+var indexedScenario = data.Scenario{
+	Name:       "customer lookup",
+	Query:      "SELECT * FROM orders WHERE customer_id = ?",
+	Args:       []interface{}{100},
+	ExpectPlan: []data.PlanExpectation{{Table: "orders", Type: "ref", Key: "idx_orders_customer_id"}},
+}
+
+func TestAssertValid(t *testing.T) {
+	if r := record(func(tb testing.TB) { AssertValid(tb, indexedScenario) }); len(r.errors) != 0 {
+		t.Errorf("valid scenario reported %q", r.errors)
+	}
+	broken := data.Scenario{Name: "broken", Query: "SELECT * FROM orders WHERE id = ? AND note = ?", Args: []interface{}{1}, MinVersion: "eight"}
+	if r := record(func(tb testing.TB) { AssertValid(tb, broken) }); len(r.errors) != 2 {
+		t.Errorf("broken scenario reported %q, want a placeholder and a MinVersion error", r.errors)
+	}
+}
+
+func TestAssertPlan(t *testing.T) {
+	good := []data.PlanRow{{ID: 1, SelectType: "SIMPLE", Table: "orders", Type: "ref", Key: "idx_orders_customer_id", Rows: 10, Filtered: 100}}
+	if r := record(func(tb testing.TB) { AssertPlan(tb, indexedScenario, good) }); len(r.errors) != 0 {
+		t.Errorf("matching plan reported %q", r.errors)
+	}
+	scan := []data.PlanRow{{ID: 1, SelectType: "SIMPLE", Table: "orders", Type: "ALL", Rows: 1000000, Filtered: 10}}
+	if r := record(func(tb testing.TB) { AssertPlan(tb, indexedScenario, scan) }); len(r.errors) != 2 {
+		t.Errorf("full scan reported %q, want type and key mismatches", r.errors)
+	}
+	noExpectation := data.Scenario{Name: "no expectation", Query: "SELECT 1"}
+	if r := record(func(tb testing.TB) { AssertPlan(tb, noExpectation, good) }); len(r.errors) != 1 {
+		t.Errorf("scenario without ExpectPlan reported %q, want one error", r.errors)
+	}
+}
+
+func TestAssertExecuted(t *testing.T) {
+	db, fake := NewDB(t)
+	db.Exec("INSERT INTO invoices (id) VALUES (1)")
+
+	if r := record(func(tb testing.TB) { AssertExecuted(tb, fake, `^INSERT INTO invoices`) }); len(r.errors) != 0 {
+		t.Errorf("executed statement reported %q", r.errors)
+	}
+	if r := record(func(tb testing.TB) { AssertExecuted(tb, fake, `^DELETE`) }); len(r.errors) != 1 {
+		t.Errorf("missing statement reported %q, want one error", r.errors)
+	}
+	if r := record(func(tb testing.TB) { AssertNotExecuted(tb, fake, `^DELETE`) }); len(r.errors) != 0 {
+		t.Errorf("absent statement reported %q", r.errors)
+	}
+	if r := record(func(tb testing.TB) { AssertNotExecuted(tb, fake, `invoices`) }); len(r.errors) != 1 {
+		t.Errorf("executed statement not reported by AssertNotExecuted: %q", r.errors)
+	}
+}
+
+func TestRunSetup(t *testing.T) {
+	db, fake := NewDB(t)
+	sc := data.Scenario{
+		Name:  "with setup",
+		Query: "SELECT * FROM invoices",
+		Setup: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).Exec("CREATE TABLE invoices (id INT)").Error
+		},
+		SetupSQL: []string{"INSERT INTO invoices VALUES (1)"},
+	}
+	if r := record(func(tb testing.TB) { RunSetup(tb, db, sc) }); len(r.errors) != 0 {
+		t.Fatalf("setup reported %q", r.errors)
+	}
+	AssertExecuted(t, fake, `^CREATE TABLE invoices`)
+	AssertExecuted(t, fake, `^INSERT INTO invoices`)
+
+	fake.On(`^CREATE TABLE`).Fails(errors.New("table exists"))
+	r := record(func(tb testing.TB) { RunSetup(tb, db, sc) })
+	if !r.fatal || len(r.errors) != 1 {
+		t.Errorf("failing setup: fatal=%v errors=%q, want one fatal error", r.fatal, r.errors)
+	}
+}
@@ -0,0 +1,233 @@
+// Package slowlabtest helps scenario authors unit-test their scenarios without a live MySQL:
+// a scripted fake behind *gorm.DB that records every statement, golden EXPLAIN fixtures, and
+// assertions over both.
+//
+//	func TestInvoiceSetup(t *testing.T) {
+//		db, fake := slowlabtest.NewDB(t)
+//		fake.On(`SELECT COUNT\(\*\) FROM invoices`).Returns([]string{"n"}, []interface{}{0})
+//		slowlabtest.AssertValid(t, invoiceScenario)
+//		slowlabtest.RunSetup(t, db, invoiceScenario)
+//		slowlabtest.AssertExecuted(t, fake, `^INSERT INTO invoices`)
+//
+//		plan := slowlabtest.LoadPlan(t, "testdata/invoice_scan.plan.json")
+//		slowlabtest.AssertPlan(t, invoiceScenario, plan)
+//	}
+package slowlabtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement is one statement the code under test sent to the fake. Transactions show up as
+// "BEGIN", "COMMIT" and "ROLLBACK".
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Fake is a scripted stand-in for MySQL. Statements are answered by the most recently added
+// rule whose pattern matches; anything unmatched succeeds with no rows and 0 rows affected.
+type Fake struct {
+	mu         sync.Mutex
+	rules      []*Rule
+	statements []Statement
+}
+
+// Rule scripts the answer to statements matching a regular expression. SQL is matched with
+// runs of whitespace collapsed to single spaces.
+type Rule struct {
+	re       *regexp.Regexp
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// NewDB returns a *gorm.DB backed by a new Fake, closed when the test ends.
+func NewDB(t testing.TB) (*gorm.DB, *Fake) {
+	t.Helper()
+	fake := &Fake{}
+	sqlDB := sql.OpenDB(connector{fake})
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger:                 logger.Discard,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("slowlabtest: open fake db: %v", err)
+	}
+	return db, fake
+}
+
+// On adds a rule for statements matching pattern; it panics on an invalid pattern.
+func (f *Fake) On(pattern string) *Rule {
+	r := &Rule{re: regexp.MustCompile(pattern)}
+	f.mu.Lock()
+	f.rules = append(f.rules, r)
+	f.mu.Unlock()
+	return r
+}
+
+// Returns makes matching queries return rows with the given columns.
+func (r *Rule) Returns(columns []string, rows ...[]interface{}) *Rule {
+	r.columns = columns
+	r.rows = r.rows[:0]
+	for _, row := range rows {
+		values := make([]driver.Value, len(row))
+		for i, v := range row {
+			value, err := driver.DefaultParameterConverter.ConvertValue(v)
+			if err != nil {
+				panic(fmt.Sprintf("slowlabtest: row value %v: %v", v, err))
+			}
+			values[i] = value
+		}
+		r.rows = append(r.rows, values)
+	}
+	return r
+}
+
+// Affects makes matching statements report n rows affected.
+func (r *Rule) Affects(n int64) *Rule {
+	r.affected = n
+	return r
+}
+
+// Fails makes matching statements fail with err.
+func (r *Rule) Fails(err error) *Rule {
+	r.err = err
+	return r
+}
+
+// Statements returns everything executed so far, in order.
+func (f *Fake) Statements() []Statement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Statement(nil), f.statements...)
+}
+
+// Reset forgets recorded statements; rules are kept.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	f.statements = nil
+	f.mu.Unlock()
+}
+
+func (f *Fake) handle(query string, args []driver.NamedValue) *Rule {
+	normalized := strings.Join(strings.Fields(query), " ")
+	stmt := Statement{SQL: normalized}
+	for _, arg := range args {
+		stmt.Args = append(stmt.Args, arg.Value)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = append(f.statements, stmt)
+	for i := len(f.rules) - 1; i >= 0; i-- {
+		if f.rules[i].re.MatchString(normalized) {
+			return f.rules[i]
+		}
+	}
+	return &Rule{}
+}
+
+type connector struct{ fake *Fake }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{fake: c.fake}, nil }
+func (c connector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("slowlabtest: use NewDB")
+}
+
+type conn struct{ fake *Fake }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{conn: c, query: query}, nil }
+func (c *conn) Close() error                              { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	if r := c.fake.handle("BEGIN", nil); r.err != nil {
+		return nil, r.err
+	}
+	return tx{c.fake}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r := c.fake.handle(query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return driver.RowsAffected(r.affected), nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r := c.fake.handle(query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &rows{columns: r.columns, values: r.rows}, nil
+}
+
+type tx struct{ fake *Fake }
+
+func (t tx) Commit() error   { return t.fake.handle("COMMIT", nil).err }
+func (t tx) Rollback() error { return t.fake.handle("ROLLBACK", nil).err }
+
+// stmt backs server-side prepares; preparing never fails, executing goes through the rules.
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	out := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		out[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return out
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,86 @@
+package slowlabtest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFakeRecordsStatements(t *testing.T) {
+	db, fake := NewDB(t)
+	if err := db.Exec("UPDATE orders\n   SET note = ?  WHERE id = ?", "x", 7).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := fake.Statements()
+	want := []Statement{{SQL: "UPDATE orders SET note = ? WHERE id = ?", Args: []interface{}{"x", int64(7)}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Statements() = %#v, want %#v", got, want)
+	}
+	fake.Reset()
+	if got := fake.Statements(); len(got) != 0 {
+		t.Errorf("after Reset, Statements() = %v", got)
+	}
+}
+
+func TestFakeReturnsRows(t *testing.T) {
+	db, fake := NewDB(t)
+	fake.On(`^SELECT id, note FROM orders`).Returns([]string{"id", "note"}, []interface{}{1, "a"}, []interface{}{2, "b"})
+
+	var got []struct {
+		ID   int64
+		Note string
+	}
+	if err := db.Raw("SELECT id, note FROM orders").Scan(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[1].Note != "b" {
+		t.Errorf("rows = %+v", got)
+	}
+
+	// Unmatched statements succeed with no rows.
+	var n int64 = -1
+	if err := db.Raw("SELECT COUNT(*) FROM customers").Scan(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	if n != -1 {
+		t.Errorf("unmatched query scanned %d, want no rows", n)
+	}
+}
+
+func TestFakeLatestRuleWins(t *testing.T) {
+	db, fake := NewDB(t)
+	fake.On(`^DELETE`).Affects(1)
+	fake.On(`^DELETE FROM orders`).Affects(5)
+
+	if n := db.Exec("DELETE FROM orders WHERE id < 10").RowsAffected; n != 5 {
+		t.Errorf("orders delete affected %d, want 5", n)
+	}
+	if n := db.Exec("DELETE FROM customers").RowsAffected; n != 1 {
+		t.Errorf("customers delete affected %d, want 1", n)
+	}
+}
+
+func TestFakeFails(t *testing.T) {
+	db, fake := NewDB(t)
+	boom := errors.New("boom")
+	fake.On(`^INSERT`).Fails(boom)
+	if err := db.Exec("INSERT INTO orders (id) VALUES (1)").Error; !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+}
+
+func TestFakeTransactions(t *testing.T) {
+	db, fake := NewDB(t)
+	tx := db.Begin()
+	tx.Exec("UPDATE orders SET note = 'x'")
+	tx.Rollback()
+
+	var sqls []string
+	for _, stmt := range fake.Statements() {
+		sqls = append(sqls, stmt.SQL)
+	}
+	want := []string{"BEGIN", "UPDATE orders SET note = 'x'", "ROLLBACK"}
+	if !reflect.DeepEqual(sqls, want) {
+		t.Errorf("statements = %q, want %q", sqls, want)
+	}
+}
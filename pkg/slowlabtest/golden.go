@@ -0,0 +1,90 @@
+package slowlabtest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+// planFixture is one EXPLAIN row as stored in a golden file, keyed by EXPLAIN's own column names.
+type planFixture struct {
+	ID           int64   `json:"id"`
+	SelectType   string  `json:"select_type"`
+	Table        string  `json:"table"`
+	Partitions   string  `json:"partitions,omitempty"`
+	Type         string  `json:"type"`
+	PossibleKeys string  `json:"possible_keys,omitempty"`
+	Key          string  `json:"key,omitempty"`
+	KeyLen       string  `json:"key_len,omitempty"`
+	Ref          string  `json:"ref,omitempty"`
+	Rows         int64   `json:"rows"`
+	Filtered     float64 `json:"filtered"`
+	Extra        string  `json:"Extra,omitempty"`
+}
+
+var planColumns = []string{"id", "select_type", "table", "partitions", "type", "possible_keys", "key", "key_len", "ref", "rows", "filtered", "Extra"}
+
+// LoadPlan reads a golden EXPLAIN fixture written by RecordPlan.
+func LoadPlan(t testing.TB, path string) []data.PlanRow {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("slowlabtest: %v", err)
+	}
+	var fixtures []planFixture
+	if err := json.Unmarshal(raw, &fixtures); err != nil {
+		t.Fatalf("slowlabtest: %s: %v", path, err)
+	}
+	plan := make([]data.PlanRow, len(fixtures))
+	for i, f := range fixtures {
+		plan[i] = data.PlanRow(f)
+	}
+	return plan
+}
+
+// RecordPlan runs the scenario's Setup and EXPLAINs its query against a live server, writing
+// the plan to path as a golden fixture. Call it from a test that only runs when a server is
+// configured, and commit the file so LoadPlan works everywhere else.
+func RecordPlan(ctx context.Context, db *gorm.DB, sc data.Scenario, path string) error {
+	if _, err := data.RunSetup(ctx, db, sc); err != nil {
+		return err
+	}
+	args := sc.Args
+	if sc.ArgsFunc != nil {
+		var err error
+		if args, err = sc.ArgsFunc(ctx, db); err != nil {
+			return err
+		}
+	}
+	plan, err := data.ExplainPlan(ctx, db, sc.SQL(), args...)
+	if err != nil {
+		return err
+	}
+	fixtures := make([]planFixture, len(plan))
+	for i, row := range plan {
+		fixtures[i] = planFixture(row)
+	}
+	raw, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+// ReturnsPlan makes matching queries (usually `^EXPLAIN `) answer with plan, e.g. one loaded by LoadPlan.
+func (r *Rule) ReturnsPlan(plan []data.PlanRow) *Rule {
+	rows := make([][]interface{}, len(plan))
+	for i, p := range plan {
+		rows[i] = []interface{}{p.ID, p.SelectType, p.Table, p.Partitions, p.Type, p.PossibleKeys, p.Key, p.KeyLen, p.Ref, p.Rows, p.Filtered, p.Extra}
+	}
+	return r.Returns(planColumns, rows...)
+}
@@ -0,0 +1,50 @@
+package slowlabtest
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"mysql-slow-query-lab/internal/data"
+)
+
+func TestLoadPlan(t *testing.T) {
+	plan := LoadPlan(t, "testdata/customer_lookup.plan.json")
+	want := []data.PlanRow{{
+		ID: 1, SelectType: "SIMPLE", Table: "orders", Type: "ref",
+		PossibleKeys: "idx_orders_customer_id", Key: "idx_orders_customer_id", KeyLen: "8", Ref: "const",
+		Rows: 1000, Filtered: 100,
+	}}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("LoadPlan = %+v, want %+v", plan, want)
+	}
+	AssertPlan(t, indexedScenario, plan)
+}
+
+func TestLoadPlanMissingFile(t *testing.T) {
+	r := record(func(tb testing.TB) { LoadPlan(tb, "testdata/missing.plan.json") })
+	if !r.fatal {
+		t.Error("LoadPlan of a missing file did not fail the test")
+	}
+}
+
+// TestRecordPlanRoundTrip records a plan served by the fake and reads it back.
+func TestRecordPlanRoundTrip(t *testing.T) {
+	plan := []data.PlanRow{
+		{ID: 1, SelectType: "PRIMARY", Table: "c", Type: "ALL", Rows: 2000, Filtered: 10, Extra: "Using where"},
+		{ID: 1, SelectType: "PRIMARY", Table: "orders", Partitions: "p2024", Type: "ref", PossibleKeys: "idx_orders_customer_id",
+			Key: "idx_orders_customer_id", KeyLen: "8", Ref: "slowlab.c.id", Rows: 12, Filtered: 33.33, Extra: "Using index"},
+	}
+	db, fake := NewDB(t)
+	fake.On(`^EXPLAIN `).ReturnsPlan(plan)
+
+	path := filepath.Join(t.TempDir(), "nested", "join.plan.json")
+	if err := RecordPlan(context.Background(), db, indexedScenario, path); err != nil {
+		t.Fatal(err)
+	}
+	AssertExecuted(t, fake, `^EXPLAIN SELECT \* FROM orders WHERE customer_id = \?$`)
+	if got := LoadPlan(t, path); !reflect.DeepEqual(got, plan) {
+		t.Errorf("round trip = %+v, want %+v", got, plan)
+	}
+}